@@ -2,15 +2,24 @@ package main
 
 import (
 	"chatops/internal/app"
+	"chatops/internal/bot/auth"
 	"chatops/internal/bot/handlers"
+	"chatops/internal/bot/notify"
 	"chatops/internal/db/migrations"
+	"chatops/internal/db/models"
+	"chatops/internal/db/repository"
 	"chatops/internal/kube"
 	"chatops/internal/monitoring"
+	"context"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -20,47 +29,133 @@ import (
 
 type handlerFunc func(telebot.Context) error
 
-func withConfirmation(handler handlerFunc) handlerFunc {
-	return func(originalContext telebot.Context) error {
-		yesBtn := telebot.InlineButton{
-			Unique: "confirm_yes",
-			Text:   "Да",
-		}
-		noBtn := telebot.InlineButton{
-			Unique: "confirm_no",
-			Text:   "Нет",
-		}
+// confirmationTTL - сколько команда ждет ответа на запрос подтверждения
+const confirmationTTL = 10 * time.Minute
 
-		_, err := originalContext.Bot().Send(originalContext.Chat(), "Вы уверены?", &telebot.ReplyMarkup{
-			InlineKeyboard: [][]telebot.InlineButton{
-				{yesBtn, noBtn},
-			},
-		})
+var (
+	confirmYesBtn = telebot.InlineButton{Unique: "confirm_yes", Text: "Да"}
+	confirmNoBtn  = telebot.InlineButton{Unique: "confirm_no", Text: "Нет"}
+)
 
-		if err != nil {
-			return err
+// pendingConfirmation - команда, ожидающая ответа на "Вы уверены?"
+type pendingConfirmation struct {
+	ctx       telebot.Context
+	handler   handlerFunc
+	operation *models.Operation
+	userID    uint
+	role      string // роль, которую требует команда
+	createdAt time.Time
+}
+
+// confirmations хранит ожидающие подтверждения команды по идентификатору запроса,
+// который передается в Data кнопок, поэтому запросы разных пользователей не мешают друг другу
+type confirmations struct {
+	mu       sync.Mutex
+	seq      uint64
+	pending  map[string]*pendingConfirmation
+	sessions *auth.SessionStore
+}
+
+func newConfirmations(sessions *auth.SessionStore) *confirmations {
+	return &confirmations{pending: make(map[string]*pendingConfirmation), sessions: sessions}
+}
+
+// register подключает обработчики кнопок подтверждения
+func (s *confirmations) register(bot *telebot.Bot) {
+	bot.Handle(&confirmYesBtn, s.onYes)
+	bot.Handle(&confirmNoBtn, s.onNo)
+}
+
+// request запрашивает подтверждение команды; handler выполнится после нажатия "Да"
+func (s *confirmations) request(c telebot.Context, session *auth.Session, role string, operation *models.Operation, handler handlerFunc) error {
+	s.mu.Lock()
+	s.expireLocked(time.Now())
+	s.seq++
+	id := strconv.FormatUint(s.seq, 10)
+	s.pending[id] = &pendingConfirmation{
+		ctx:       c,
+		handler:   handler,
+		operation: operation,
+		userID:    session.UserID,
+		role:      role,
+		createdAt: time.Now(),
+	}
+	s.mu.Unlock()
+
+	yesBtn, noBtn := confirmYesBtn, confirmNoBtn
+	yesBtn.Data, noBtn.Data = id, id
+	_, err := c.Bot().Send(c.Chat(), "Вы уверены?", &telebot.ReplyMarkup{
+		InlineKeyboard: [][]telebot.InlineButton{{yesBtn, noBtn}},
+	})
+	if err != nil {
+		s.mu.Lock()
+		delete(s.pending, id)
+		s.mu.Unlock()
+		handlers.FinishAudit(c, models.ConfirmationSkipped, err)
+		return err
+	}
+	return nil
+}
+
+// take забирает запрос подтверждения, если его отправил тот же пользователь; повторное нажатие запрос уже не найдет
+func (s *confirmations) take(cb telebot.Context) (*pendingConfirmation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked(time.Now())
+	id := cb.Callback().Data
+	p, ok := s.pending[id]
+	if !ok {
+		return nil, cb.Respond(&telebot.CallbackResponse{Text: "Запрос уже обработан или устарел"})
+	}
+	if cb.Sender().ID != p.ctx.Sender().ID {
+		return nil, cb.Respond(&telebot.CallbackResponse{Text: "Это не для вас"})
+	}
+	delete(s.pending, id)
+	return p, nil
+}
+
+// expireLocked отменяет запросы, на которые не ответили за confirmationTTL
+func (s *confirmations) expireLocked(now time.Time) {
+	for id, p := range s.pending {
+		if now.Sub(p.createdAt) > confirmationTTL {
+			delete(s.pending, id)
+			handlers.FinishAudit(p.ctx, models.ConfirmationRejected, nil)
 		}
+	}
+}
 
-		originalContext.Bot().Handle(&yesBtn, func(cb telebot.Context) error {
-			if cb.Sender().ID != originalContext.Sender().ID {
-				return cb.Respond(&telebot.CallbackResponse{Text: "Это не для вас"})
-			}
-			cb.Respond()
-			return handler(originalContext)
-		})
+func (s *confirmations) onYes(cb telebot.Context) error {
+	p, err := s.take(cb)
+	if p == nil {
+		return err
+	}
+	cb.Respond()
+
+	// за время ожидания сессия могла истечь, а роль - измениться
+	session, ok := s.sessions.Get(cb.Sender().ID)
+	if !ok || session.UserID != p.userID || !auth.HasRole(session.Role, p.role) {
+		p.operation.Status = models.OperationDenied
+		p.operation.Error = "сессия истекла или роль изменилась до подтверждения"
+		handlers.FinishAudit(p.ctx, models.ConfirmationConfirmed, nil)
+		return cb.Send("Сессия истекла или недостаточно прав. Введите /start и повторите команду.")
+	}
+	auth.WithSession(p.ctx, session)
+	err = p.handler(p.ctx)
+	handlers.FinishAudit(p.ctx, models.ConfirmationConfirmed, err)
+	return err
+}
 
-		originalContext.Bot().Handle(&noBtn, func(cb telebot.Context) error {
-			if cb.Sender().ID != originalContext.Sender().ID {
-				return cb.Respond(&telebot.CallbackResponse{Text: "Это не для вас"})
-			}
-			cb.Respond()
-			return cb.Send("Отмена")
-		})
-		return nil
+func (s *confirmations) onNo(cb telebot.Context) error {
+	p, err := s.take(cb)
+	if p == nil {
+		return err
 	}
+	cb.Respond()
+	handlers.FinishAudit(p.ctx, models.ConfirmationRejected, nil)
+	return cb.Send("Отмена")
 }
 
-func startPoller() {
+func startPoller(bot *telebot.Bot, kubeClient *kube.K8sClient) {
 	// Получаем URL'ы из переменных окружения
 	prometheusURL := os.Getenv("PROMETHEUS_URL")
 	alertmanagerURL := os.Getenv("ALERTMANAGER_URL")
@@ -72,8 +167,88 @@ func startPoller() {
 		return
 	}
 
-	// Создаем поллер с интервалом 40 секунд
-	poller := app.NewAlertPoller(monitoringClient, 40*time.Second)
+	// Уведомления, которые не удалось доставить дежурным, уходят в резервный чат
+	var fallbackChatID int64
+	if v := os.Getenv("ALERT_FALLBACK_CHAT_ID"); v != "" {
+		fallbackChatID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			log.Printf("Некорректное значение ALERT_FALLBACK_CHAT_ID=%q: %v", v, err)
+		}
+	}
+
+	// Повторное уведомление по активному алерту, как repeat_interval в Alertmanager
+	repeatInterval := 4 * time.Hour
+	if v := os.Getenv("ALERT_REPEAT_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			repeatInterval = d
+		} else {
+			log.Printf("Некорректное значение ALERT_REPEAT_INTERVAL=%q: %v", v, err)
+		}
+	}
+
+	// Источник алертов: опрос Alertmanager (poll, по умолчанию) или прием webhook-уведомлений (webhook).
+	// В режиме webhook поллер каждые 40 секунд прогоняет обработчики по принятым алертам,
+	// чтобы эскалации продвигались и без новых уведомлений.
+	var source app.MonitoringClient = monitoringClient
+	var webhook *app.AlertWebhook
+	var server *http.Server
+	switch mode := os.Getenv("ALERT_SOURCE"); mode {
+	case "", "poll":
+	case "webhook":
+		webhook = app.NewAlertWebhook(os.Getenv("ALERT_WEBHOOK_TOKEN"))
+		// Набор алертов сверяется с Alertmanager при старте и раз в ALERT_WEBHOOK_RECONCILE (5m),
+		// чтобы алерты, о погасании которых не пришло уведомление, не оставались активными
+		if alertmanagerURL != "" {
+			reconcile := 5 * time.Minute
+			if v := os.Getenv("ALERT_WEBHOOK_RECONCILE"); v != "" {
+				if d, err := time.ParseDuration(v); err == nil && d > 0 {
+					reconcile = d
+				} else {
+					log.Printf("Некорректное значение ALERT_WEBHOOK_RECONCILE=%q", v)
+				}
+			}
+			webhook.WithReconciliation(monitoringClient, reconcile)
+		}
+		source = webhook
+	default:
+		log.Printf("Некорректное значение ALERT_SOURCE=%q, используется опрос", mode)
+	}
+
+	// Сработавшие алерты группируются в инциденты и рассылаются дежурным
+	dbAdapter := &app.DBAdapter{}
+	alerter := app.NewAlerter(source, dbAdapter).
+		WithNotifier(notify.NewTelegramNotifier(bot), fallbackChatID).
+		WithDeduplication(dbAdapter, repeatInterval)
+	// ALERT_CHARTS=true добавляет к уведомлению график выражения алерта за последний час
+	if v, _ := strconv.ParseBool(os.Getenv("ALERT_CHARTS")); v {
+		alerter.WithCharts(monitoringClient, time.Hour)
+	}
+	escalator := app.NewEscalator(dbAdapter, notify.NewTelegramNotifier(bot))
+	poller := app.NewAlertPoller(source, 40*time.Second, app.NewIncidentCorrelator(dbAdapter), alerter, escalator)
+	// SLO вычисляются по Prometheus раз в минуту, о быстром расходе бюджета ошибок предупреждаются дежурные сервиса
+	sloPoller := app.NewSLOPoller(monitoringClient, dbAdapter, notify.NewTelegramNotifier(bot), time.Minute).
+		WithNotificationStore(dbAdapter)
+	// Предупреждения Kubernetes пересылаются в подписанные чаты, подписки перечитываются раз в минуту
+	eventWatcher := eventWatcherFromEnv(app.NewEventWatcher(kubeClient, dbAdapter, notify.NewTelegramNotifier(bot), time.Minute))
+
+	if webhook != nil {
+		webhook.OnUpdate(poller.Trigger)
+		// Первая сверка выполняется до запуска поллера
+		webhook.Start()
+		addr := os.Getenv("ALERT_WEBHOOK_ADDR")
+		if addr == "" {
+			addr = ":9095"
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/alertmanager/webhook", webhook)
+		server = &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			log.Printf("Alertmanager webhook receiver listening on %s", addr)
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("Alertmanager webhook receiver failed: %v", err)
+			}
+		}()
+	}
 
 	// Создаем канал для обработки сигналов
 	sigChan := make(chan os.Signal, 1)
@@ -82,14 +257,107 @@ func startPoller() {
 	// Запускаем поллер
 	poller.Start()
 	log.Println("Alert poller started")
+	sloPoller.Start()
+	log.Println("SLO poller started")
+	eventWatcher.Start()
+	log.Println("Event watcher started")
 
 	// Ждем сигнала для завершения
 	<-sigChan
 	log.Println("Received shutdown signal")
 
+	if server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("Error stopping webhook receiver: %v", err)
+		}
+		cancel()
+		webhook.Stop()
+	}
+
 	// Останавливаем поллер
 	poller.Stop()
 	log.Println("Alert poller stopped")
+	sloPoller.Stop()
+	log.Println("SLO poller stopped")
+	eventWatcher.Stop()
+	log.Println("Event watcher stopped")
+}
+
+// eventWatcherFromEnv настраивает пересылку событий: KUBE_EVENT_RATE_LIMIT - как часто пересылаются
+// события с одной причиной, KUBE_EVENT_REASONS - причины через запятую (по умолчанию все предупреждения)
+func eventWatcherFromEnv(watcher *app.EventWatcher) *app.EventWatcher {
+	if v := os.Getenv("KUBE_EVENT_RATE_LIMIT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			watcher.WithRateLimit(d)
+		} else {
+			log.Printf("Некорректное значение KUBE_EVENT_RATE_LIMIT=%q", v)
+		}
+	}
+	if v := os.Getenv("KUBE_EVENT_REASONS"); v != "" {
+		var reasons []string
+		for _, reason := range strings.Split(v, ",") {
+			if reason = strings.TrimSpace(reason); reason != "" {
+				reasons = append(reasons, reason)
+			}
+		}
+		watcher.WithReasons(reasons...)
+	}
+	return watcher
+}
+
+// promQLLimitsFromEnv читает ограничения /promql: PROMQL_TIMEOUT, PROMQL_MAX_SERIES, PROMQL_MAX_SAMPLES
+func promQLLimitsFromEnv() handlers.PromQLLimits {
+	limits := handlers.GlobalPromQLLimits
+	if v := os.Getenv("PROMQL_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			limits.Timeout = d
+		} else {
+			log.Printf("Некорректное значение PROMQL_TIMEOUT=%q", v)
+		}
+	}
+	if v := os.Getenv("PROMQL_MAX_SERIES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limits.MaxSeries = n
+		} else {
+			log.Printf("Некорректное значение PROMQL_MAX_SERIES=%q", v)
+		}
+	}
+	if v := os.Getenv("PROMQL_MAX_SAMPLES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n >= 0 {
+			limits.MaxSamples = n
+		} else {
+			log.Printf("Некорректное значение PROMQL_MAX_SAMPLES=%q", v)
+		}
+	}
+	return limits
+}
+
+// importQueryCatalog загружает в БД каталог запросов из YAML-файла QUERY_CATALOG.
+// Запросы и дашборды с теми же именами заменяются, добавленные из чата остаются.
+func importQueryCatalog() {
+	path := os.Getenv("QUERY_CATALOG")
+	if path == "" {
+		return
+	}
+	catalog, err := monitoring.LoadQueryCatalog(path)
+	if err != nil {
+		log.Printf("Failed to load query catalog %s: %v", path, err)
+		return
+	}
+	for _, q := range catalog.Queries {
+		query := &models.SavedQuery{Name: q.Name, Description: q.Description, Expr: q.Expr, Unit: q.Unit}
+		if err := repository.SaveQuery(query); err != nil {
+			log.Printf("Failed to save query %s: %v", q.Name, err)
+		}
+	}
+	for _, d := range catalog.Dashboards {
+		dashboard := &models.Dashboard{Name: d.Name, Service: d.Service, Namespace: d.Namespace, Queries: strings.Join(d.Panels, ",")}
+		if err := repository.SaveDashboard(dashboard); err != nil {
+			log.Printf("Failed to save dashboard %s: %v", d.Name, err)
+		}
+	}
+	log.Printf("Loaded query catalog %s: %d queries, %d dashboards", path, len(catalog.Queries), len(catalog.Dashboards))
 }
 
 func main() {
@@ -98,7 +366,11 @@ func main() {
 		log.Println("Не удалось загрузить .env файл, используются переменные окружения системы")
 	}
 
-	migrations.AutoMigrate()
+	if err := migrations.AutoMigrate(); err != nil {
+		log.Printf("Failed to migrate database: %v", err)
+	} else {
+		importQueryCatalog()
+	}
 
 	token := os.Getenv("TELEGRAM_BOT_TOKEN")
 
@@ -122,6 +394,7 @@ func main() {
 	}
 	handlers.SetKubeClient(kubeClient)
 	handlers.SetMonitorClient(monitorClient)
+	handlers.SetPromQLLimits(promQLLimitsFromEnv())
 
 	pref := telebot.Settings{
 		Token:  token,
@@ -134,49 +407,214 @@ func main() {
 	}
 
 	// Запускаем поллер в отдельной горутине
-	go startPoller()
+	go startPoller(bot, kubeClient)
 
 	helpMsg := `Доступные функции:
 
 	/start - чтобы авторизоваться
+	/logout - завершить сессию
 	/status [name или id] - проверка статуса сервиса
 	/metric [сервис] [строка] - вывод метрики сервиса
+	/graph [сервис] [метрика] [период] [namespace] - график метрики
+	/promql [выражение] - произвольный запрос PromQL
+	/queries - каталог запросов и дашбордов
+	/query [имя] [сервис] [namespace] - выполнить запрос из каталога
+	/query_set [имя] [выражение] - сохранить запрос в каталог
+	/query_del [имя] - удалить запрос из каталога
+	/dash [имя] [сервис] [namespace] - показать дашборд
+	/dash_set [имя] [запрос1,запрос2] [сервис] [namespace] - сохранить дашборд
+	/dash_del [имя] - удалить дашборд
+	/slo [сервис] - выполнение SLO и остаток бюджета ошибок
+	/slo_set [namespace/сервис] [имя] [availability|latency] [цель, %] [окно, дней] [запрос] - сохранить SLO
+	/slo_del [сервис] [имя] - удалить SLO
 	/list_metric [сервис] [строка] - поиск метрики, содержащую данную строку в названии
-	/scale [namespace]/[name] [количество реплик] - масштабирование сервиса
-	/restart [namespace]/[name] - перезапуск сервиса
-	/rollback [namespace]/[name] [номер ревизии] - откат сервиса к указанной ревизии
-	/history - вывод истории операций
-	/operations - вывод списка операций
-	/revisions [namespace/name] - вывод списка ревизий
-	/list_pods [namespace]/[name] - вывод списка pod'ов
+	/scale [вид/][namespace]/[name] [количество реплик] - масштабирование сервиса (deployment или statefulset)
+	/restart [вид/][namespace]/[name] - перезапуск сервиса (deployment, statefulset или daemonset)
+	/rollback [вид/][namespace]/[name] [номер ревизии] - откат сервиса к указанной ревизии
+	/history [id] - последние инциденты или хронология инцидента
+	/incident_open [severity] [namespace]/[service] [описание] - открыть инцидент
+	/ack [id] - подтвердить инцидент
+	/assign [id] [логин] - назначить исполнителя
+	/comment [id] [текст] - комментарий к инциденту
+	/mitigate [id] [комментарий] - инцидент смягчен
+	/resolve [id] [комментарий] - закрыть инцидент
+	/reopen [id] [комментарий] - переоткрыть инцидент
+	/escalations - политики эскалации
+	/escalation_set [метка] [минуты] [цель...] - задать политику эскалации (admin)
+	/escalation_del [метка] - удалить политику эскалации (admin)
+	/oncall [метка] - кто дежурит сейчас
+	/oncall_schedule [метка] [daily|weekly] [HH:MM] [часовой пояс] [логины через запятую] [день недели] - задать ротацию (admin)
+	/oncall_swap [метка] [логин1] [логин2] - обменяться ближайшими сменами
+	/oncall_override [метка] [логин] [с] [по] [причина] - подменить дежурного (отпуск, замена)
+	/operations [user=логин] [resource=namespace[/name]|kind/namespace/name] [since=24h] [until=время] [page=N] - журнал операций
+	/revisions [вид/][namespace/name] - вывод списка ревизий
+	/list_pods <namespace>[/<deployment>] [-l селектор] [--field-selector селектор] [--page N] - таблица pod'ов
+	/logs [namespace]/[pod] [контейнер] [--tail N] [--since 10m] [--previous] [--grep шаблон] - логи пода (namespace/deploy/имя - всех подов deployment)
+	/tail [namespace]/[pod] [контейнер] [--for 1m] [--grep шаблон] - новые строки лога в реальном времени
+	/events [вид/][namespace]/[имя] [--warnings] - события Kubernetes (для deployment - с ReplicaSet и подами, для sts/ds - с подами)
+	/events_sub [namespace] - пересылать предупреждения Kubernetes из namespace в чат
+	/events_unsub [namespace] - отменить пересылку предупреждений
   /ai_help [строка] - команда для общения с ИИ и преобразования текста в команды
-	/alerts - Проверка алертов
+	/alerts [матчеры] [active|silenced|inhibited] - Проверка алертов
+	/silence [матчеры] [длительность] [комментарий] - заглушить алерты
+	/silences - список тишин
+	/unsilence [id] - снять тишину
+	/user_add [логин] [пароль] [роль] - создание пользователя (admin)
+	/user_passwd [логин] [пароль] - смена пароля пользователя (admin)
+	/user_disable [логин] - отключение учетной записи (admin)
+	/user_enable [логин] - включение учетной записи (admin)
+	/grant [логин] [ns=namespace|deploy=namespace/name|sts=...|ds=...] - выдача права на ресурсы (admin)
+	/revoke [логин] [ns=namespace|deploy=namespace/name|sts=...|ds=...] - отзыв права (admin)
+	/grants [логин] - список прав пользователя (admin)
 	/help - выводит все доступные команды`
 
 	var commandHandlers = map[string]handlerFunc{
-		"/status":      handlers.StatusHandler,
-		"/metric":      handlers.MetricHandler,
-		"/list_metric": handlers.ListMetricsHandler,
-		"/scale":       handlers.ScaleHandler,
-		"/restart":     handlers.RestartHandler,
-		"/rollback":    handlers.RollbackHandler,
-		"/history":     handlers.HistoryHandler,
-		"/operations":  handlers.OperationsHandler,
-		"/list_pods":   handlers.ListPodsHandler,
-		"/revisions":   handlers.RevisionsHandler,
-		"/ai_help":     handlers.AiHelpHandler,
-		"/alerts":      handlers.AlertsHandler,
+		"/status":          handlers.StatusHandler,
+		"/metric":          handlers.MetricHandler,
+		"/list_metric":     handlers.ListMetricsHandler,
+		"/scale":           handlers.ScaleHandler,
+		"/restart":         handlers.RestartHandler,
+		"/rollback":        handlers.RollbackHandler,
+		"/history":         handlers.HistoryHandler,
+		"/operations":      handlers.OperationsHandler,
+		"/list_pods":       handlers.ListPodsHandler,
+		"/logs":            handlers.LogsHandler,
+		"/tail":            handlers.TailHandler,
+		"/events":          handlers.EventsHandler,
+		"/events_sub":      handlers.EventsSubscribeHandler,
+		"/events_unsub":    handlers.EventsUnsubscribeHandler,
+		"/revisions":       handlers.RevisionsHandler,
+		"/ai_help":         handlers.AiHelpHandler,
+		"/alerts":          handlers.AlertsHandler,
+		"/user_add":        handlers.UserAddHandler,
+		"/user_passwd":     handlers.UserPasswordHandler,
+		"/user_disable":    handlers.UserDisableHandler,
+		"/user_enable":     handlers.UserEnableHandler,
+		"/grant":           handlers.GrantHandler,
+		"/revoke":          handlers.RevokeHandler,
+		"/grants":          handlers.GrantsHandler,
+		"/incident_open":   handlers.IncidentOpenHandler,
+		"/ack":             handlers.IncidentAckHandler,
+		"/assign":          handlers.IncidentAssignHandler,
+		"/comment":         handlers.IncidentCommentHandler,
+		"/mitigate":        handlers.IncidentMitigateHandler,
+		"/resolve":         handlers.IncidentResolveHandler,
+		"/reopen":          handlers.IncidentReopenHandler,
+		"/escalation_set":  handlers.EscalationSetHandler,
+		"/escalation_del":  handlers.EscalationDeleteHandler,
+		"/escalations":     handlers.EscalationsHandler,
+		"/oncall":          handlers.OnCallHandler,
+		"/oncall_schedule": handlers.OnCallScheduleHandler,
+		"/oncall_swap":     handlers.OnCallSwapHandler,
+		"/oncall_override": handlers.OnCallOverrideHandler,
+		"/graph":           handlers.GraphHandler,
+		"/promql":          handlers.PromQLHandler,
+		"/queries":         handlers.QueriesHandler,
+		"/query":           handlers.QueryRunHandler,
+		"/query_set":       handlers.QuerySetHandler,
+		"/query_del":       handlers.QueryDeleteHandler,
+		"/dash":            handlers.DashHandler,
+		"/dash_set":        handlers.DashSetHandler,
+		"/dash_del":        handlers.DashDeleteHandler,
+		"/slo":             handlers.SLOHandler,
+		"/slo_set":         handlers.SLOSetHandler,
+		"/slo_del":         handlers.SLODeleteHandler,
+		"/silence":         handlers.SilenceHandler,
+		"/silences":        handlers.SilencesHandler,
+		"/unsilence":       handlers.UnsilenceHandler,
 	}
+
+	// Минимальная роль, необходимая для выполнения команды
+	var commandRoles = map[string]string{
+		"/status":          models.RoleViewer,
+		"/metric":          models.RoleViewer,
+		"/list_metric":     models.RoleViewer,
+		"/scale":           models.RoleOperator,
+		"/restart":         models.RoleOperator,
+		"/rollback":        models.RoleOperator,
+		"/history":         models.RoleViewer,
+		"/operations":      models.RoleViewer,
+		"/list_pods":       models.RoleViewer,
+		"/logs":            models.RoleViewer,
+		"/tail":            models.RoleViewer,
+		"/events":          models.RoleViewer,
+		"/events_sub":      models.RoleOperator,
+		"/events_unsub":    models.RoleOperator,
+		"/revisions":       models.RoleViewer,
+		"/ai_help":         models.RoleViewer,
+		"/alerts":          models.RoleViewer,
+		"/user_add":        models.RoleAdmin,
+		"/user_passwd":     models.RoleAdmin,
+		"/user_disable":    models.RoleAdmin,
+		"/user_enable":     models.RoleAdmin,
+		"/grant":           models.RoleAdmin,
+		"/revoke":          models.RoleAdmin,
+		"/grants":          models.RoleAdmin,
+		"/incident_open":   models.RoleOperator,
+		"/ack":             models.RoleOperator,
+		"/assign":          models.RoleOperator,
+		"/comment":         models.RoleOperator,
+		"/mitigate":        models.RoleOperator,
+		"/resolve":         models.RoleOperator,
+		"/reopen":          models.RoleOperator,
+		"/escalation_set":  models.RoleAdmin,
+		"/escalation_del":  models.RoleAdmin,
+		"/escalations":     models.RoleViewer,
+		"/oncall":          models.RoleViewer,
+		"/oncall_schedule": models.RoleAdmin,
+		"/oncall_swap":     models.RoleOperator,
+		"/oncall_override": models.RoleOperator,
+		"/graph":           models.RoleViewer,
+		"/promql":          models.RoleViewer,
+		"/queries":         models.RoleViewer,
+		"/query":           models.RoleViewer,
+		"/query_set":       models.RoleOperator,
+		"/query_del":       models.RoleOperator,
+		"/dash":            models.RoleViewer,
+		"/dash_set":        models.RoleOperator,
+		"/dash_del":        models.RoleOperator,
+		"/slo":             models.RoleViewer,
+		"/slo_set":         models.RoleOperator,
+		"/slo_del":         models.RoleOperator,
+		"/silence":         models.RoleOperator,
+		"/silences":        models.RoleViewer,
+		"/unsilence":       models.RoleOperator,
+	}
+
+	sessionTTL := 12 * time.Hour
+	if v := os.Getenv("SESSION_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			sessionTTL = d
+		} else {
+			log.Printf("Некорректное значение SESSION_TTL=%q: %v", v, err)
+		}
+	}
+	sessions := auth.NewSessionStore(sessionTTL)
+	handlers.SetSessionStore(sessions)
+	confirms := newConfirmations(sessions)
+	confirms.register(bot)
+
+	var stateMu sync.Mutex
 	var userState = make(map[int64]string)
-	var userLogin = ""
-	var userPassword = ""
-	var userStatusAuthorization = false
+	var userLogin = make(map[int64]string)
 
 	bot.Handle("/start", func(c telebot.Context) error {
 		userID := c.Sender().ID
+		stateMu.Lock()
 		userState[userID] = "login"
+		stateMu.Unlock()
 		return c.Send("Введите свой логин:")
 	})
+	bot.Handle("/logout", func(c telebot.Context) error {
+		if sessions.Delete(c.Sender().ID) {
+			return c.Send("Сессия завершена.")
+		}
+		return c.Send("Вы не авторизованы.")
+	})
+	ackBtn := notify.AckButton("")
+	bot.Handle(&ackBtn, handlers.AlertAckHandler)
+	silenceBtn := notify.SilenceButton("")
+	bot.Handle(&silenceBtn, handlers.AlertSilenceHandler)
 	bot.Handle("/help", func(c telebot.Context) error {
 		return c.Send(helpMsg)
 	})
@@ -184,56 +622,129 @@ func main() {
 	bot.Handle(telebot.OnText, func(c telebot.Context) error {
 		text := c.Text()
 		userID := c.Sender().ID
+
+		stateMu.Lock()
+		state, inLogin := userState[userID]
+		stateMu.Unlock()
+
 		if strings.HasPrefix(text, "/") {
-			if _, ok := userState[userID]; ok {
+			if inLogin {
 				return nil
 			}
-			if userStatusAuthorization {
-				parts := strings.SplitN(text, " ", 2)
-				cmd := parts[0]
-				if handler, ok := commandHandlers[cmd]; ok {
-					return withConfirmation(handler)(c)
-				}
-				return c.Send("Введите одну из предложенных команд")
-			} else {
+			session, ok := sessions.Get(userID)
+			if !ok {
 				return c.Send("Вы не авторизованы. Введите /start для авторизации.")
 			}
-		} else {
-			switch userState[userID] {
-			case "login":
-				userState[userID] = "password"
-				userLogin = c.Text()
-				return c.Send("Теперь введите пароль:")
-			case "password":
-				delete(userState, userID)
-				userPassword = c.Text()
-				userStatusAuthorization = handlers.ProofLoginPaswordHandler(userLogin, userPassword)
-				if userStatusAuthorization {
-					return c.Send("Авторизация успешна!")
-				} else {
-					return c.Send("Неверный логин или пароль.")
+			parts := strings.SplitN(text, " ", 2)
+			cmd := parts[0]
+			if handlers.IsSensitiveCommand(cmd) {
+				if err := c.Delete(); err != nil {
+					log.Printf("Не удалось удалить сообщение с паролем: %v", err)
 				}
-			default:
-				return c.Send("Непонятные входные данные или что то пошло не так.")
 			}
+			handler, ok := commandHandlers[cmd]
+			if !ok {
+				return c.Send("Введите одну из предложенных команд")
+			}
+			auth.WithSession(c, session)
+			operation := handlers.StartAudit(c, session)
+			if !auth.HasRole(session.Role, commandRoles[cmd]) {
+				log.Printf("Отказ в доступе: пользователь %s (роль %s) пытался выполнить %s", session.Login, session.Role, cmd)
+				operation.Status = models.OperationDenied
+				operation.Error = fmt.Sprintf("роль %s не позволяет выполнить команду", session.Role)
+				handlers.FinishAudit(c, models.ConfirmationSkipped, nil)
+				return c.Send("Недостаточно прав для выполнения команды.")
+			}
+			return confirms.request(c, session, commandRoles[cmd], operation, handler)
 		}
 
+		switch state {
+		case "login":
+			stateMu.Lock()
+			userState[userID] = "password"
+			userLogin[userID] = c.Text()
+			stateMu.Unlock()
+			return c.Send("Теперь введите пароль:")
+		case "password":
+			stateMu.Lock()
+			login := userLogin[userID]
+			delete(userState, userID)
+			delete(userLogin, userID)
+			stateMu.Unlock()
+			password := c.Text()
+			if err := c.Delete(); err != nil {
+				log.Printf("Не удалось удалить сообщение с паролем: %v", err)
+			}
+			user := handlers.ProofLoginPaswordHandler(login, password)
+			if user == nil {
+				return c.Send("Неверный логин или пароль.")
+			}
+			session := sessions.Start(userID, user)
+			if err := repository.SetUserTelegramChatID(user.ID, c.Chat().ID); err != nil {
+				log.Printf("Не удалось сохранить чат Telegram пользователя %s: %v", user.Login, err)
+			}
+			return c.Send(fmt.Sprintf("Авторизация успешна! Роль: %s", session.Role))
+		default:
+			return c.Send("Непонятные входные данные или что то пошло не так.")
+		}
 	})
 	commands := []telebot.Command{
 		{Text: "start", Description: "Авторизация в системе"},
+		{Text: "logout", Description: "Завершение сессии"},
 		{Text: "status", Description: "Проверка статуса [name|id]"},
 		{Text: "metric", Description: "Получение метрик сервиса"},
 		{Text: "list_metric", Description: "Полуение списка доступных "},
 		{Text: "scale", Description: "Масштабирование"},
 		{Text: "restart", Description: "Перезапуск"},
 		{Text: "rollback", Description: "Откат изменений"},
-		{Text: "history", Description: "История операций"},
+		{Text: "history", Description: "Хронология инцидентов"},
+		{Text: "incident_open", Description: "Открыть инцидент"},
+		{Text: "ack", Description: "Подтвердить инцидент"},
+		{Text: "assign", Description: "Назначить исполнителя инцидента"},
+		{Text: "comment", Description: "Комментарий к инциденту"},
+		{Text: "mitigate", Description: "Инцидент смягчен"},
+		{Text: "resolve", Description: "Закрыть инцидент"},
+		{Text: "reopen", Description: "Переоткрыть инцидент"},
+		{Text: "escalations", Description: "Политики эскалации"},
+		{Text: "escalation_set", Description: "Задать политику эскалации"},
+		{Text: "escalation_del", Description: "Удалить политику эскалации"},
+		{Text: "oncall", Description: "Кто дежурит сейчас"},
+		{Text: "oncall_schedule", Description: "Задать ротацию дежурств"},
+		{Text: "oncall_swap", Description: "Обменяться сменами"},
+		{Text: "oncall_override", Description: "Подменить дежурного"},
+		{Text: "graph", Description: "График метрики"},
+		{Text: "promql", Description: "Запрос PromQL"},
+		{Text: "queries", Description: "Каталог запросов"},
+		{Text: "query", Description: "Запрос из каталога"},
+		{Text: "query_set", Description: "Сохранить запрос в каталог"},
+		{Text: "query_del", Description: "Удалить запрос из каталога"},
+		{Text: "dash", Description: "Дашборд"},
+		{Text: "dash_set", Description: "Сохранить дашборд"},
+		{Text: "dash_del", Description: "Удалить дашборд"},
+		{Text: "slo", Description: "SLO и бюджет ошибок"},
+		{Text: "slo_set", Description: "Сохранить SLO"},
+		{Text: "slo_del", Description: "Удалить SLO"},
+		{Text: "silence", Description: "Заглушить алерты"},
+		{Text: "silences", Description: "Список тишин"},
+		{Text: "unsilence", Description: "Снять тишину"},
 		{Text: "operations", Description: "Список операций"},
 		{Text: "revisions", Description: "Список ревизий"},
 		{Text: "list_pods", Description: "Список pod'ов"},
+		{Text: "logs", Description: "Логи пода"},
+		{Text: "tail", Description: "Логи пода в реальном времени"},
+		{Text: "events", Description: "События Kubernetes"},
+		{Text: "events_sub", Description: "Подписка на предупреждения Kubernetes"},
+		{Text: "events_unsub", Description: "Отмена подписки на предупреждения"},
 		{Text: "help", Description: "Список доступных команд"},
 		{Text: "ai_help", Description: "преобразования текста в команды с помошью ИИ"},
 		{Text: "alerts", Description: "Проверка алертов"},
+		{Text: "user_add", Description: "Создание пользователя"},
+		{Text: "user_passwd", Description: "Смена пароля пользователя"},
+		{Text: "user_disable", Description: "Отключение учетной записи"},
+		{Text: "user_enable", Description: "Включение учетной записи"},
+		{Text: "grant", Description: "Выдача права на ресурсы"},
+		{Text: "revoke", Description: "Отзыв права на ресурсы"},
+		{Text: "grants", Description: "Список прав пользователя"},
 	}
 	if err := bot.SetCommands(commands); err != nil {
 		log.Println("Ошибка при установке команд:", err)
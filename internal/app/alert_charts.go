@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"time"
+
+	"chatops/internal/chart"
+	"chatops/internal/monitoring"
+)
+
+// RangeQuerier выполняет range-запросы PromQL
+type RangeQuerier interface {
+	QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) ([]monitoring.Series, error)
+}
+
+// PhotoNotifier доставляет изображение в чат Telegram
+type PhotoNotifier interface {
+	SendPhoto(ctx context.Context, chatID int64, photo []byte, caption string) error
+}
+
+// WithCharts включает отправку графика выражения алерта за последние window
+// вместе с уведомлением; требуется notifier, реализующий PhotoNotifier
+func (a *Alerter) WithCharts(querier RangeQuerier, window time.Duration) *Alerter {
+	a.charts = querier
+	a.chartWindow = window
+	return a
+}
+
+// AlertExpression извлекает выражение правила из generatorURL алерта (параметр g0.expr)
+func AlertExpression(alert monitoring.Alert) string {
+	if alert.GeneratorURL == "" {
+		return ""
+	}
+	u, err := url.Parse(alert.GeneratorURL)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("g0.expr")
+}
+
+// renderChart строит график выражения алерта; при любой ошибке возвращает nil,
+// чтобы уведомление ушло без графика
+func (a *Alerter) renderChart(ctx context.Context, alert monitoring.Alert) []byte {
+	if a.charts == nil {
+		return nil
+	}
+	if _, ok := a.notifier.(PhotoNotifier); !ok {
+		return nil
+	}
+	expr := AlertExpression(alert)
+	if expr == "" {
+		return nil
+	}
+
+	end := a.now()
+	step := a.chartWindow / 240
+	if step < 15*time.Second {
+		step = 15 * time.Second
+	}
+	series, err := a.charts.QueryRange(ctx, expr, end.Add(-a.chartWindow), end, step)
+	if err != nil {
+		log.Printf("Failed to query chart for alert %s: %v", alert.Labels["alertname"], err)
+		return nil
+	}
+	image, err := chart.Render(expr, series)
+	if err != nil {
+		log.Printf("Failed to render chart for alert %s: %v", alert.Labels["alertname"], err)
+		return nil
+	}
+	return image
+}
@@ -3,77 +3,235 @@ package app
 import (
 	"chatops/internal/monitoring"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"time"
 
 	"chatops/internal/db/models"
 )
 
 type MonitoringClient interface {
-	GetActiveAlerts(ctx context.Context) ([]monitoring.Alert, error)
+	GetActiveAlerts(ctx context.Context, filter monitoring.AlertFilter) ([]monitoring.Alert, error)
 }
 
 type DutyFinder interface {
 	GetDutyUsersByLabel(label string) ([]models.User, error)
 }
 
+// Notifier доставляет сообщение в чат Telegram
+type Notifier interface {
+	Send(ctx context.Context, chatID int64, text string) error
+}
+
+// NotificationStore хранит состояние отправленных уведомлений по fingerprint алерта
+type NotificationStore interface {
+	GetActiveAlertNotifications() ([]models.AlertNotification, error)
+	SaveAlertNotification(notification *models.AlertNotification) error
+}
+
 type Alerter struct {
-	monClient MonitoringClient
-	db        DutyFinder
+	monClient      MonitoringClient
+	db             DutyFinder
+	notifier       Notifier
+	fallbackChatID int64
+	state          NotificationStore
+	repeatInterval time.Duration
+	charts         RangeQuerier
+	chartWindow    time.Duration
+	now            func() time.Time
 }
 
 func NewAlerter(monClient MonitoringClient, db DutyFinder) *Alerter {
 	return &Alerter{
 		monClient: monClient,
 		db:        db,
+		now:       time.Now,
 	}
 }
 
+// WithDeduplication включает учет уже отправленных уведомлений:
+// повторное уведомление по активному алерту отправляется не чаще repeatInterval
+// (аналог repeat_interval в Alertmanager)
+func (a *Alerter) WithDeduplication(state NotificationStore, repeatInterval time.Duration) *Alerter {
+	a.state = state
+	a.repeatInterval = repeatInterval
+	return a
+}
+
+// SetClock подменяет источник времени (используется в тестах)
+func (a *Alerter) SetClock(now func() time.Time) {
+	a.now = now
+}
+
+// WithNotifier включает доставку уведомлений через notifier.
+// Уведомления, которые не удалось доставить дежурному, отправляются в fallbackChatID.
+func (a *Alerter) WithNotifier(notifier Notifier, fallbackChatID int64) *Alerter {
+	a.notifier = notifier
+	a.fallbackChatID = fallbackChatID
+	return a
+}
+
 func (a *Alerter) CheckAndNotify(ctx context.Context) error {
 	log.Println("Checking for active alerts...")
 
-	alerts, err := a.monClient.GetActiveAlerts(ctx)
+	alerts, err := a.monClient.GetActiveAlerts(ctx, monitoring.AlertFilter{})
 	if err != nil {
 		return fmt.Errorf("failed to get active alerts: %w", err)
 	}
 
+	return a.ProcessAlerts(ctx, alerts)
+}
+
+// ProcessAlerts находит дежурных для каждого алерта и отправляет им уведомления.
+// Если задано хранилище состояний, уже отправленные уведомления повторяются
+// не чаще repeatInterval, а по погасшим алертам рассылается сообщение о восстановлении.
+func (a *Alerter) ProcessAlerts(ctx context.Context, alerts []monitoring.Alert) error {
+	var notified map[string]*models.AlertNotification
+	if a.state != nil {
+		active, err := a.state.GetActiveAlertNotifications()
+		if err != nil {
+			return fmt.Errorf("failed to load notification state: %w", err)
+		}
+		notified = make(map[string]*models.AlertNotification, len(active))
+		for i := range active {
+			notified[active[i].Fingerprint] = &active[i]
+		}
+	}
+
 	if len(alerts) == 0 {
 		log.Println("No active alerts found.")
-		return nil
+	} else {
+		log.Printf("Found %d active alerts. Processing...\n", len(alerts))
 	}
 
-	log.Printf("Found %d active alerts. Processing...\n", len(alerts))
-
+	now := a.now()
 	for _, alert := range alerts {
-		var dutyUsers []models.User
-		var err error
-
-		for key, value := range alert.Labels {
-			labelToSearch := fmt.Sprintf("%s=%s", key, value)
-			dutyUsers, err = a.db.GetDutyUsersByLabel(labelToSearch)
-			if err != nil {
-				log.Printf("Error searching duty users for label %s: %v", labelToSearch, err)
-				continue
-			}
-			if len(dutyUsers) > 0 {
-				log.Printf("Found %d duty users for label '%s'", len(dutyUsers), labelToSearch)
-				break
-			}
+		fingerprint := AlertFingerprint(alert)
+		state, seen := notified[fingerprint]
+		delete(notified, fingerprint)
+		if seen && now.Sub(state.LastNotifiedAt) < a.repeatInterval {
+			continue
+		}
+		// Заглушенные и подавленные алерты не рассылаются, но остаются активными,
+		// чтобы по ним не ушло сообщение о восстановлении
+		if alert.Suppressed() {
+			log.Printf("Alert %s is suppressed, skipping notification", fingerprint)
+			continue
 		}
 
+		dutyUsers := a.findDutyUsers(alert.Labels)
+		var chart []byte
 		if len(dutyUsers) == 0 {
 			log.Printf("No duty users found for alert with labels: %v. Skipping.", alert.Labels)
-			continue
+		} else {
+			chart = a.renderChart(ctx, alert)
 		}
-
+		reported := false
 		for _, user := range dutyUsers {
 			notification := formatNotification(user.Login, alert)
-			log.Println(notification)
+			if seen {
+				notification = "🔁 Алерт все еще активен\n" + notification
+			}
+			delivered, ok := a.deliver(ctx, user, notification, fingerprint)
+			reported = reported || ok
+			if delivered && chart != nil {
+				caption := fmt.Sprintf("%s за %s", alert.Labels["alertname"], a.chartWindow)
+				if err := a.notifier.(PhotoNotifier).SendPhoto(ctx, user.TelegramChatID, chart, caption); err != nil {
+					log.Printf("Failed to deliver chart to %s: %v", user.Login, err)
+				}
+			}
+		}
+
+		// Без доставленного уведомления состояние не обновляется: алерт будет разослан
+		// на следующем тике, например когда появится дежурный
+		if a.state == nil || !reported {
+			continue
+		}
+		if !seen {
+			labels, _ := json.Marshal(alert.Labels)
+			state = &models.AlertNotification{
+				Fingerprint:     fingerprint,
+				AlertName:       alert.Labels["alertname"],
+				Labels:          string(labels),
+				FirstNotifiedAt: now,
+			}
+		}
+		state.LastNotifiedAt = now
+		if err := a.state.SaveAlertNotification(state); err != nil {
+			log.Printf("Error saving notification state for alert %s: %v", fingerprint, err)
+		}
+	}
+
+	// Алерты, которых больше нет среди активных, считаются погасшими
+	for _, state := range notified {
+		var labels map[string]string
+		if err := json.Unmarshal([]byte(state.Labels), &labels); err != nil {
+			log.Printf("Error decoding labels of alert %s: %v", state.Fingerprint, err)
+		}
+		for _, user := range a.findDutyUsers(labels) {
+			a.deliver(ctx, user, formatResolvedNotification(user.Login, state.AlertName, labels, now.Sub(state.FirstNotifiedAt)), "")
+		}
+		resolvedAt := now
+		state.ResolvedAt = &resolvedAt
+		if err := a.state.SaveAlertNotification(state); err != nil {
+			log.Printf("Error saving notification state for alert %s: %v", state.Fingerprint, err)
 		}
 	}
 	return nil
 }
+
+// findDutyUsers ищет дежурных по первой метке алерта, для которой они назначены
+func (a *Alerter) findDutyUsers(labels map[string]string) []models.User {
+	for key, value := range labels {
+		labelToSearch := fmt.Sprintf("%s=%s", key, value)
+		dutyUsers, err := a.db.GetDutyUsersByLabel(labelToSearch)
+		if err != nil {
+			log.Printf("Error searching duty users for label %s: %v", labelToSearch, err)
+			continue
+		}
+		if len(dutyUsers) > 0 {
+			log.Printf("Found %d duty users for label '%s'", len(dutyUsers), labelToSearch)
+			return dutyUsers
+		}
+	}
+	return nil
+}
+
+// deliver отправляет уведомление дежурному; непустой ackKey добавляет кнопку подтверждения.
+// delivered - уведомление доставлено лично дежурному, reported - доставлено дежурному
+// или сообщение о неудаче доставлено в резервный чат.
+func (a *Alerter) deliver(ctx context.Context, user models.User, notification, ackKey string) (delivered, reported bool) {
+	if a.notifier == nil {
+		log.Println(notification)
+		return false, false
+	}
+
+	var reason string
+	if user.TelegramChatID == 0 {
+		reason = "пользователь ни разу не запускал бота"
+	} else if err := sendWithAck(ctx, a.notifier, user.TelegramChatID, notification, ackKey); err != nil {
+		reason = fmt.Sprintf("ошибка доставки: %v", err)
+	} else {
+		log.Printf("Notification delivered to %s", user.Login)
+		return true, true
+	}
+
+	log.Printf("Failed to notify %s: %s", user.Login, reason)
+	if a.fallbackChatID == 0 {
+		log.Println(notification)
+		return false, false
+	}
+	report := fmt.Sprintf("⚠️ Не удалось доставить уведомление @%s (%s)\n\n%s", user.Login, reason, notification)
+	if err := a.notifier.Send(ctx, a.fallbackChatID, report); err != nil {
+		log.Printf("Failed to deliver notification to fallback chat: %v", err)
+		log.Println(notification)
+		return false, false
+	}
+	return false, true
+}
 func formatNotification(dutyPersonUsername string, alert monitoring.Alert) string {
 	var details []string
 	for key, value := range alert.Labels {
@@ -100,3 +258,29 @@ func formatNotification(dutyPersonUsername string, alert monitoring.Alert) strin
 		labelsFormatted,
 	)
 }
+
+func formatResolvedNotification(dutyPersonUsername, alertName string, labels map[string]string, duration time.Duration) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var details []string
+	for _, key := range keys {
+		details = append(details, fmt.Sprintf("- %s: %s", key, labels[key]))
+	}
+
+	return fmt.Sprintf(
+		"УВЕДОМЛЕНИЕ ДЛЯ: @%s\n"+
+			"==================================\n"+
+			"✅ Алерт погас: %s\n"+
+			"⏱ Длительность: %s\n\n"+
+			"🏷 Метки:\n"+
+			"%s\n"+
+			"==================================",
+		dutyPersonUsername,
+		alertName,
+		duration.Round(time.Second),
+		strings.Join(details, "\n"),
+	)
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"chatops/internal/db/models"
 	"chatops/internal/db/repository"
+	"time"
 )
 
 type DBAdapter struct{}
@@ -10,3 +11,67 @@ type DBAdapter struct{}
 func (a *DBAdapter) GetDutyUsersByLabel(label string) ([]models.User, error) {
 	return repository.GetDutyUsersByLabel(label)
 }
+
+func (a *DBAdapter) GetActiveAlertIncidents() ([]models.IncidentHistory, error) {
+	return repository.GetActiveAlertIncidents()
+}
+
+func (a *DBAdapter) OpenAlertIncident(groupKey, title, severity, namespace, service string) (*models.IncidentHistory, error) {
+	return repository.OpenAlertIncident(groupKey, title, severity, namespace, service)
+}
+
+func (a *DBAdapter) AddIncidentAlert(incidentID uint, fingerprint, alertName string, startsAt time.Time) error {
+	return repository.AddIncidentAlert(incidentID, fingerprint, alertName, startsAt)
+}
+
+func (a *DBAdapter) ResolveIncidentAlert(incidentID uint, fingerprint string) error {
+	return repository.ResolveIncidentAlert(incidentID, fingerprint)
+}
+
+func (a *DBAdapter) TransitionIncident(id uint, to string, userID *uint, note string) (*models.IncidentHistory, error) {
+	return repository.TransitionIncident(id, to, userID, note)
+}
+
+func (a *DBAdapter) GetActiveAlertNotifications() ([]models.AlertNotification, error) {
+	return repository.GetActiveAlertNotifications()
+}
+
+func (a *DBAdapter) SaveAlertNotification(notification *models.AlertNotification) error {
+	return repository.SaveAlertNotification(notification)
+}
+
+func (a *DBAdapter) GetEscalationPolicies() ([]models.EscalationPolicy, error) {
+	return repository.GetEscalationPolicies()
+}
+
+func (a *DBAdapter) GetOpenEscalations() ([]models.AlertEscalation, error) {
+	return repository.GetOpenEscalations()
+}
+
+func (a *DBAdapter) SaveEscalation(escalation *models.AlertEscalation) error {
+	return repository.SaveEscalation(escalation)
+}
+
+func (a *DBAdapter) GetUserByLogin(login string) (*models.User, error) {
+	return repository.GetUserByLogin(login)
+}
+
+func (a *DBAdapter) GetSLOs() ([]models.SLO, error) {
+	return repository.GetSLOs()
+}
+
+func (a *DBAdapter) GetSLONotifications() ([]models.SLONotification, error) {
+	return repository.GetSLONotifications()
+}
+
+func (a *DBAdapter) SaveSLONotification(notification *models.SLONotification) error {
+	return repository.SaveSLONotification(notification)
+}
+
+func (a *DBAdapter) DeleteSLONotification(sloID uint) error {
+	return repository.DeleteSLONotification(sloID)
+}
+
+func (a *DBAdapter) GetEventSubscriptions() ([]models.EventSubscription, error) {
+	return repository.GetEventSubscriptions()
+}
@@ -0,0 +1,243 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"chatops/internal/db/models"
+	"chatops/internal/monitoring"
+)
+
+// AckNotifier отправляет сообщение с кнопкой подтверждения алерта
+type AckNotifier interface {
+	Notifier
+	SendWithAck(ctx context.Context, chatID int64, text, ackKey string) error
+}
+
+// EscalationStore хранит политики и состояние эскалаций
+type EscalationStore interface {
+	DutyFinder
+	GetEscalationPolicies() ([]models.EscalationPolicy, error)
+	GetOpenEscalations() ([]models.AlertEscalation, error)
+	SaveEscalation(escalation *models.AlertEscalation) error
+	GetUserByLogin(login string) (*models.User, error)
+}
+
+// Escalator уведомляет следующий уровень политики эскалации,
+// если уведомление об алерте не подтверждено за отведенное время
+type Escalator struct {
+	store    EscalationStore
+	notifier Notifier
+	now      func() time.Time
+}
+
+func NewEscalator(store EscalationStore, notifier Notifier) *Escalator {
+	return &Escalator{
+		store:    store,
+		notifier: notifier,
+		now:      time.Now,
+	}
+}
+
+// SetClock подменяет источник времени (используется в тестах)
+func (e *Escalator) SetClock(now func() time.Time) {
+	e.now = now
+}
+
+// ProcessAlerts запускает эскалации для новых алертов, подходящих под политику,
+// продвигает просроченные неподтвержденные эскалации и закрывает эскалации погасших алертов
+func (e *Escalator) ProcessAlerts(ctx context.Context, alerts []monitoring.Alert) error {
+	policies, err := e.store.GetEscalationPolicies()
+	if err != nil {
+		return fmt.Errorf("failed to load escalation policies: %w", err)
+	}
+	open, err := e.store.GetOpenEscalations()
+	if err != nil {
+		return fmt.Errorf("failed to load escalations: %w", err)
+	}
+
+	policyByID := make(map[uint]models.EscalationPolicy, len(policies))
+	for _, p := range policies {
+		policyByID[p.ID] = p
+	}
+	escalations := make(map[string]*models.AlertEscalation, len(open))
+	for i := range open {
+		escalations[open[i].Fingerprint] = &open[i]
+	}
+
+	now := e.now()
+	for _, alert := range alerts {
+		fingerprint := AlertFingerprint(alert)
+		escalation, ok := escalations[fingerprint]
+		delete(escalations, fingerprint)
+
+		if !ok {
+			policy, found := matchEscalationPolicy(policies, alert.Labels)
+			if !found {
+				continue
+			}
+			labels, _ := json.Marshal(alert.Labels)
+			escalation = &models.AlertEscalation{
+				Fingerprint: fingerprint,
+				PolicyID:    policy.ID,
+				AlertName:   alert.Labels["alertname"],
+				Labels:      string(labels),
+				NextAt:      now.Add(time.Duration(policy.TimeoutMinutes) * time.Minute),
+			}
+			if err := e.store.SaveEscalation(escalation); err != nil {
+				log.Printf("Error saving escalation for alert %s: %v", fingerprint, err)
+			}
+			continue
+		}
+
+		if escalation.AcknowledgedAt != nil || now.Before(escalation.NextAt) {
+			continue
+		}
+		if alert.Suppressed() {
+			escalation.NextAt = now.Add(time.Duration(policyByID[escalation.PolicyID].TimeoutMinutes) * time.Minute)
+			if err := e.store.SaveEscalation(escalation); err != nil {
+				log.Printf("Error saving escalation for alert %s: %v", fingerprint, err)
+			}
+			continue
+		}
+		policy, found := policyByID[escalation.PolicyID]
+		if !found {
+			continue
+		}
+		tiers := policy.TierList()
+		if escalation.Level >= len(tiers) {
+			continue
+		}
+
+		target := tiers[escalation.Level]
+		escalation.Level++
+		escalation.NextAt = now.Add(time.Duration(policy.TimeoutMinutes) * time.Minute)
+		log.Printf("Escalating alert %s to level %d (%s)", fingerprint, escalation.Level, target)
+		e.notifyTier(ctx, target, formatEscalation(alert, escalation.Level, policy.TimeoutMinutes), fingerprint)
+		if err := e.store.SaveEscalation(escalation); err != nil {
+			log.Printf("Error saving escalation for alert %s: %v", fingerprint, err)
+		}
+	}
+
+	// Алерт погас - эскалация больше не нужна
+	for _, escalation := range escalations {
+		resolvedAt := now
+		escalation.ResolvedAt = &resolvedAt
+		if err := e.store.SaveEscalation(escalation); err != nil {
+			log.Printf("Error saving escalation for alert %s: %v", escalation.Fingerprint, err)
+		}
+	}
+	return nil
+}
+
+func (e *Escalator) notifyTier(ctx context.Context, target, text, ackKey string) {
+	kind, value, _ := strings.Cut(target, ":")
+	var chatIDs []int64
+	switch kind {
+	case "duty":
+		users, err := e.store.GetDutyUsersByLabel(value)
+		if err != nil {
+			log.Printf("Error searching duty users for label %s: %v", value, err)
+		}
+		for _, user := range users {
+			if user.TelegramChatID != 0 {
+				chatIDs = append(chatIDs, user.TelegramChatID)
+			}
+		}
+	case "user":
+		user, err := e.store.GetUserByLogin(value)
+		if err != nil {
+			log.Printf("Escalation target user %s not found: %v", value, err)
+		} else if user.TelegramChatID != 0 {
+			chatIDs = append(chatIDs, user.TelegramChatID)
+		}
+	case "chat":
+		chatID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			log.Printf("Invalid escalation chat %q: %v", value, err)
+		} else {
+			chatIDs = append(chatIDs, chatID)
+		}
+	default:
+		log.Printf("Unknown escalation target %q", target)
+	}
+
+	if len(chatIDs) == 0 {
+		log.Printf("Escalation target %s has no reachable chats", target)
+		return
+	}
+	for _, chatID := range chatIDs {
+		if err := sendWithAck(ctx, e.notifier, chatID, text, ackKey); err != nil {
+			log.Printf("Failed to deliver escalation to chat %d: %v", chatID, err)
+		}
+	}
+}
+
+// ValidateEscalationTarget проверяет формат цели эскалации
+func ValidateEscalationTarget(target string) error {
+	kind, value, ok := strings.Cut(target, ":")
+	if !ok || value == "" {
+		return fmt.Errorf("ожидается duty:<метка>, user:<логин> или chat:<id>, получено %q", target)
+	}
+	switch kind {
+	case "duty", "user":
+		return nil
+	case "chat":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("некорректный ID чата в %q", target)
+		}
+		return nil
+	default:
+		return fmt.Errorf("неизвестный тип цели %q", kind)
+	}
+}
+
+func matchEscalationPolicy(policies []models.EscalationPolicy, labels map[string]string) (models.EscalationPolicy, bool) {
+	for _, policy := range policies {
+		key, value, _ := strings.Cut(policy.Label, "=")
+		if v, ok := labels[key]; ok && v == value {
+			return policy, true
+		}
+	}
+	return models.EscalationPolicy{}, false
+}
+
+func sendWithAck(ctx context.Context, notifier Notifier, chatID int64, text, ackKey string) error {
+	if ackNotifier, ok := notifier.(AckNotifier); ok && ackKey != "" {
+		return ackNotifier.SendWithAck(ctx, chatID, text, ackKey)
+	}
+	return notifier.Send(ctx, chatID, text)
+}
+
+func formatEscalation(alert monitoring.Alert, level, timeoutMinutes int) string {
+	keys := make([]string, 0, len(alert.Labels))
+	for key := range alert.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var details []string
+	for _, key := range keys {
+		details = append(details, fmt.Sprintf("- %s: %s", key, alert.Labels[key]))
+	}
+
+	return fmt.Sprintf(
+		"⏫ ЭСКАЛАЦИЯ (уровень %d)\n"+
+			"==================================\n"+
+			"🚨 Алерт %s не подтвержден за %d мин.\n\n"+
+			"📋 Описание: %s\n\n"+
+			"🏷 Метки:\n"+
+			"%s\n"+
+			"==================================",
+		level,
+		alert.Labels["alertname"],
+		timeoutMinutes,
+		alert.Annotations["summary"],
+		strings.Join(details, "\n"),
+	)
+}
@@ -0,0 +1,236 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"chatops/internal/db/models"
+	"chatops/internal/kube"
+)
+
+// eventRewatchDelay - пауза перед повторным открытием оборвавшегося watch
+const eventRewatchDelay = 5 * time.Second
+
+// EventSource следит за событиями Warning в namespace, его реализует kube.K8sClient
+type EventSource interface {
+	WatchWarningEvents(ctx context.Context, namespace string) (<-chan kube.EventInfo, error)
+}
+
+// EventSubscriptionStore хранит подписки чатов на события namespace
+type EventSubscriptionStore interface {
+	GetEventSubscriptions() ([]models.EventSubscription, error)
+}
+
+// eventLimit - последнее пересланное событие с данной причиной и число подавленных после него
+type eventLimit struct {
+	sentAt     time.Time
+	suppressed int
+}
+
+// EventWatcher пересылает события Warning Kubernetes в чаты, подписанные на namespace.
+// Подписки перечитываются раз в interval. О событиях с одной причиной (BackOff, FailedScheduling)
+// в namespace сообщается не чаще rateLimit, число подавленных событий добавляется к следующему сообщению.
+type EventWatcher struct {
+	source     EventSource
+	store      EventSubscriptionStore
+	notifier   Notifier
+	interval   time.Duration
+	rateLimit  time.Duration
+	reasons    map[string]bool
+	now        func() time.Time
+	mu         sync.Mutex
+	chats      map[string][]int64
+	watches    map[string]context.CancelFunc
+	limits     map[string]*eventLimit
+	ctx        context.Context
+	cancelFunc context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+func NewEventWatcher(source EventSource, store EventSubscriptionStore, notifier Notifier, interval time.Duration) *EventWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &EventWatcher{
+		source:     source,
+		store:      store,
+		notifier:   notifier,
+		interval:   interval,
+		rateLimit:  10 * time.Minute,
+		now:        time.Now,
+		chats:      make(map[string][]int64),
+		watches:    make(map[string]context.CancelFunc),
+		limits:     make(map[string]*eventLimit),
+		ctx:        ctx,
+		cancelFunc: cancel,
+	}
+}
+
+// WithRateLimit задает, как часто пересылаются события с одной причиной в namespace
+func (w *EventWatcher) WithRateLimit(rateLimit time.Duration) *EventWatcher {
+	w.rateLimit = rateLimit
+	return w
+}
+
+// WithReasons ограничивает пересылку событиями с указанными причинами; без них пересылаются все события Warning
+func (w *EventWatcher) WithReasons(reasons ...string) *EventWatcher {
+	if len(reasons) == 0 {
+		w.reasons = nil
+		return w
+	}
+	w.reasons = make(map[string]bool, len(reasons))
+	for _, reason := range reasons {
+		w.reasons[reason] = true
+	}
+	return w
+}
+
+// SetClock подменяет источник времени (используется в тестах)
+func (w *EventWatcher) SetClock(now func() time.Time) {
+	w.now = now
+}
+
+func (w *EventWatcher) Start() {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		if err := w.Sync(); err != nil {
+			log.Printf("Error syncing event subscriptions: %v", err)
+		}
+
+		for {
+			select {
+			case <-w.ctx.Done():
+				log.Println("Event watcher stopping...")
+				return
+			case <-ticker.C:
+				if err := w.Sync(); err != nil {
+					log.Printf("Error syncing event subscriptions: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (w *EventWatcher) Stop() {
+	w.cancelFunc()
+	w.wg.Wait()
+}
+
+// Sync перечитывает подписки: открывает watch для новых namespace и закрывает для тех, на которые больше никто не подписан
+func (w *EventWatcher) Sync() error {
+	subscriptions, err := w.store.GetEventSubscriptions()
+	if err != nil {
+		return fmt.Errorf("failed to load event subscriptions: %w", err)
+	}
+	chats := make(map[string][]int64)
+	for _, s := range subscriptions {
+		chats[s.Namespace] = append(chats[s.Namespace], s.ChatID)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ctx.Err() != nil {
+		return nil
+	}
+	w.chats = chats
+	// ограничения частоты нужны только в пределах rateLimit и только для отслеживаемых namespace
+	now := w.now()
+	for key, limit := range w.limits {
+		namespace, _, _ := strings.Cut(key, "/")
+		if _, ok := chats[namespace]; !ok || now.Sub(limit.sentAt) >= w.rateLimit {
+			delete(w.limits, key)
+		}
+	}
+	for namespace, cancel := range w.watches {
+		if _, ok := chats[namespace]; !ok {
+			cancel()
+			delete(w.watches, namespace)
+			log.Printf("Stopped watching events in namespace %s", namespace)
+		}
+	}
+	for namespace := range chats {
+		if _, ok := w.watches[namespace]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(w.ctx)
+		w.watches[namespace] = cancel
+		w.wg.Add(1)
+		go w.watch(ctx, namespace)
+		log.Printf("Watching warning events in namespace %s", namespace)
+	}
+	return nil
+}
+
+// watch пересылает события namespace и открывает watch заново после разрыва
+func (w *EventWatcher) watch(ctx context.Context, namespace string) {
+	defer w.wg.Done()
+	for {
+		events, err := w.source.WatchWarningEvents(ctx, namespace)
+		if err != nil {
+			log.Printf("Error watching events in namespace %s: %v", namespace, err)
+		} else {
+			for e := range events {
+				w.Forward(ctx, namespace, e)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(eventRewatchDelay):
+		}
+	}
+}
+
+// Forward пересылает событие чатам, подписанным на namespace, с учетом ограничения частоты по причине
+func (w *EventWatcher) Forward(ctx context.Context, namespace string, e kube.EventInfo) {
+	if w.reasons != nil && !w.reasons[e.Reason] {
+		return
+	}
+
+	w.mu.Lock()
+	chats := w.chats[namespace]
+	now := w.now()
+	key := namespace + "/" + e.Reason
+	limit, ok := w.limits[key]
+	if ok && now.Sub(limit.sentAt) < w.rateLimit {
+		limit.suppressed++
+		w.mu.Unlock()
+		return
+	}
+	suppressed := 0
+	if ok {
+		suppressed = limit.suppressed
+	}
+	w.limits[key] = &eventLimit{sentAt: now}
+	w.mu.Unlock()
+
+	text := formatWarningEvent(namespace, e, suppressed)
+	for _, chatID := range chats {
+		if w.notifier == nil {
+			log.Printf("Cannot deliver event notification to chat %d:\n%s", chatID, text)
+			continue
+		}
+		if err := w.notifier.Send(ctx, chatID, text); err != nil {
+			log.Printf("Failed to deliver event notification to chat %d: %v", chatID, err)
+		}
+	}
+}
+
+func formatWarningEvent(namespace string, e kube.EventInfo, suppressed int) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("⚠️ %s: %s %s", namespace, e.Reason, e.Object))
+	if e.Count > 1 {
+		sb.WriteString(fmt.Sprintf(" (×%d)", e.Count))
+	}
+	sb.WriteString("\n" + strings.TrimSpace(e.Message))
+	if suppressed > 0 {
+		sb.WriteString(fmt.Sprintf("\nС предыдущего сообщения пропущено событий %s: %d", e.Reason, suppressed))
+	}
+	return sb.String()
+}
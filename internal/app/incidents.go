@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"chatops/internal/db/models"
+	"chatops/internal/monitoring"
+)
+
+// IncidentStore хранит инциденты, созданные по алертам
+type IncidentStore interface {
+	GetActiveAlertIncidents() ([]models.IncidentHistory, error)
+	OpenAlertIncident(groupKey, title, severity, namespace, service string) (*models.IncidentHistory, error)
+	AddIncidentAlert(incidentID uint, fingerprint, alertName string, startsAt time.Time) error
+	ResolveIncidentAlert(incidentID uint, fingerprint string) error
+	TransitionIncident(id uint, to string, userID *uint, note string) (*models.IncidentHistory, error)
+}
+
+// IncidentCorrelator группирует сработавшие алерты в инциденты
+// и закрывает инциденты, когда все их алерты погасли
+type IncidentCorrelator struct {
+	store IncidentStore
+	now   func() time.Time
+}
+
+func NewIncidentCorrelator(store IncidentStore) *IncidentCorrelator {
+	return &IncidentCorrelator{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+type alertGroup struct {
+	key    string
+	alerts []monitoring.Alert
+}
+
+// ProcessAlerts сопоставляет текущий список активных алертов с открытыми инцидентами
+func (c *IncidentCorrelator) ProcessAlerts(ctx context.Context, alerts []monitoring.Alert) error {
+	groups := groupAlerts(alerts)
+
+	incidents, err := c.store.GetActiveAlertIncidents()
+	if err != nil {
+		return fmt.Errorf("failed to load active incidents: %w", err)
+	}
+	byKey := make(map[string]*models.IncidentHistory, len(incidents))
+	for i := range incidents {
+		byKey[incidents[i].GroupKey] = &incidents[i]
+	}
+
+	for _, group := range groups {
+		incident, ok := byKey[group.key]
+		if !ok {
+			first := group.alerts[0]
+			incident, err = c.store.OpenAlertIncident(
+				group.key,
+				incidentTitle(first),
+				first.Labels["severity"],
+				first.Labels["namespace"],
+				first.Labels["job"],
+			)
+			if err != nil {
+				log.Printf("Error opening incident for alert group %s: %v", group.key, err)
+				continue
+			}
+			log.Printf("Opened incident #%d for alert group %s", incident.ID, group.key)
+		}
+
+		known := make(map[string]bool, len(incident.Alerts))
+		for _, a := range incident.Alerts {
+			known[a.Fingerprint] = true
+		}
+		current := make(map[string]bool, len(group.alerts))
+		for _, alert := range group.alerts {
+			fp := AlertFingerprint(alert)
+			current[fp] = true
+			if known[fp] {
+				continue
+			}
+			if err := c.store.AddIncidentAlert(incident.ID, fp, alert.Labels["alertname"], c.now()); err != nil {
+				log.Printf("Error adding alert %s to incident #%d: %v", fp, incident.ID, err)
+			}
+		}
+		for fp := range known {
+			if !current[fp] {
+				if err := c.store.ResolveIncidentAlert(incident.ID, fp); err != nil {
+					log.Printf("Error resolving alert %s of incident #%d: %v", fp, incident.ID, err)
+				}
+			}
+		}
+		delete(byKey, group.key)
+	}
+
+	// Оставшиеся инциденты больше не имеют активных алертов
+	for _, incident := range byKey {
+		for _, a := range incident.Alerts {
+			if err := c.store.ResolveIncidentAlert(incident.ID, a.Fingerprint); err != nil {
+				log.Printf("Error resolving alert %s of incident #%d: %v", a.Fingerprint, incident.ID, err)
+			}
+		}
+		if _, err := c.store.TransitionIncident(incident.ID, models.IncidentResolved, nil, "все алерты погасли"); err != nil {
+			log.Printf("Error auto-resolving incident #%d: %v", incident.ID, err)
+			continue
+		}
+		log.Printf("Auto-resolved incident #%d for alert group %s", incident.ID, incident.GroupKey)
+	}
+	return nil
+}
+
+// AlertGroupKey возвращает ключ группировки алерта: alertname/namespace/job
+func AlertGroupKey(alert monitoring.Alert) string {
+	return fmt.Sprintf("%s/%s/%s", alert.Labels["alertname"], alert.Labels["namespace"], alert.Labels["job"])
+}
+
+// AlertFingerprint возвращает fingerprint алерта из Alertmanager
+// или вычисляет его по набору меток, если Alertmanager его не передал
+func AlertFingerprint(alert monitoring.Alert) string {
+	if alert.Fingerprint != "" {
+		return alert.Fingerprint
+	}
+	keys := make([]string, 0, len(alert.Labels))
+	for k := range alert.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write([]byte(alert.Labels[k]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func groupAlerts(alerts []monitoring.Alert) []alertGroup {
+	index := make(map[string]int)
+	var groups []alertGroup
+	for _, alert := range alerts {
+		key := AlertGroupKey(alert)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, alertGroup{key: key})
+		}
+		groups[i].alerts = append(groups[i].alerts, alert)
+	}
+	return groups
+}
+
+func incidentTitle(alert monitoring.Alert) string {
+	title := alert.Labels["alertname"]
+	if summary := alert.Annotations["summary"]; summary != "" {
+		title += ": " + summary
+	}
+	var scope []string
+	if ns := alert.Labels["namespace"]; ns != "" {
+		scope = append(scope, ns)
+	}
+	if job := alert.Labels["job"]; job != "" {
+		scope = append(scope, job)
+	}
+	if len(scope) > 0 {
+		title += " (" + strings.Join(scope, "/") + ")"
+	}
+	return title
+}
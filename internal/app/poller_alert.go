@@ -11,19 +11,28 @@ import (
 	"chatops/internal/monitoring"
 )
 
+// AlertProcessor обрабатывает список активных алертов, полученный поллером
+type AlertProcessor interface {
+	ProcessAlerts(ctx context.Context, alerts []monitoring.Alert) error
+}
+
 type AlertPoller struct {
-	monitoringClient *monitoring.Client
+	monitoringClient MonitoringClient
 	interval         time.Duration
+	processors       []AlertProcessor
+	trigger          chan struct{}
 	ctx              context.Context
 	cancelFunc       context.CancelFunc
 	wg               sync.WaitGroup
 }
 
-func NewAlertPoller(client *monitoring.Client, interval time.Duration) *AlertPoller {
+func NewAlertPoller(client MonitoringClient, interval time.Duration, processors ...AlertProcessor) *AlertPoller {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &AlertPoller{
 		monitoringClient: client,
 		interval:         interval,
+		processors:       processors,
+		trigger:          make(chan struct{}, 1),
 		ctx:              ctx,
 		cancelFunc:       cancel,
 	}
@@ -50,18 +59,30 @@ func (p *AlertPoller) Start() {
 				if err := p.checkAlerts(); err != nil {
 					log.Printf("Error checking alerts: %v", err)
 				}
+			case <-p.trigger:
+				if err := p.checkAlerts(); err != nil {
+					log.Printf("Error checking alerts: %v", err)
+				}
 			}
 		}
 	}()
 }
 
+// Trigger запрашивает внеочередную проверку алертов, не дожидаясь очередного тика
+func (p *AlertPoller) Trigger() {
+	select {
+	case p.trigger <- struct{}{}:
+	default:
+	}
+}
+
 func (p *AlertPoller) Stop() {
 	p.cancelFunc()
 	p.wg.Wait()
 }
 
 func (p *AlertPoller) checkAlerts() error {
-	alerts, err := p.monitoringClient.GetActiveAlerts(p.ctx)
+	alerts, err := p.monitoringClient.GetActiveAlerts(p.ctx, monitoring.AlertFilter{})
 	if err != nil {
 		return err
 	}
@@ -86,5 +107,11 @@ func (p *AlertPoller) checkAlerts() error {
 	}
 
 	log.Println(sb.String())
+
+	for _, processor := range p.processors {
+		if err := processor.ProcessAlerts(p.ctx, alerts); err != nil {
+			log.Printf("Error processing alerts: %v", err)
+		}
+	}
 	return nil
 }
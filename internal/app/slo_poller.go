@@ -0,0 +1,292 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"chatops/internal/db/models"
+	"chatops/internal/monitoring"
+)
+
+// SLOEvaluator вычисляет состояние SLO, его реализует monitoring.Client
+type SLOEvaluator interface {
+	EvaluateSLO(ctx context.Context, slo monitoring.SLO, rules []monitoring.BurnRateRule) (*monitoring.SLOStatus, error)
+}
+
+// SLOStore хранит SLO и находит дежурных по сервису
+type SLOStore interface {
+	DutyFinder
+	GetSLOs() ([]models.SLO, error)
+}
+
+// SLONotificationStore хранит состояние предупреждений по SLO между перезапусками
+type SLONotificationStore interface {
+	GetSLONotifications() ([]models.SLONotification, error)
+	SaveSLONotification(notification *models.SLONotification) error
+	DeleteSLONotification(sloID uint) error
+}
+
+// sloNotification - последнее предупреждение о расходе бюджета SLO
+type sloNotification struct {
+	severity string
+	at       time.Time
+}
+
+// SLOPoller периодически вычисляет SLO и предупреждает дежурных сервиса о быстром
+// расходе бюджета ошибок по правилам monitoring.DefaultBurnRateRules. Повторное
+// предупреждение той же важности отправляется не чаще repeatInterval,
+// после восстановления рассылается сообщение о нормализации. Без хранилища
+// состояний (WithNotificationStore) оно живет в памяти, и после перезапуска
+// предупреждения по сгорающим SLO рассылаются заново.
+type SLOPoller struct {
+	evaluator      SLOEvaluator
+	store          SLOStore
+	notifier       Notifier
+	interval       time.Duration
+	repeatInterval time.Duration
+	rules          []monitoring.BurnRateRule
+	notified       map[uint]sloNotification
+	state          SLONotificationStore
+	now            func() time.Time
+	ctx            context.Context
+	cancelFunc     context.CancelFunc
+	wg             sync.WaitGroup
+}
+
+func NewSLOPoller(evaluator SLOEvaluator, store SLOStore, notifier Notifier, interval time.Duration) *SLOPoller {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SLOPoller{
+		evaluator:      evaluator,
+		store:          store,
+		notifier:       notifier,
+		interval:       interval,
+		repeatInterval: time.Hour,
+		rules:          monitoring.DefaultBurnRateRules,
+		notified:       make(map[uint]sloNotification),
+		now:            time.Now,
+		ctx:            ctx,
+		cancelFunc:     cancel,
+	}
+}
+
+// WithRepeatInterval задает интервал повторного предупреждения по SLO, бюджет которого продолжает сгорать
+func (p *SLOPoller) WithRepeatInterval(repeatInterval time.Duration) *SLOPoller {
+	p.repeatInterval = repeatInterval
+	return p
+}
+
+// WithNotificationStore включает сохранение состояния предупреждений в state,
+// чтобы перезапуск бота не приводил к повторной рассылке и потере сообщений о восстановлении
+func (p *SLOPoller) WithNotificationStore(state SLONotificationStore) *SLOPoller {
+	p.state = state
+	return p
+}
+
+// SetClock подменяет источник времени (используется в тестах)
+func (p *SLOPoller) SetClock(now func() time.Time) {
+	p.now = now
+}
+
+func (p *SLOPoller) Start() {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		if err := p.Check(p.ctx); err != nil {
+			log.Printf("Error checking SLOs: %v", err)
+		}
+
+		for {
+			select {
+			case <-p.ctx.Done():
+				log.Println("SLO poller stopping...")
+				return
+			case <-ticker.C:
+				if err := p.Check(p.ctx); err != nil {
+					log.Printf("Error checking SLOs: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (p *SLOPoller) Stop() {
+	p.cancelFunc()
+	p.wg.Wait()
+}
+
+// Check вычисляет все SLO и рассылает предупреждения о расходе бюджета и о восстановлении
+func (p *SLOPoller) Check(ctx context.Context) error {
+	slos, err := p.store.GetSLOs()
+	if err != nil {
+		return fmt.Errorf("failed to load SLOs: %w", err)
+	}
+	if p.state != nil {
+		saved, err := p.state.GetSLONotifications()
+		if err != nil {
+			return fmt.Errorf("failed to load SLO notification state: %w", err)
+		}
+		p.notified = make(map[uint]sloNotification, len(saved))
+		for _, n := range saved {
+			p.notified[n.SLOID] = sloNotification{severity: n.Severity, at: n.LastNotifiedAt}
+		}
+	}
+
+	now := p.now()
+	known := make(map[uint]bool, len(slos))
+	for _, s := range slos {
+		known[s.ID] = true
+		status, err := p.evaluator.EvaluateSLO(ctx, SLOFromModel(s), p.rules)
+		if err != nil {
+			log.Printf("Error evaluating SLO %s/%s: %v", s.Service, s.Name, err)
+			continue
+		}
+
+		last, notified := p.notified[s.ID]
+		if len(status.Firing) == 0 {
+			if notified {
+				p.forget(s.ID)
+				p.notify(ctx, s.Service, func(login string) string { return formatSLORecovered(login, status) })
+			}
+			continue
+		}
+
+		severity := status.Firing[0].Severity
+		for _, rule := range status.Firing {
+			if rule.Severity == monitoring.BurnRateCritical {
+				severity = rule.Severity
+			}
+		}
+		if notified && last.severity == severity && now.Sub(last.at) < p.repeatInterval {
+			continue
+		}
+		p.notified[s.ID] = sloNotification{severity: severity, at: now}
+		if p.state != nil {
+			state := &models.SLONotification{SLOID: s.ID, Severity: severity, LastNotifiedAt: now}
+			if err := p.state.SaveSLONotification(state); err != nil {
+				log.Printf("Error saving notification state for SLO %s/%s: %v", s.Service, s.Name, err)
+			}
+		}
+		p.notify(ctx, s.Service, func(login string) string { return formatSLOBurn(login, status) })
+	}
+
+	// Удаленные SLO больше не отслеживаются
+	for id := range p.notified {
+		if !known[id] {
+			p.forget(id)
+		}
+	}
+	return nil
+}
+
+// forget удаляет состояние предупреждений по SLO
+func (p *SLOPoller) forget(id uint) {
+	delete(p.notified, id)
+	if p.state == nil {
+		return
+	}
+	if err := p.state.DeleteSLONotification(id); err != nil {
+		log.Printf("Error deleting notification state for SLO %d: %v", id, err)
+	}
+}
+
+// notify отправляет сообщение дежурным по метке service=<сервис>, а если их нет - job=<сервис>
+func (p *SLOPoller) notify(ctx context.Context, service string, message func(login string) string) {
+	var users []models.User
+	for _, label := range []string{"service=" + service, "job=" + service} {
+		found, err := p.store.GetDutyUsersByLabel(label)
+		if err != nil {
+			log.Printf("Error searching duty users for label %s: %v", label, err)
+			continue
+		}
+		if len(found) > 0 {
+			users = found
+			break
+		}
+	}
+	if len(users) == 0 {
+		log.Printf("No duty users found for service %s, SLO notification skipped:\n%s", service, message(""))
+		return
+	}
+
+	for _, user := range users {
+		text := message(user.Login)
+		if p.notifier == nil || user.TelegramChatID == 0 {
+			log.Printf("Cannot deliver SLO notification to %s:\n%s", user.Login, text)
+			continue
+		}
+		if err := p.notifier.Send(ctx, user.TelegramChatID, text); err != nil {
+			log.Printf("Failed to deliver SLO notification to %s: %v", user.Login, err)
+		}
+	}
+}
+
+// SLOFromModel преобразует SLO из БД в цель для вычисления
+func SLOFromModel(s models.SLO) monitoring.SLO {
+	return monitoring.SLO{
+		Name:      s.Name,
+		Service:   s.Service,
+		Namespace: s.Namespace,
+		Kind:      s.Kind,
+		Query:     s.Query,
+		Target:    s.Target,
+		Window:    time.Duration(s.WindowDays) * 24 * time.Hour,
+	}
+}
+
+func formatSLOBurn(dutyPersonUsername string, status *monitoring.SLOStatus) string {
+	slo := status.SLO
+	var rules []string
+	for _, rule := range status.Firing {
+		rules = append(rules, fmt.Sprintf("- %s: %s ×%.1f, %s ×%.1f (порог ×%.1f)",
+			rule.Severity,
+			monitoring.PromDuration(rule.Long), status.BurnRates[rule.Long],
+			monitoring.PromDuration(rule.Short), status.BurnRates[rule.Short],
+			rule.Threshold(slo.Window)))
+	}
+
+	return fmt.Sprintf(
+		"УВЕДОМЛЕНИЕ ДЛЯ: @%s\n"+
+			"==================================\n"+
+			"🔥 Быстро расходуется бюджет ошибок SLO: %s/%s\n\n"+
+			"🎯 Цель: %s за %s\n"+
+			"📉 Выполнение: %s, остаток бюджета: %s\n\n"+
+			"⏱ Скорость расхода бюджета:\n"+
+			"%s\n"+
+			"==================================",
+		dutyPersonUsername,
+		slo.Service, slo.Name,
+		FormatRatio(slo.Target), monitoring.PromDuration(slo.Window),
+		FormatRatio(status.Attainment), FormatRatio(status.BudgetRemaining),
+		strings.Join(rules, "\n"),
+	)
+}
+
+func formatSLORecovered(dutyPersonUsername string, status *monitoring.SLOStatus) string {
+	return fmt.Sprintf(
+		"УВЕДОМЛЕНИЕ ДЛЯ: @%s\n"+
+			"==================================\n"+
+			"✅ Расход бюджета ошибок SLO %s/%s вернулся в норму\n"+
+			"📉 Остаток бюджета: %s\n"+
+			"==================================",
+		dutyPersonUsername,
+		status.SLO.Service, status.SLO.Name,
+		FormatRatio(status.BudgetRemaining),
+	)
+}
+
+// FormatRatio форматирует долю в процентах, "нет данных" - для NaN
+func FormatRatio(ratio float64) string {
+	if !monitoring.IsFinite(ratio) {
+		return "нет данных"
+	}
+	percent := strconv.FormatFloat(ratio*100, 'f', 3, 64)
+	return strings.TrimRight(strings.TrimRight(percent, "0"), ".") + "%"
+}
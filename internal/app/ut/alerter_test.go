@@ -10,6 +10,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"chatops/internal/db/models"
 )
@@ -19,7 +20,7 @@ type mockMonitoringClient struct {
 	Error  error
 }
 
-func (m *mockMonitoringClient) GetActiveAlerts(ctx context.Context) ([]monitoring.Alert, error) {
+func (m *mockMonitoringClient) GetActiveAlerts(ctx context.Context, filter monitoring.AlertFilter) ([]monitoring.Alert, error) {
 	return m.Alerts, m.Error
 }
 
@@ -123,6 +124,275 @@ func TestAlerter_CheckAndNotify(t *testing.T) {
 	}
 }
 
+type sentMessage struct {
+	ChatID int64
+	Text   string
+}
+
+type fakeNotifier struct {
+	Sent    []sentMessage
+	Blocked map[int64]bool
+}
+
+func (n *fakeNotifier) Send(ctx context.Context, chatID int64, text string) error {
+	if n.Blocked[chatID] {
+		return errors.New("telegram: bot was blocked by the user (403)")
+	}
+	n.Sent = append(n.Sent, sentMessage{ChatID: chatID, Text: text})
+	return nil
+}
+
+func TestAlerter_NotifierDelivery(t *testing.T) {
+	alert := monitoring.Alert{Labels: map[string]string{"alertname": "PodCrash", "job": "api"}}
+	const fallbackChat = int64(-100500)
+
+	testCases := []struct {
+		name         string
+		users        []models.User
+		blocked      map[int64]bool
+		expectedChat []int64
+		expectedText []string
+	}{
+		{
+			name:         "Delivered to duty user chat",
+			users:        []models.User{{Login: "alice", TelegramChatID: 111}},
+			expectedChat: []int64{111},
+			expectedText: []string{"УВЕДОМЛЕНИЕ ДЛЯ: @alice"},
+		},
+		{
+			name:         "User never started the bot",
+			users:        []models.User{{Login: "bob"}},
+			expectedChat: []int64{fallbackChat},
+			expectedText: []string{"@bob (пользователь ни разу не запускал бота)"},
+		},
+		{
+			name:         "User blocked the bot",
+			users:        []models.User{{Login: "carol", TelegramChatID: 222}},
+			blocked:      map[int64]bool{222: true},
+			expectedChat: []int64{fallbackChat},
+			expectedText: []string{"blocked by the user"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifier := &fakeNotifier{Blocked: tc.blocked}
+			alerter := app.NewAlerter(&mockMonitoringClient{}, &mockDutyFinder{Users: tc.users}).
+				WithNotifier(notifier, fallbackChat)
+
+			if err := alerter.ProcessAlerts(context.Background(), []monitoring.Alert{alert}); err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+
+			if len(notifier.Sent) != len(tc.expectedChat) {
+				t.Fatalf("Expected %d messages, got %d: %+v", len(tc.expectedChat), len(notifier.Sent), notifier.Sent)
+			}
+			for i, msg := range notifier.Sent {
+				if msg.ChatID != tc.expectedChat[i] {
+					t.Errorf("Expected message to chat %d, got %d", tc.expectedChat[i], msg.ChatID)
+				}
+				if !strings.Contains(msg.Text, tc.expectedText[i]) {
+					t.Errorf("Expected message to contain '%s', but it was: \n%s", tc.expectedText[i], msg.Text)
+				}
+			}
+		})
+	}
+}
+
 func init() {
 	log.SetOutput(os.Stderr)
 }
+
+type fakeNotificationStore struct {
+	nextID uint
+	states map[uint]*models.AlertNotification
+}
+
+func (s *fakeNotificationStore) GetActiveAlertNotifications() ([]models.AlertNotification, error) {
+	var result []models.AlertNotification
+	for _, st := range s.states {
+		if st.ResolvedAt == nil {
+			result = append(result, *st)
+		}
+	}
+	return result, nil
+}
+
+func (s *fakeNotificationStore) SaveAlertNotification(n *models.AlertNotification) error {
+	if n.ID == 0 {
+		s.nextID++
+		n.ID = s.nextID
+	}
+	copied := *n
+	s.states[n.ID] = &copied
+	return nil
+}
+
+func TestAlerter_Deduplication(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeNotificationStore{states: make(map[uint]*models.AlertNotification)}
+	notifier := &fakeNotifier{}
+	alerter := app.NewAlerter(&mockMonitoringClient{}, &mockDutyFinder{Users: []models.User{{Login: "alice", TelegramChatID: 111}}}).
+		WithNotifier(notifier, 0).
+		WithDeduplication(store, time.Hour)
+	alerter.SetClock(func() time.Time { return now })
+
+	alert := monitoring.Alert{Fingerprint: "fp-1", Labels: map[string]string{"alertname": "PodCrash", "job": "api"}}
+	ctx := context.Background()
+
+	// Первое срабатывание - уведомление отправлено
+	if err := alerter.ProcessAlerts(ctx, []monitoring.Alert{alert}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(notifier.Sent) != 1 {
+		t.Fatalf("Expected 1 message after first tick, got %d", len(notifier.Sent))
+	}
+
+	// Следующий тик в пределах repeat interval - без повтора
+	now = now.Add(40 * time.Second)
+	alerter.ProcessAlerts(ctx, []monitoring.Alert{alert})
+	if len(notifier.Sent) != 1 {
+		t.Fatalf("Expected no repeat within interval, got %d messages", len(notifier.Sent))
+	}
+
+	// Состояние переживает перезапуск: новый Alerter с тем же хранилищем не шлет повтор
+	restarted := app.NewAlerter(&mockMonitoringClient{}, &mockDutyFinder{Users: []models.User{{Login: "alice", TelegramChatID: 111}}}).
+		WithNotifier(notifier, 0).
+		WithDeduplication(store, time.Hour)
+	restarted.SetClock(func() time.Time { return now })
+	restarted.ProcessAlerts(ctx, []monitoring.Alert{alert})
+	if len(notifier.Sent) != 1 {
+		t.Fatalf("Expected no repeat after restart, got %d messages", len(notifier.Sent))
+	}
+
+	// После repeat interval - повторное уведомление
+	now = now.Add(time.Hour)
+	alerter.ProcessAlerts(ctx, []monitoring.Alert{alert})
+	if len(notifier.Sent) != 2 || !strings.Contains(notifier.Sent[1].Text, "все еще активен") {
+		t.Fatalf("Expected repeat notification, got %+v", notifier.Sent)
+	}
+
+	// Алерт погас - сообщение о восстановлении, затем тишина
+	now = now.Add(time.Minute)
+	alerter.ProcessAlerts(ctx, nil)
+	if len(notifier.Sent) != 3 || !strings.Contains(notifier.Sent[2].Text, "Алерт погас: PodCrash") {
+		t.Fatalf("Expected resolved notification, got %+v", notifier.Sent)
+	}
+	alerter.ProcessAlerts(ctx, nil)
+	if len(notifier.Sent) != 3 {
+		t.Fatalf("Expected single resolved notification, got %d messages", len(notifier.Sent))
+	}
+}
+
+func TestAlerter_DeduplicationWithoutDelivery(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeNotificationStore{states: make(map[uint]*models.AlertNotification)}
+	notifier := &fakeNotifier{Blocked: map[int64]bool{111: true}}
+	duty := &mockDutyFinder{}
+	alerter := app.NewAlerter(&mockMonitoringClient{}, duty).
+		WithNotifier(notifier, 0).
+		WithDeduplication(store, time.Hour)
+	alerter.SetClock(func() time.Time { return now })
+
+	alert := monitoring.Alert{Fingerprint: "fp-1", Labels: map[string]string{"alertname": "PodCrash", "job": "api"}}
+	ctx := context.Background()
+
+	// Дежурного нет - состояние не сохраняется
+	alerter.ProcessAlerts(ctx, []monitoring.Alert{alert})
+	if len(store.states) != 0 {
+		t.Fatalf("Expected no saved state without duty users, got %+v", store.states)
+	}
+
+	// Доставка не удалась и резервного чата нет - состояние тоже не сохраняется
+	now = now.Add(time.Minute)
+	duty.Users = []models.User{{Login: "alice", TelegramChatID: 111}}
+	alerter.ProcessAlerts(ctx, []monitoring.Alert{alert})
+	if len(store.states) != 0 {
+		t.Fatalf("Expected no saved state after failed delivery, got %+v", store.states)
+	}
+
+	// Дежурный доступен - уведомление уходит сразу, не дожидаясь repeat interval
+	now = now.Add(time.Minute)
+	delete(notifier.Blocked, 111)
+	alerter.ProcessAlerts(ctx, []monitoring.Alert{alert})
+	if len(notifier.Sent) != 1 || len(store.states) != 1 {
+		t.Fatalf("Expected notification and saved state, got %+v, %+v", notifier.Sent, store.states)
+	}
+	if strings.Contains(notifier.Sent[0].Text, "все еще активен") {
+		t.Errorf("First delivered notification must not be a repeat: %s", notifier.Sent[0].Text)
+	}
+}
+
+func TestAlerter_SkipsSuppressedAlerts(t *testing.T) {
+	store := &fakeNotificationStore{states: make(map[uint]*models.AlertNotification)}
+	notifier := &fakeNotifier{}
+	alerter := app.NewAlerter(&mockMonitoringClient{}, &mockDutyFinder{Users: []models.User{{Login: "alice", TelegramChatID: 111}}}).
+		WithNotifier(notifier, 0).
+		WithDeduplication(store, time.Hour)
+
+	silenced := monitoring.Alert{
+		Fingerprint: "fp-1",
+		Labels:      map[string]string{"alertname": "PodCrash", "job": "api"},
+		Status:      monitoring.AlertStatus{State: monitoring.AlertStateSuppressed, SilencedBy: []string{"s1"}},
+	}
+	ctx := context.Background()
+
+	alerter.ProcessAlerts(ctx, []monitoring.Alert{silenced})
+	if len(notifier.Sent) != 0 {
+		t.Fatalf("Expected silenced alert not to be sent, got %+v", notifier.Sent)
+	}
+
+	// Тишина снята - уведомление уходит
+	silenced.Status = monitoring.AlertStatus{State: monitoring.AlertStateActive}
+	alerter.ProcessAlerts(ctx, []monitoring.Alert{silenced})
+	if len(notifier.Sent) != 1 {
+		t.Fatalf("Expected notification after silence expired, got %d messages", len(notifier.Sent))
+	}
+}
+
+type fakeRangeQuerier struct {
+	Queries []string
+}
+
+func (q *fakeRangeQuerier) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) ([]monitoring.Series, error) {
+	q.Queries = append(q.Queries, query)
+	return []monitoring.Series{{
+		Metric: map[string]string{"pod": "api-1"},
+		Points: []monitoring.SamplePoint{{Time: start, Value: 1}, {Time: end, Value: 2}},
+	}}, nil
+}
+
+type fakePhotoNotifier struct {
+	fakeNotifier
+	Photos []int64
+}
+
+func (n *fakePhotoNotifier) SendPhoto(ctx context.Context, chatID int64, photo []byte, caption string) error {
+	n.Photos = append(n.Photos, chatID)
+	return nil
+}
+
+func TestAlerter_AttachesChart(t *testing.T) {
+	querier := &fakeRangeQuerier{}
+	notifier := &fakePhotoNotifier{}
+	alerter := app.NewAlerter(&mockMonitoringClient{}, &mockDutyFinder{Users: []models.User{{Login: "alice", TelegramChatID: 111}}}).
+		WithNotifier(notifier, 0).
+		WithCharts(querier, time.Hour)
+
+	alert := monitoring.Alert{
+		Fingerprint:  "fp-1",
+		Labels:       map[string]string{"alertname": "HighErrorRate", "job": "api"},
+		GeneratorURL: "http://prometheus:9090/graph?g0.expr=rate%28errors_total%5B5m%5D%29+%3E+1&g0.tab=1",
+	}
+	without := monitoring.Alert{Fingerprint: "fp-2", Labels: map[string]string{"alertname": "NoExpr", "job": "api"}}
+	if err := alerter.ProcessAlerts(context.Background(), []monitoring.Alert{alert, without}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if len(querier.Queries) != 1 || querier.Queries[0] != "rate(errors_total[5m]) > 1" {
+		t.Errorf("Expected chart query for alert expression, got %v", querier.Queries)
+	}
+	if len(notifier.Sent) != 2 || len(notifier.Photos) != 1 || notifier.Photos[0] != 111 {
+		t.Errorf("Expected 2 messages and 1 chart, got %d messages and %v charts", len(notifier.Sent), notifier.Photos)
+	}
+}
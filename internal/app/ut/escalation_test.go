@@ -0,0 +1,163 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"chatops/internal/app"
+	"chatops/internal/db/models"
+	"chatops/internal/monitoring"
+)
+
+type fakeEscalationStore struct {
+	policies    []models.EscalationPolicy
+	escalations map[uint]*models.AlertEscalation
+	nextID      uint
+	duty        map[string][]models.User
+	users       map[string]*models.User
+}
+
+func (s *fakeEscalationStore) GetDutyUsersByLabel(label string) ([]models.User, error) {
+	return s.duty[label], nil
+}
+
+func (s *fakeEscalationStore) GetEscalationPolicies() ([]models.EscalationPolicy, error) {
+	return s.policies, nil
+}
+
+func (s *fakeEscalationStore) GetOpenEscalations() ([]models.AlertEscalation, error) {
+	var result []models.AlertEscalation
+	for _, e := range s.escalations {
+		if e.ResolvedAt == nil {
+			result = append(result, *e)
+		}
+	}
+	return result, nil
+}
+
+func (s *fakeEscalationStore) SaveEscalation(e *models.AlertEscalation) error {
+	if e.ID == 0 {
+		s.nextID++
+		e.ID = s.nextID
+	}
+	copied := *e
+	s.escalations[e.ID] = &copied
+	return nil
+}
+
+func (s *fakeEscalationStore) GetUserByLogin(login string) (*models.User, error) {
+	if user, ok := s.users[login]; ok {
+		return user, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (s *fakeEscalationStore) acknowledge(fingerprint string) {
+	now := time.Now()
+	for _, e := range s.escalations {
+		if e.Fingerprint == fingerprint {
+			e.AcknowledgedAt = &now
+		}
+	}
+}
+
+type fakeAckNotifier struct {
+	fakeNotifier
+	AckKeys []string
+}
+
+func (n *fakeAckNotifier) SendWithAck(ctx context.Context, chatID int64, text, ackKey string) error {
+	n.AckKeys = append(n.AckKeys, ackKey)
+	return n.Send(ctx, chatID, text)
+}
+
+func TestEscalator_Tiers(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeEscalationStore{
+		policies: []models.EscalationPolicy{
+			{ID: 1, Label: "team=payments", TimeoutMinutes: 10, Tiers: "duty:team=payments-secondary,user:lead,chat:-100"},
+		},
+		escalations: make(map[uint]*models.AlertEscalation),
+		duty: map[string][]models.User{
+			"team=payments-secondary": {{Login: "second", TelegramChatID: 201}},
+		},
+		users: map[string]*models.User{"lead": {Login: "lead", TelegramChatID: 301}},
+	}
+	notifier := &fakeAckNotifier{}
+	escalator := app.NewEscalator(store, notifier)
+	escalator.SetClock(func() time.Time { return now })
+
+	alert := monitoring.Alert{Fingerprint: "fp-1", Labels: map[string]string{"alertname": "PodCrash", "team": "payments"}}
+	unmatched := monitoring.Alert{Fingerprint: "fp-2", Labels: map[string]string{"alertname": "Other", "team": "search"}}
+	ctx := context.Background()
+	tick := func(alerts ...monitoring.Alert) {
+		if err := escalator.ProcessAlerts(ctx, alerts); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+
+	tick(alert, unmatched)
+	if len(store.escalations) != 1 || len(notifier.Sent) != 0 {
+		t.Fatalf("Expected one pending escalation and no messages, got %d escalations, %d messages", len(store.escalations), len(notifier.Sent))
+	}
+
+	now = now.Add(9 * time.Minute)
+	tick(alert, unmatched)
+	if len(notifier.Sent) != 0 {
+		t.Fatalf("Expected no escalation before timeout, got %+v", notifier.Sent)
+	}
+
+	expectedChats := []int64{201, 301, -100}
+	for i, chat := range expectedChats {
+		now = now.Add(10 * time.Minute)
+		tick(alert, unmatched)
+		if len(notifier.Sent) != i+1 {
+			t.Fatalf("Expected %d messages after tier %d, got %d", i+1, i+1, len(notifier.Sent))
+		}
+		if notifier.Sent[i].ChatID != chat {
+			t.Errorf("Expected tier %d to notify chat %d, got %d", i+1, chat, notifier.Sent[i].ChatID)
+		}
+		if !strings.Contains(notifier.Sent[i].Text, "ЭСКАЛАЦИЯ") || notifier.AckKeys[i] != "fp-1" {
+			t.Errorf("Expected escalation message with ack button, got %q (ack %q)", notifier.Sent[i].Text, notifier.AckKeys[i])
+		}
+	}
+
+	// Уровни исчерпаны - больше сообщений нет
+	now = now.Add(time.Hour)
+	tick(alert, unmatched)
+	if len(notifier.Sent) != len(expectedChats) {
+		t.Fatalf("Expected no more escalations, got %d messages", len(notifier.Sent))
+	}
+}
+
+func TestEscalator_AckStopsEscalation(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeEscalationStore{
+		policies:    []models.EscalationPolicy{{ID: 1, Label: "team=payments", TimeoutMinutes: 5, Tiers: "chat:-100"}},
+		escalations: make(map[uint]*models.AlertEscalation),
+	}
+	notifier := &fakeAckNotifier{}
+	escalator := app.NewEscalator(store, notifier)
+	escalator.SetClock(func() time.Time { return now })
+
+	alert := monitoring.Alert{Fingerprint: "fp-1", Labels: map[string]string{"alertname": "PodCrash", "team": "payments"}}
+	escalator.ProcessAlerts(context.Background(), []monitoring.Alert{alert})
+	store.acknowledge("fp-1")
+
+	now = now.Add(10 * time.Minute)
+	escalator.ProcessAlerts(context.Background(), []monitoring.Alert{alert})
+	if len(notifier.Sent) != 0 {
+		t.Fatalf("Expected acknowledged alert not to escalate, got %+v", notifier.Sent)
+	}
+
+	// Алерт погас - эскалация закрыта
+	escalator.ProcessAlerts(context.Background(), nil)
+	for _, e := range store.escalations {
+		if e.ResolvedAt == nil {
+			t.Errorf("Expected escalation %d to be resolved", e.ID)
+		}
+	}
+}
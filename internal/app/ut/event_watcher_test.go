@@ -0,0 +1,208 @@
+package app_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"chatops/internal/app"
+	"chatops/internal/db/models"
+	"chatops/internal/kube"
+)
+
+type fakeEventStore struct {
+	Subscriptions []models.EventSubscription
+}
+
+func (s *fakeEventStore) GetEventSubscriptions() ([]models.EventSubscription, error) {
+	return s.Subscriptions, nil
+}
+
+// fakeEventSource отдает по каналу на namespace; watch открывается в отдельных горутинах
+type fakeEventSource struct {
+	mu       sync.Mutex
+	channels map[string]chan kube.EventInfo
+}
+
+func (s *fakeEventSource) WatchWarningEvents(ctx context.Context, namespace string) (<-chan kube.EventInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch := make(chan kube.EventInfo)
+	s.channels[namespace] = ch
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.channels[namespace] == ch {
+			delete(s.channels, namespace)
+		}
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func (s *fakeEventSource) channel(namespace string) chan kube.EventInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.channels[namespace]
+}
+
+type lockedNotifier struct {
+	mu   sync.Mutex
+	sent []sentMessage
+}
+
+func (n *lockedNotifier) Send(ctx context.Context, chatID int64, text string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, sentMessage{ChatID: chatID, Text: text})
+	return nil
+}
+
+func (n *lockedNotifier) Sent() []sentMessage {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]sentMessage(nil), n.sent...)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEventWatcher_Forward(t *testing.T) {
+	store := &fakeEventStore{Subscriptions: []models.EventSubscription{
+		{ChatID: 111, Namespace: "prod"},
+		{ChatID: 222, Namespace: "prod"},
+	}}
+	notifier := &lockedNotifier{}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	watcher := app.NewEventWatcher(&fakeEventSource{channels: map[string]chan kube.EventInfo{}}, store, notifier, time.Minute).
+		WithRateLimit(10 * time.Minute)
+	watcher.SetClock(func() time.Time { return now })
+	if err := watcher.Sync(); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	defer watcher.Stop()
+
+	backOff := kube.EventInfo{Type: "Warning", Reason: "BackOff", Object: "Pod/api-0", Message: "Back-off restarting failed container", Count: 5}
+	ctx := context.Background()
+
+	watcher.Forward(ctx, "prod", backOff)
+	sent := notifier.Sent()
+	if len(sent) != 2 || sent[0].ChatID != 111 || sent[1].ChatID != 222 {
+		t.Fatalf("expected event forwarded to both chats, got %+v", sent)
+	}
+	for _, want := range []string{"prod: BackOff Pod/api-0 (×5)", "Back-off restarting failed container"} {
+		if !strings.Contains(sent[0].Text, want) {
+			t.Errorf("message %q does not contain %q", sent[0].Text, want)
+		}
+	}
+
+	// та же причина в пределах ограничения подавляется, другая пересылается
+	now = now.Add(time.Minute)
+	watcher.Forward(ctx, "prod", backOff)
+	watcher.Forward(ctx, "prod", backOff)
+	watcher.Forward(ctx, "prod", kube.EventInfo{Type: "Warning", Reason: "FailedScheduling", Object: "Pod/api-1", Message: "0/3 nodes are available"})
+	if sent = notifier.Sent(); len(sent) != 4 || !strings.Contains(sent[2].Text, "FailedScheduling") {
+		t.Fatalf("expected only FailedScheduling to be forwarded, got %+v", sent)
+	}
+
+	// после ограничения сообщается о пропущенных событиях
+	now = now.Add(10 * time.Minute)
+	watcher.Forward(ctx, "prod", backOff)
+	if sent = notifier.Sent(); len(sent) != 6 || !strings.Contains(sent[4].Text, "пропущено событий BackOff: 2") {
+		t.Fatalf("expected BackOff with suppressed count, got %+v", sent)
+	}
+
+	// события namespace без подписчиков никуда не отправляются
+	watcher.Forward(ctx, "staging", kube.EventInfo{Type: "Warning", Reason: "BackOff", Object: "Pod/web-0"})
+	if sent = notifier.Sent(); len(sent) != 6 {
+		t.Fatalf("expected no messages for unsubscribed namespace, got %+v", sent[6:])
+	}
+}
+
+func TestEventWatcher_SyncPrunesLimits(t *testing.T) {
+	store := &fakeEventStore{Subscriptions: []models.EventSubscription{{ChatID: 111, Namespace: "prod"}}}
+	notifier := &lockedNotifier{}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	watcher := app.NewEventWatcher(&fakeEventSource{channels: map[string]chan kube.EventInfo{}}, store, notifier, time.Minute).
+		WithRateLimit(10 * time.Minute)
+	watcher.SetClock(func() time.Time { return now })
+	if err := watcher.Sync(); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	defer watcher.Stop()
+
+	backOff := kube.EventInfo{Type: "Warning", Reason: "BackOff", Object: "Pod/api-0"}
+	ctx := context.Background()
+	watcher.Forward(ctx, "prod", backOff)
+	watcher.Forward(ctx, "prod", backOff)
+
+	// после отписки ограничение namespace забывается, и при новой подписке событие пересылается сразу
+	store.Subscriptions = nil
+	if err := watcher.Sync(); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	store.Subscriptions = []models.EventSubscription{{ChatID: 111, Namespace: "prod"}}
+	if err := watcher.Sync(); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	watcher.Forward(ctx, "prod", backOff)
+	sent := notifier.Sent()
+	if len(sent) != 2 || strings.Contains(sent[1].Text, "пропущено") {
+		t.Fatalf("expected BackOff forwarded again without suppressed count, got %+v", sent)
+	}
+}
+
+func TestEventWatcher_Reasons(t *testing.T) {
+	store := &fakeEventStore{Subscriptions: []models.EventSubscription{{ChatID: 111, Namespace: "prod"}}}
+	notifier := &lockedNotifier{}
+	watcher := app.NewEventWatcher(&fakeEventSource{channels: map[string]chan kube.EventInfo{}}, store, notifier, time.Minute).
+		WithReasons("FailedScheduling", "Unhealthy")
+	if err := watcher.Sync(); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	defer watcher.Stop()
+
+	watcher.Forward(context.Background(), "prod", kube.EventInfo{Type: "Warning", Reason: "FailedMount", Object: "Pod/db-0"})
+	watcher.Forward(context.Background(), "prod", kube.EventInfo{Type: "Warning", Reason: "Unhealthy", Object: "Pod/api-0"})
+	if sent := notifier.Sent(); len(sent) != 1 || !strings.Contains(sent[0].Text, "Unhealthy") {
+		t.Fatalf("expected only Unhealthy to be forwarded, got %+v", sent)
+	}
+}
+
+func TestEventWatcher_Sync(t *testing.T) {
+	store := &fakeEventStore{Subscriptions: []models.EventSubscription{{ChatID: 111, Namespace: "prod"}}}
+	source := &fakeEventSource{channels: map[string]chan kube.EventInfo{}}
+	notifier := &lockedNotifier{}
+	watcher := app.NewEventWatcher(source, store, notifier, time.Minute)
+	defer watcher.Stop()
+
+	if err := watcher.Sync(); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	waitFor(t, "watch of prod", func() bool { return source.channel("prod") != nil })
+
+	source.channel("prod") <- kube.EventInfo{Type: "Warning", Reason: "Unhealthy", Object: "Pod/api-0", Message: "Readiness probe failed"}
+	waitFor(t, "forwarded event", func() bool { return len(notifier.Sent()) == 1 })
+	if sent := notifier.Sent(); sent[0].ChatID != 111 || !strings.Contains(sent[0].Text, "Readiness probe failed") {
+		t.Errorf("unexpected message %+v", sent[0])
+	}
+
+	// отписка от prod закрывает его watch, подписка на staging открывает новый
+	store.Subscriptions = []models.EventSubscription{{ChatID: 111, Namespace: "staging"}}
+	if err := watcher.Sync(); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	waitFor(t, "watch of staging", func() bool { return source.channel("staging") != nil })
+	waitFor(t, "prod watch stopped", func() bool { return source.channel("prod") == nil })
+}
@@ -0,0 +1,154 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chatops/internal/app"
+	"chatops/internal/db/models"
+	"chatops/internal/monitoring"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeIncidentStore struct {
+	nextID    uint
+	incidents map[uint]*models.IncidentHistory
+}
+
+func newFakeIncidentStore() *fakeIncidentStore {
+	return &fakeIncidentStore{incidents: make(map[uint]*models.IncidentHistory)}
+}
+
+func (s *fakeIncidentStore) GetActiveAlertIncidents() ([]models.IncidentHistory, error) {
+	var result []models.IncidentHistory
+	for _, incident := range s.incidents {
+		if incident.Status == models.IncidentResolved {
+			continue
+		}
+		copied := *incident
+		copied.Alerts = nil
+		for _, a := range incident.Alerts {
+			if a.ResolvedAt == nil {
+				copied.Alerts = append(copied.Alerts, a)
+			}
+		}
+		result = append(result, copied)
+	}
+	return result, nil
+}
+
+func (s *fakeIncidentStore) OpenAlertIncident(groupKey, title, severity, namespace, service string) (*models.IncidentHistory, error) {
+	s.nextID++
+	incident := &models.IncidentHistory{
+		ID:        s.nextID,
+		Status:    models.IncidentOpen,
+		Title:     title,
+		Severity:  severity,
+		Namespace: namespace,
+		Service:   service,
+		GroupKey:  groupKey,
+	}
+	s.incidents[incident.ID] = incident
+	copied := *incident
+	return &copied, nil
+}
+
+func (s *fakeIncidentStore) AddIncidentAlert(incidentID uint, fingerprint, alertName string, startsAt time.Time) error {
+	incident := s.incidents[incidentID]
+	incident.Alerts = append(incident.Alerts, models.IncidentAlert{
+		IncidentID:  incidentID,
+		Fingerprint: fingerprint,
+		AlertName:   alertName,
+		StartsAt:    startsAt,
+	})
+	return nil
+}
+
+func (s *fakeIncidentStore) ResolveIncidentAlert(incidentID uint, fingerprint string) error {
+	incident := s.incidents[incidentID]
+	now := time.Now()
+	for i := range incident.Alerts {
+		if incident.Alerts[i].Fingerprint == fingerprint && incident.Alerts[i].ResolvedAt == nil {
+			incident.Alerts[i].ResolvedAt = &now
+		}
+	}
+	return nil
+}
+
+func (s *fakeIncidentStore) TransitionIncident(id uint, to string, userID *uint, note string) (*models.IncidentHistory, error) {
+	incident := s.incidents[id]
+	incident.Status = to
+	return incident, nil
+}
+
+func activeFingerprints(incident *models.IncidentHistory) []string {
+	var fps []string
+	for _, a := range incident.Alerts {
+		if a.ResolvedAt == nil {
+			fps = append(fps, a.Fingerprint)
+		}
+	}
+	return fps
+}
+
+func TestIncidentCorrelator_Lifecycle(t *testing.T) {
+	store := newFakeIncidentStore()
+	correlator := app.NewIncidentCorrelator(store)
+	ctx := context.Background()
+
+	podA := monitoring.Alert{
+		Fingerprint: "fp-a",
+		Labels:      map[string]string{"alertname": "PodCrash", "namespace": "payments", "job": "api", "pod": "api-1", "severity": "critical"},
+		Annotations: map[string]string{"summary": "Pod is crash looping"},
+	}
+	podB := monitoring.Alert{
+		Fingerprint: "fp-b",
+		Labels:      map[string]string{"alertname": "PodCrash", "namespace": "payments", "job": "api", "pod": "api-2", "severity": "critical"},
+	}
+	other := monitoring.Alert{
+		Fingerprint: "fp-c",
+		Labels:      map[string]string{"alertname": "HighLatency", "namespace": "billing", "job": "gw"},
+	}
+
+	// Новая группа открывает инцидент
+	require.NoError(t, correlator.ProcessAlerts(ctx, []monitoring.Alert{podA}))
+	require.Len(t, store.incidents, 1)
+	incident := store.incidents[1]
+	assert.Equal(t, "PodCrash/payments/api", incident.GroupKey)
+	assert.Equal(t, "critical", incident.Severity)
+	assert.Equal(t, "PodCrash: Pod is crash looping (payments/api)", incident.Title)
+	assert.Equal(t, []string{"fp-a"}, activeFingerprints(incident))
+
+	// Алерт той же группы дописывается в существующий инцидент, другая группа - новый инцидент
+	require.NoError(t, correlator.ProcessAlerts(ctx, []monitoring.Alert{podA, podB, other}))
+	require.Len(t, store.incidents, 2)
+	assert.ElementsMatch(t, []string{"fp-a", "fp-b"}, activeFingerprints(incident))
+
+	// Повторный тик не дублирует алерты
+	require.NoError(t, correlator.ProcessAlerts(ctx, []monitoring.Alert{podA, podB, other}))
+	assert.Len(t, incident.Alerts, 2)
+
+	// Часть алертов погасла - инцидент остается открытым
+	require.NoError(t, correlator.ProcessAlerts(ctx, []monitoring.Alert{podB, other}))
+	assert.Equal(t, []string{"fp-b"}, activeFingerprints(incident))
+	assert.Equal(t, models.IncidentOpen, incident.Status)
+
+	// Все алерты группы погасли - инцидент закрывается автоматически
+	require.NoError(t, correlator.ProcessAlerts(ctx, []monitoring.Alert{other}))
+	assert.Equal(t, models.IncidentResolved, incident.Status)
+	assert.Empty(t, activeFingerprints(incident))
+	assert.Equal(t, models.IncidentOpen, store.incidents[2].Status)
+}
+
+func TestAlertFingerprint_FallbackIsStable(t *testing.T) {
+	a := monitoring.Alert{Labels: map[string]string{"alertname": "X", "pod": "p1"}}
+	b := monitoring.Alert{Labels: map[string]string{"pod": "p1", "alertname": "X"}}
+	c := monitoring.Alert{Labels: map[string]string{"alertname": "X", "pod": "p2"}}
+
+	assert.Equal(t, app.AlertFingerprint(a), app.AlertFingerprint(b))
+	assert.NotEqual(t, app.AlertFingerprint(a), app.AlertFingerprint(c))
+	assert.Equal(t, "given", app.AlertFingerprint(monitoring.Alert{Fingerprint: "given"}))
+}
@@ -0,0 +1,178 @@
+package app_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"chatops/internal/app"
+	"chatops/internal/db/models"
+	"chatops/internal/monitoring"
+)
+
+type fakeSLOStore struct {
+	SLOs     []models.SLO
+	Duty     map[string][]models.User
+	Searched []string
+}
+
+func (s *fakeSLOStore) GetDutyUsersByLabel(label string) ([]models.User, error) {
+	s.Searched = append(s.Searched, label)
+	return s.Duty[label], nil
+}
+
+func (s *fakeSLOStore) GetSLOs() ([]models.SLO, error) {
+	return s.SLOs, nil
+}
+
+type fakeSLOEvaluator struct {
+	Firing []monitoring.BurnRateRule
+}
+
+func (e *fakeSLOEvaluator) EvaluateSLO(ctx context.Context, slo monitoring.SLO, rules []monitoring.BurnRateRule) (*monitoring.SLOStatus, error) {
+	return &monitoring.SLOStatus{
+		SLO:             slo,
+		Attainment:      0.998,
+		BudgetRemaining: -1,
+		BurnRates:       map[time.Duration]float64{time.Hour: 20, 5 * time.Minute: 30, 24 * time.Hour: 4, 2 * time.Hour: 5},
+		Firing:          e.Firing,
+	}, nil
+}
+
+func TestSLOPoller_Check(t *testing.T) {
+	critical, warning := monitoring.DefaultBurnRateRules[0], monitoring.DefaultBurnRateRules[2]
+	store := &fakeSLOStore{
+		SLOs: []models.SLO{{ID: 1, Service: "api", Name: "availability", Kind: monitoring.SLOAvailability, Target: 0.999, WindowDays: 30}},
+		Duty: map[string][]models.User{"job=api": {{Login: "alice", TelegramChatID: 111}}},
+	}
+	evaluator := &fakeSLOEvaluator{Firing: []monitoring.BurnRateRule{warning}}
+	notifier := &fakeNotifier{}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	poller := app.NewSLOPoller(evaluator, store, notifier, time.Minute).WithRepeatInterval(time.Hour)
+	poller.SetClock(func() time.Time { return now })
+
+	check := func() {
+		t.Helper()
+		if err := poller.Check(context.Background()); err != nil {
+			t.Fatalf("Check returned error: %v", err)
+		}
+	}
+
+	check()
+	if len(notifier.Sent) != 1 || notifier.Sent[0].ChatID != 111 {
+		t.Fatalf("expected one warning to alice, got %+v", notifier.Sent)
+	}
+	if got := strings.Join(store.Searched, ","); got != "service=api,job=api" {
+		t.Errorf("duty users searched by %s", got)
+	}
+	for _, want := range []string{"УВЕДОМЛЕНИЕ ДЛЯ: @alice", "api/availability", "99.9% за 30d", "warning: 1d ×4.0, 2h ×5.0 (порог ×3.0)"} {
+		if !strings.Contains(notifier.Sent[0].Text, want) {
+			t.Errorf("warning %q does not contain %q", notifier.Sent[0].Text, want)
+		}
+	}
+
+	// Повтор той же важности - не раньше repeatInterval
+	now = now.Add(30 * time.Minute)
+	check()
+	if len(notifier.Sent) != 1 {
+		t.Fatalf("expected no repeat within repeat interval, got %d messages", len(notifier.Sent))
+	}
+
+	// Рост важности отправляется сразу
+	evaluator.Firing = []monitoring.BurnRateRule{critical, warning}
+	check()
+	if len(notifier.Sent) != 2 || !strings.Contains(notifier.Sent[1].Text, "critical: 1h ×20.0, 5m ×30.0 (порог ×14.4)") {
+		t.Fatalf("expected critical warning, got %+v", notifier.Sent)
+	}
+
+	now = now.Add(2 * time.Hour)
+	check()
+	if len(notifier.Sent) != 3 {
+		t.Fatalf("expected repeat after repeat interval, got %d messages", len(notifier.Sent))
+	}
+
+	evaluator.Firing = nil
+	check()
+	if len(notifier.Sent) != 4 || !strings.Contains(notifier.Sent[3].Text, "вернулся в норму") {
+		t.Fatalf("expected recovery message, got %+v", notifier.Sent)
+	}
+	check()
+	if len(notifier.Sent) != 4 {
+		t.Fatalf("expected single recovery message, got %d messages", len(notifier.Sent))
+	}
+}
+
+type fakeSLONotificationStore struct {
+	states map[uint]models.SLONotification
+}
+
+func (s *fakeSLONotificationStore) GetSLONotifications() ([]models.SLONotification, error) {
+	var result []models.SLONotification
+	for _, n := range s.states {
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+func (s *fakeSLONotificationStore) SaveSLONotification(n *models.SLONotification) error {
+	s.states[n.SLOID] = *n
+	return nil
+}
+
+func (s *fakeSLONotificationStore) DeleteSLONotification(sloID uint) error {
+	delete(s.states, sloID)
+	return nil
+}
+
+func TestSLOPoller_StateSurvivesRestart(t *testing.T) {
+	store := &fakeSLOStore{
+		SLOs: []models.SLO{{ID: 1, Service: "api", Name: "availability", Kind: monitoring.SLOAvailability, Target: 0.999, WindowDays: 30}},
+		Duty: map[string][]models.User{"job=api": {{Login: "alice", TelegramChatID: 111}}},
+	}
+	state := &fakeSLONotificationStore{states: make(map[uint]models.SLONotification)}
+	evaluator := &fakeSLOEvaluator{Firing: []monitoring.BurnRateRule{monitoring.DefaultBurnRateRules[2]}}
+	notifier := &fakeNotifier{}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	newPoller := func() *app.SLOPoller {
+		poller := app.NewSLOPoller(evaluator, store, notifier, time.Minute).WithNotificationStore(state)
+		poller.SetClock(func() time.Time { return now })
+		return poller
+	}
+
+	if err := newPoller().Check(context.Background()); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(notifier.Sent) != 1 || len(state.states) != 1 {
+		t.Fatalf("expected one warning and saved state, got %+v, %+v", notifier.Sent, state.states)
+	}
+
+	// Перезапуск: предупреждение не повторяется
+	now = now.Add(10 * time.Minute)
+	if err := newPoller().Check(context.Background()); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(notifier.Sent) != 1 {
+		t.Fatalf("expected no repeat after restart, got %d messages", len(notifier.Sent))
+	}
+
+	// Еще один перезапуск: о восстановлении сообщается, состояние удаляется
+	evaluator.Firing = nil
+	if err := newPoller().Check(context.Background()); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(notifier.Sent) != 2 || !strings.Contains(notifier.Sent[1].Text, "вернулся в норму") {
+		t.Fatalf("expected recovery message after restart, got %+v", notifier.Sent)
+	}
+	if len(state.states) != 0 {
+		t.Errorf("expected state to be deleted after recovery, got %+v", state.states)
+	}
+}
+
+func TestFormatRatio(t *testing.T) {
+	for ratio, want := range map[float64]string{0.999: "99.9%", 0.99951: "99.951%", 1: "100%", 0: "0%", -0.5: "-50%"} {
+		if got := app.FormatRatio(ratio); got != want {
+			t.Errorf("FormatRatio(%v) = %q, want %q", ratio, got, want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package app_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"chatops/internal/app"
+	"chatops/internal/monitoring"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const webhookFiring = `{
+  "version": "4",
+  "groupKey": "{}:{alertname=\"PodCrash\"}",
+  "status": "firing",
+  "receiver": "chatops",
+  "alerts": [
+    {"status": "firing", "fingerprint": "fp-1", "labels": {"alertname": "PodCrash", "pod": "api-1"}, "startsAt": "2024-01-01T12:00:00Z"},
+    {"status": "firing", "fingerprint": "fp-2", "labels": {"alertname": "PodCrash", "pod": "api-2"}, "startsAt": "2024-01-01T12:01:00Z"}
+  ]
+}`
+
+const webhookResolved = `{
+  "version": "4",
+  "groupKey": "{}:{alertname=\"PodCrash\"}",
+  "status": "resolved",
+  "alerts": [
+    {"status": "resolved", "fingerprint": "fp-1", "labels": {"alertname": "PodCrash", "pod": "api-1"}}
+  ]
+}`
+
+func postWebhook(handler http.Handler, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/alertmanager/webhook", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func fingerprints(alerts []monitoring.Alert) []string {
+	var fps []string
+	for _, a := range alerts {
+		fps = append(fps, a.Fingerprint)
+	}
+	return fps
+}
+
+func TestAlertWebhook_TracksActiveAlerts(t *testing.T) {
+	webhook := app.NewAlertWebhook("")
+	updates := 0
+	webhook.OnUpdate(func() { updates++ })
+
+	require.Equal(t, http.StatusOK, postWebhook(webhook, webhookFiring, "").Code)
+	alerts, err := webhook.GetActiveAlerts(context.Background(), monitoring.AlertFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fp-1", "fp-2"}, fingerprints(alerts))
+	assert.Equal(t, monitoring.AlertStateActive, alerts[0].Status.State)
+	assert.Equal(t, "api-1", alerts[0].Labels["pod"])
+
+	filtered, err := webhook.GetActiveAlerts(context.Background(), monitoring.AlertFilter{
+		Matchers: []monitoring.Matcher{{Name: "pod", Value: "api-2", IsEqual: true}},
+		Receiver: "chat.*",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fp-2"}, fingerprints(filtered))
+
+	require.Equal(t, http.StatusOK, postWebhook(webhook, webhookResolved, "").Code)
+	alerts, _ = webhook.GetActiveAlerts(context.Background(), monitoring.AlertFilter{})
+	assert.Equal(t, []string{"fp-2"}, fingerprints(alerts))
+	assert.Equal(t, 2, updates)
+}
+
+func TestAlertWebhook_RejectsInvalidRequests(t *testing.T) {
+	webhook := app.NewAlertWebhook("secret")
+
+	assert.Equal(t, http.StatusUnauthorized, postWebhook(webhook, webhookFiring, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, postWebhook(webhook, webhookFiring, "wrong").Code)
+	assert.Equal(t, http.StatusBadRequest, postWebhook(webhook, "{not json", "secret").Code)
+	assert.Equal(t, http.StatusBadRequest, postWebhook(webhook, `{"version": "3", "alerts": []}`, "secret").Code)
+
+	rec := httptest.NewRecorder()
+	webhook.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/alertmanager/webhook", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	alerts, _ := webhook.GetActiveAlerts(context.Background(), monitoring.AlertFilter{})
+	assert.Empty(t, alerts)
+	assert.Equal(t, http.StatusOK, postWebhook(webhook, webhookFiring, "secret").Code)
+}
+
+func TestAlertWebhook_ResolvedBeforeDelivery(t *testing.T) {
+	webhook := app.NewAlertWebhook("")
+
+	// Алерт погас до того, как поллер его забрал: он отдается один раз
+	require.Equal(t, http.StatusOK, postWebhook(webhook, webhookFiring, "").Code)
+	require.Equal(t, http.StatusOK, postWebhook(webhook, webhookResolved, "").Code)
+	alerts, err := webhook.GetActiveAlerts(context.Background(), monitoring.AlertFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fp-1", "fp-2"}, fingerprints(alerts))
+
+	alerts, _ = webhook.GetActiveAlerts(context.Background(), monitoring.AlertFilter{})
+	assert.Equal(t, []string{"fp-2"}, fingerprints(alerts))
+}
+
+func TestAlertWebhook_ExpiresByEndsAt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 10, 0, 0, time.UTC)
+	webhook := app.NewAlertWebhook("")
+	webhook.SetClock(func() time.Time { return now })
+
+	webhook.Apply(monitoring.WebhookMessage{Alerts: []monitoring.WebhookAlert{
+		{Status: "firing", Fingerprint: "fp-1", Labels: map[string]string{"alertname": "PodCrash"}, EndsAt: now.Add(5 * time.Minute)},
+		{Status: "firing", Fingerprint: "fp-2", Labels: map[string]string{"alertname": "DiskFull"}},
+	}})
+	alerts, _ := webhook.GetActiveAlerts(context.Background(), monitoring.AlertFilter{})
+	assert.Equal(t, []string{"fp-1", "fp-2"}, fingerprints(alerts))
+
+	// Уведомление о погасании не пришло, но EndsAt прошел
+	now = now.Add(10 * time.Minute)
+	alerts, _ = webhook.GetActiveAlerts(context.Background(), monitoring.AlertFilter{})
+	assert.Equal(t, []string{"fp-2"}, fingerprints(alerts))
+}
+
+func TestAlertWebhook_Reconcile(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	source := &mockMonitoringClient{Alerts: []monitoring.Alert{
+		{Fingerprint: "fp-2", Labels: map[string]string{"alertname": "PodCrash", "pod": "api-2"}},
+		{Fingerprint: "fp-3", Labels: map[string]string{"alertname": "DiskFull"}},
+	}}
+	webhook := app.NewAlertWebhook("").WithReconciliation(source, time.Minute)
+	webhook.SetClock(func() time.Time { return now })
+
+	require.Equal(t, http.StatusOK, postWebhook(webhook, webhookFiring, "").Code)
+	alerts, _ := webhook.GetActiveAlerts(context.Background(), monitoring.AlertFilter{})
+	assert.Equal(t, []string{"fp-1", "fp-2"}, fingerprints(alerts))
+
+	// Уведомление о погасании fp-1 потерялось, fp-3 сработал без уведомления
+	now = now.Add(time.Minute)
+	require.NoError(t, webhook.Reconcile(context.Background()))
+	alerts, _ = webhook.GetActiveAlerts(context.Background(), monitoring.AlertFilter{})
+	assert.Equal(t, []string{"fp-2", "fp-3"}, fingerprints(alerts))
+
+	// Алерт из уведомления, пришедшего после запроса к Alertmanager, сохраняется
+	fetchedAt := now
+	webhook.SetClock(func() time.Time { return fetchedAt.Add(time.Second) })
+	webhook.Apply(monitoring.WebhookMessage{Alerts: []monitoring.WebhookAlert{
+		{Status: "firing", Fingerprint: "fp-4", Labels: map[string]string{"alertname": "Late"}},
+	}})
+	webhook.Seed(nil, fetchedAt)
+	alerts, _ = webhook.GetActiveAlerts(context.Background(), monitoring.AlertFilter{})
+	assert.Equal(t, []string{"fp-4"}, fingerprints(alerts))
+}
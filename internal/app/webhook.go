@@ -0,0 +1,264 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"chatops/internal/monitoring"
+)
+
+// maxWebhookBody ограничивает размер тела webhook-запроса
+const maxWebhookBody = 4 << 20
+
+// AlertWebhook принимает webhook-уведомления Alertmanager и хранит текущий набор
+// активных алертов. Реализует MonitoringClient, поэтому подключается к AlertPoller
+// вместо опроса Alertmanager: поллер периодически прогоняет обработчики по известным
+// алертам, а каждое уведомление запускает внеочередную обработку через OnUpdate.
+//
+// Уведомление о погасшем алерте может не прийти (потеря доставки, send_resolved: false,
+// тишина), поэтому алерт с прошедшим EndsAt считается погасшим, а набор периодически
+// сверяется с API Alertmanager (WithReconciliation). Алерт, погасший до того, как его
+// забрал поллер, отдается один раз, чтобы короткие алерты не терялись.
+type AlertWebhook struct {
+	token    string
+	mu       sync.Mutex
+	alerts   map[string]*webhookAlert
+	onUpdate func()
+	source   MonitoringClient
+	interval time.Duration
+	now      func() time.Time
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// webhookAlert - алерт из набора ресивера
+type webhookAlert struct {
+	alert     monitoring.Alert
+	updatedAt time.Time // время последнего уведомления или сверки
+	delivered bool      // алерт уже отдан через GetActiveAlerts
+	resolved  bool      // алерт погас до того, как был отдан, и будет отдан один раз
+}
+
+// NewAlertWebhook создает ресивер; непустой token требует заголовок Authorization: Bearer <token>
+func NewAlertWebhook(token string) *AlertWebhook {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &AlertWebhook{
+		token:  token,
+		alerts: make(map[string]*webhookAlert),
+		now:    time.Now,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// OnUpdate задает функцию, вызываемую после применения каждого уведомления
+func (w *AlertWebhook) OnUpdate(fn func()) {
+	w.onUpdate = fn
+}
+
+// WithReconciliation включает сверку набора активных алертов с source (API Alertmanager)
+// при запуске и далее раз в interval: алерты, которых нет в ответе, считаются погасшими
+func (w *AlertWebhook) WithReconciliation(source MonitoringClient, interval time.Duration) *AlertWebhook {
+	w.source = source
+	w.interval = interval
+	return w
+}
+
+// SetClock подменяет источник времени (используется в тестах)
+func (w *AlertWebhook) SetClock(now func() time.Time) {
+	w.now = now
+}
+
+// Start сверяет набор алертов с Alertmanager, чтобы ранее сработавшие алерты не считались
+// погасшими, и запускает периодическую сверку. Без WithReconciliation ничего не делает.
+func (w *AlertWebhook) Start() {
+	if w.source == nil {
+		return
+	}
+	if err := w.Reconcile(w.ctx); err != nil {
+		log.Printf("Failed to reconcile webhook alerts with alertmanager: %v", err)
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := w.Reconcile(w.ctx); err != nil {
+					log.Printf("Failed to reconcile webhook alerts with alertmanager: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (w *AlertWebhook) Stop() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+// Reconcile заменяет набор активных алертов ответом Alertmanager. Алерты, обновленные
+// уведомлением после начала запроса, сохраняются.
+func (w *AlertWebhook) Reconcile(ctx context.Context) error {
+	fetchedAt := w.now()
+	reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	alerts, err := w.source.GetActiveAlerts(reqCtx, monitoring.AlertFilter{})
+	if err != nil {
+		return err
+	}
+	w.Seed(alerts, fetchedAt)
+	return nil
+}
+
+// Seed заменяет набор активных алертов снимком, полученным из API Alertmanager в момент at
+func (w *AlertWebhook) Seed(alerts []monitoring.Alert, at time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	active := make(map[string]bool, len(alerts))
+	for _, alert := range alerts {
+		fingerprint := AlertFingerprint(alert)
+		active[fingerprint] = true
+		if e, ok := w.alerts[fingerprint]; ok && e.updatedAt.After(at) {
+			continue
+		}
+		w.set(fingerprint, alert, at)
+	}
+	for fingerprint, e := range w.alerts {
+		if !active[fingerprint] && !e.updatedAt.After(at) {
+			w.resolve(fingerprint)
+		}
+	}
+}
+
+// GetActiveAlerts возвращает подходящие под фильтр алерты, которые по последним уведомлениям все еще горят
+func (w *AlertWebhook) GetActiveAlerts(ctx context.Context, filter monitoring.AlertFilter) ([]monitoring.Alert, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	keys := make([]string, 0, len(w.alerts))
+	for key, e := range w.alerts {
+		// Алерт, о погасании которого не сообщили, гаснет по EndsAt
+		if !e.resolved && !e.alert.EndsAt.IsZero() && e.alert.EndsAt.Before(now) {
+			w.resolve(key)
+			if _, ok := w.alerts[key]; !ok {
+				continue
+			}
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	alerts := make([]monitoring.Alert, 0, len(keys))
+	for _, key := range keys {
+		e := w.alerts[key]
+		if !filter.Matches(e.alert) {
+			continue
+		}
+		alerts = append(alerts, e.alert)
+		e.delivered = true
+		if e.resolved {
+			delete(w.alerts, key)
+		}
+	}
+	return alerts, nil
+}
+
+// Apply обновляет набор активных алертов по webhook-уведомлению
+func (w *AlertWebhook) Apply(msg monitoring.WebhookMessage) {
+	w.mu.Lock()
+	now := w.now()
+	for _, a := range msg.Alerts {
+		alert := a.ToAlert()
+		fingerprint := AlertFingerprint(alert)
+		if a.Status == monitoring.WebhookStatusResolved {
+			w.resolve(fingerprint)
+			continue
+		}
+		if msg.Receiver != "" {
+			alert.Receivers = []monitoring.AlertReceiver{{Name: msg.Receiver}}
+		}
+		w.set(fingerprint, alert, now)
+	}
+	w.mu.Unlock()
+
+	if w.onUpdate != nil {
+		w.onUpdate()
+	}
+}
+
+// set сохраняет горящий алерт, сохраняя отметку о том, что он уже был отдан
+func (w *AlertWebhook) set(fingerprint string, alert monitoring.Alert, at time.Time) {
+	alert.Fingerprint = fingerprint
+	e, ok := w.alerts[fingerprint]
+	if !ok {
+		e = &webhookAlert{}
+		w.alerts[fingerprint] = e
+	}
+	e.alert = alert
+	e.updatedAt = at
+	e.resolved = false
+}
+
+// resolve удаляет погасший алерт; еще не отданный алерт остается до следующего GetActiveAlerts
+func (w *AlertWebhook) resolve(fingerprint string) {
+	e, ok := w.alerts[fingerprint]
+	if !ok {
+		return
+	}
+	if e.delivered {
+		delete(w.alerts, fingerprint)
+		return
+	}
+	e.resolved = true
+}
+
+func (w *AlertWebhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !w.authorized(r) {
+		log.Printf("Rejected alertmanager webhook from %s: invalid token", r.RemoteAddr)
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var msg monitoring.WebhookMessage
+	if err := json.NewDecoder(http.MaxBytesReader(rw, r.Body, maxWebhookBody)).Decode(&msg); err != nil {
+		http.Error(rw, "invalid payload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if msg.Version != "4" {
+		http.Error(rw, "unsupported webhook version "+msg.Version, http.StatusBadRequest)
+		return
+	}
+	if msg.TruncatedAlerts > 0 {
+		log.Printf("Alertmanager webhook %s truncated %d alerts", msg.GroupKey, msg.TruncatedAlerts)
+	}
+
+	log.Printf("Received alertmanager webhook %s (%s, %d alerts)", msg.GroupKey, msg.Status, len(msg.Alerts))
+	w.Apply(msg)
+	rw.WriteHeader(http.StatusOK)
+}
+
+func (w *AlertWebhook) authorized(r *http.Request) bool {
+	if w.token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(w.token)) == 1
+}
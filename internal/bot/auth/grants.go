@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+const scopeNamespacePrefix = "ns="
+
+// workloadScopes - префиксы прав на отдельную нагрузку и виды нагрузок (как в kube.Kind*), к которым они относятся
+var workloadScopes = map[string]string{
+	"deploy=": "Deployment",
+	"sts=":    "StatefulSet",
+	"ds=":     "DaemonSet",
+}
+
+// ValidateScope проверяет формат области доступа: ns=<namespace>, ns=* или
+// deploy=, sts=, ds=<namespace>/<name> для Deployment, StatefulSet и DaemonSet
+func ValidateScope(scope string) error {
+	if strings.HasPrefix(scope, scopeNamespacePrefix) {
+		if strings.TrimPrefix(scope, scopeNamespacePrefix) == "" {
+			return fmt.Errorf("пустой namespace в %q", scope)
+		}
+		return nil
+	}
+	for prefix := range workloadScopes {
+		if !strings.HasPrefix(scope, prefix) {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(scope, prefix), "/", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("ожидается %s<namespace>/<name>, получено %q", prefix, scope)
+		}
+		return nil
+	}
+	return fmt.Errorf("неизвестный формат %q, ожидается ns=<namespace> или deploy=, sts=, ds=<namespace>/<name>", scope)
+}
+
+// ScopeAllows проверяет, разрешают ли области доступа операцию над ресурсом вида kind.
+// Право на нагрузку действует только для ресурса того же вида: deploy= не дает доступа
+// к StatefulSet с тем же именем. Пустое name означает операцию над всем namespace,
+// для нее требуется ns=<namespace>.
+func ScopeAllows(scopes []string, kind, namespace, name string) bool {
+	for _, scope := range scopes {
+		if strings.HasPrefix(scope, scopeNamespacePrefix) {
+			ns := strings.TrimPrefix(scope, scopeNamespacePrefix)
+			if ns == "*" || ns == namespace {
+				return true
+			}
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		for prefix, scopeKind := range workloadScopes {
+			if scopeKind == kind && strings.HasPrefix(scope, prefix) && strings.TrimPrefix(scope, prefix) == namespace+"/"+name {
+				return true
+			}
+		}
+	}
+	return false
+}
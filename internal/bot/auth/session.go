@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"chatops/internal/db/models"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+const sessionKey = "session"
+
+// Session описывает авторизованного пользователя Telegram
+type Session struct {
+	TelegramID int64
+	UserID     uint
+	Login      string
+	Role       string
+	ExpiresAt  time.Time
+}
+
+// Expired сообщает, истекла ли сессия к моменту now
+func (s *Session) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// SessionStore хранит сессии, привязанные к Telegram ID отправителя
+type SessionStore struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	sessions map[int64]*Session
+	now      func() time.Time
+}
+
+// NewSessionStore создает хранилище сессий с заданным временем жизни
+func NewSessionStore(ttl time.Duration) *SessionStore {
+	return &SessionStore{
+		ttl:      ttl,
+		sessions: make(map[int64]*Session),
+		now:      time.Now,
+	}
+}
+
+// SetClock подменяет источник времени (используется в тестах)
+func (s *SessionStore) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// Start открывает новую сессию для пользователя Telegram
+func (s *SessionStore) Start(telegramID int64, user *models.User) *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	role := user.Role
+	if role == "" {
+		role = models.RoleViewer
+	}
+	session := &Session{
+		TelegramID: telegramID,
+		UserID:     user.ID,
+		Login:      user.Login,
+		Role:       role,
+		ExpiresAt:  s.now().Add(s.ttl),
+	}
+	s.sessions[telegramID] = session
+	return session
+}
+
+// Get возвращает активную сессию; истекшие сессии удаляются
+func (s *SessionStore) Get(telegramID int64) (*Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[telegramID]
+	if !ok {
+		return nil, false
+	}
+	if session.Expired(s.now()) {
+		delete(s.sessions, telegramID)
+		return nil, false
+	}
+	return session, true
+}
+
+// Delete завершает сессию пользователя
+func (s *SessionStore) Delete(telegramID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.sessions[telegramID]
+	delete(s.sessions, telegramID)
+	return ok
+}
+
+// DeleteByUserID завершает все сессии указанного пользователя БД
+func (s *SessionStore) DeleteByUserID(userID uint) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for telegramID, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, telegramID)
+			removed++
+		}
+	}
+	return removed
+}
+
+var roleRank = map[string]int{
+	models.RoleViewer:   1,
+	models.RoleOperator: 2,
+	models.RoleAdmin:    3,
+}
+
+// ValidRole проверяет, что роль известна
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// HasRole проверяет, что роль пользователя не ниже требуемой
+func HasRole(role, required string) bool {
+	if required == "" {
+		return true
+	}
+	have, ok := roleRank[role]
+	if !ok {
+		return false
+	}
+	return have >= roleRank[required]
+}
+
+// WithSession сохраняет сессию в контексте обработчика
+func WithSession(c telebot.Context, session *Session) {
+	c.Set(sessionKey, session)
+}
+
+// SessionFromContext возвращает сессию, сохраненную WithSession
+func SessionFromContext(c telebot.Context) *Session {
+	session, _ := c.Get(sessionKey).(*Session)
+	return session
+}
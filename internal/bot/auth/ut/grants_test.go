@@ -0,0 +1,53 @@
+package auth_test
+
+import (
+	"testing"
+
+	"chatops/internal/bot/auth"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopeAllows(t *testing.T) {
+	tests := []struct {
+		name      string
+		scopes    []string
+		kind      string
+		namespace string
+		resource  string
+		expected  bool
+	}{
+		{"Право на namespace", []string{"ns=payments"}, "Deployment", "payments", "api", true},
+		{"Право на namespace для списка подов", []string{"ns=payments"}, "", "payments", "", true},
+		{"Право на другой namespace", []string{"ns=payments"}, "Deployment", "prod", "billing", false},
+		{"Право на deployment", []string{"deploy=payments/api"}, "Deployment", "payments", "api", true},
+		{"Право на другой deployment", []string{"deploy=payments/api"}, "Deployment", "payments", "worker", false},
+		{"Deployment не дает доступ ко всему namespace", []string{"deploy=payments/api"}, "", "payments", "", false},
+		{"Право на deployment не дает доступ к StatefulSet", []string{"deploy=payments/api"}, "StatefulSet", "payments", "api", false},
+		{"Право на DaemonSet не дает доступ к Deployment", []string{"ds=payments/api"}, "Deployment", "payments", "api", false},
+		{"Право на StatefulSet", []string{"sts=payments/db"}, "StatefulSet", "payments", "db", true},
+		{"Право на DaemonSet", []string{"ds=monitoring/agent"}, "DaemonSet", "monitoring", "agent", true},
+		{"Право на namespace для любого вида", []string{"ns=payments"}, "DaemonSet", "payments", "agent", true},
+		{"Все namespace", []string{"ns=*"}, "Deployment", "prod", "billing", true},
+		{"Нет прав", nil, "Deployment", "prod", "billing", false},
+		{"Метка дежурства не является правом", []string{"team=payments"}, "Deployment", "payments", "api", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, auth.ScopeAllows(tt.scopes, tt.kind, tt.namespace, tt.resource))
+		})
+	}
+}
+
+func TestValidateScope(t *testing.T) {
+	valid := []string{"ns=payments", "ns=*", "deploy=payments/api", "sts=payments/db", "ds=monitoring/agent"}
+	for _, scope := range valid {
+		assert.NoError(t, auth.ValidateScope(scope), scope)
+	}
+
+	invalid := []string{"", "ns=", "deploy=payments", "deploy=/api", "sts=payments", "team=payments"}
+	for _, scope := range invalid {
+		assert.Error(t, auth.ValidateScope(scope), scope)
+	}
+}
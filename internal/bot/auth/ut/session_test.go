@@ -0,0 +1,80 @@
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"chatops/internal/bot/auth"
+	"chatops/internal/db/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionStore_PerUser(t *testing.T) {
+	store := auth.NewSessionStore(time.Hour)
+
+	store.Start(1, &models.User{ID: 10, Login: "alice", Role: models.RoleOperator})
+
+	session, ok := store.Get(1)
+	require.True(t, ok)
+	assert.Equal(t, "alice", session.Login)
+	assert.Equal(t, models.RoleOperator, session.Role)
+
+	_, ok = store.Get(2)
+	assert.False(t, ok, "другой пользователь Telegram не должен получить чужую сессию")
+}
+
+func TestSessionStore_Expiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := auth.NewSessionStore(30 * time.Minute)
+	store.SetClock(func() time.Time { return now })
+
+	store.Start(1, &models.User{ID: 10, Login: "alice"})
+
+	now = now.Add(29 * time.Minute)
+	_, ok := store.Get(1)
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = store.Get(1)
+	assert.False(t, ok)
+}
+
+func TestSessionStore_Delete(t *testing.T) {
+	store := auth.NewSessionStore(time.Hour)
+	store.Start(1, &models.User{ID: 10, Login: "alice"})
+
+	assert.True(t, store.Delete(1))
+	assert.False(t, store.Delete(1))
+	_, ok := store.Get(1)
+	assert.False(t, ok)
+}
+
+func TestSessionStore_DefaultRole(t *testing.T) {
+	store := auth.NewSessionStore(time.Hour)
+	session := store.Start(1, &models.User{ID: 10, Login: "legacy"})
+	assert.Equal(t, models.RoleViewer, session.Role)
+}
+
+func TestHasRole(t *testing.T) {
+	tests := []struct {
+		role     string
+		required string
+		expected bool
+	}{
+		{models.RoleViewer, models.RoleViewer, true},
+		{models.RoleViewer, models.RoleOperator, false},
+		{models.RoleOperator, models.RoleOperator, true},
+		{models.RoleOperator, models.RoleAdmin, false},
+		{models.RoleAdmin, models.RoleOperator, true},
+		{"unknown", models.RoleViewer, false},
+		{models.RoleViewer, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role+"->"+tt.required, func(t *testing.T) {
+			assert.Equal(t, tt.expected, auth.HasRole(tt.role, tt.required))
+		})
+	}
+}
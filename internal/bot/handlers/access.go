@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+
+	"chatops/internal/bot/auth"
+	"chatops/internal/db/models"
+	"chatops/internal/db/repository"
+	"chatops/internal/kube"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+// authorizeResource проверяет право текущего пользователя на операцию action над ресурсом
+// вида kind в namespace/name; пустой kind - ресурс без вида (весь namespace, тишина).
+// При отказе отправляет сообщение и помечает операцию в журнале как отклоненную.
+func authorizeResource(c telebot.Context, action, kind, namespace, name string) bool {
+	target := namespace
+	if name != "" {
+		target = namespace + "/" + name
+		if kind != "" {
+			target = kind + "/" + target
+		}
+	}
+	resource := name
+	if kind != "" && name != "" {
+		resource = target
+	}
+	setAuditTarget(c, namespace, resource)
+
+	session := auth.SessionFromContext(c)
+	if session == nil {
+		c.Send("Вы не авторизованы. Введите /start для авторизации.")
+		return false
+	}
+	if session.Role == models.RoleAdmin {
+		return true
+	}
+
+	scopes, err := repository.GetUserGrants(session.UserID)
+	if err != nil {
+		log.Printf("Error loading grants for user %s: %v", session.Login, err)
+		c.Send(fmt.Sprintf("Не удалось проверить права доступа к %s", target))
+		return false
+	}
+	if auth.ScopeAllows(scopes, kind, namespace, name) {
+		return true
+	}
+
+	log.Printf("Отказано в доступе: %s пытался выполнить %s для %s", session.Login, action, target)
+	markAuditDenied(c, fmt.Sprintf("нет права на %s", target))
+	c.Send(fmt.Sprintf("⛔ У вас нет прав на %s для %s. Обратитесь к администратору.", action, target))
+	return false
+}
+
+// authorizeWorkload проверяет право на операцию action над рабочей нагрузкой
+func authorizeWorkload(c telebot.Context, action string, workload kube.Workload) bool {
+	return authorizeResource(c, action, workload.Kind, workload.Namespace, workload.Name)
+}
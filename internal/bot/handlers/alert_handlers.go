@@ -12,24 +12,54 @@ import (
 )
 
 func AlertsHandler(c telebot.Context) error {
+	filter, err := parseAlertsFilter(strings.Fields(c.Text())[1:])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка: %v\nИспользование: /alerts [матчеры] [active|silenced|inhibited]", err))
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 
-	message, err := GenerateAlertsMessage(ctx, GlobalMonitorClient)
+	message, err := GenerateAlertsMessage(ctx, GlobalMonitorClient, filter)
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return c.Send("Превышено время ожидания запроса (timeout)")
+			return sendError(c, "Превышено время ожидания запроса (timeout)")
 		}
+		markAuditError(c, err.Error())
 		return c.Send(fmt.Sprintf("❌ *Произошла ошибка:*\n`%v`", escapeMarkdown(err.Error())), telebot.ModeMarkdownV2)
 	}
 
 	return c.Send(message, telebot.ModeMarkdownV2)
 }
 
+// parseAlertsFilter разбирает аргументы /alerts: матчеры Alertmanager
+// и ключевые слова active, silenced, inhibited для выбора состояния
+func parseAlertsFilter(args []string) (monitoring.AlertFilter, error) {
+	var filter monitoring.AlertFilter
+	yes, no := true, false
+	for _, arg := range args {
+		switch arg {
+		case "active":
+			filter.Active, filter.Silenced, filter.Inhibited = &yes, &no, &no
+		case "silenced":
+			filter.Active, filter.Silenced = &no, &yes
+		case "inhibited":
+			filter.Active, filter.Inhibited = &no, &yes
+		default:
+			matchers, err := monitoring.ParseMatchers(arg)
+			if err != nil {
+				return filter, err
+			}
+			filter.Matchers = append(filter.Matchers, matchers...)
+		}
+	}
+	return filter, nil
+}
+
 // GenerateAlertsMessage получает алерты и возвращает отформатированное строковое сообщение.
 // Эта функция содержит основную логику и не зависит от telebot.
-func GenerateAlertsMessage(ctx context.Context, client *monitoring.Client) (string, error) {
-	alerts, err := client.GetActiveAlerts(ctx)
+func GenerateAlertsMessage(ctx context.Context, client *monitoring.Client, filter monitoring.AlertFilter) (string, error) {
+	alerts, err := client.GetActiveAlerts(ctx, filter)
 	if err != nil {
 		return "", fmt.Errorf("ошибка получения активных алертов: %w", err)
 	}
@@ -39,8 +69,19 @@ func GenerateAlertsMessage(ctx context.Context, client *monitoring.Client) (stri
 
 	if len(alerts) > 0 {
 		sb.WriteString("🔥 *Активные алерты:*\n")
+		now := time.Now()
 		for _, alert := range alerts {
-			sb.WriteString(fmt.Sprintf("> *%s*\n", escapeMarkdown(alert.Labels["alertname"])))
+			sb.WriteString(fmt.Sprintf("> *%s*%s\n", escapeMarkdown(alert.Labels["alertname"]), alertMarkers(alert)))
+			state := alert.Status.State
+			if state == "" {
+				state = "unknown"
+			}
+			if alert.StartsAt.IsZero() {
+				sb.WriteString(fmt.Sprintf("  State: `%s`\n", escapeMarkdown(state)))
+			} else {
+				sb.WriteString(fmt.Sprintf("  State: `%s`, горит `%s`\n", escapeMarkdown(state),
+					escapeMarkdown(now.Sub(alert.StartsAt).Round(time.Second).String())))
+			}
 			if desc, ok := alert.Annotations["description"]; ok {
 				sb.WriteString(fmt.Sprintf("  _%s_\n", escapeMarkdown(desc)))
 			}
@@ -55,3 +96,15 @@ func GenerateAlertsMessage(ctx context.Context, client *monitoring.Client) (stri
 
 	return sb.String(), nil
 }
+
+// alertMarkers возвращает отметки о подавлении алерта тишиной или правилом inhibit
+func alertMarkers(alert monitoring.Alert) string {
+	var markers string
+	if alert.Silenced() {
+		markers += fmt.Sprintf(" 🔕 silenced \\(%s\\)", escapeMarkdown(strings.Join(alert.Status.SilencedBy, ", ")))
+	}
+	if alert.Inhibited() {
+		markers += " 🚫 inhibited"
+	}
+	return markers
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"chatops/internal/bot/auth"
+	"chatops/internal/db/models"
+	"chatops/internal/db/repository"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+const operationKey = "operation"
+
+// sensitiveArgs задает, сколько первых аргументов команды можно сохранить в журнал,
+// остальные аргументы таких команд содержат пароли и не записываются
+var sensitiveArgs = map[string]int{
+	"/user_add":    1,
+	"/user_passwd": 1,
+}
+
+// IsSensitiveCommand сообщает, содержит ли команда секреты в аргументах
+func IsSensitiveCommand(cmd string) bool {
+	_, ok := sensitiveArgs[cmd]
+	return ok
+}
+
+// StartAudit создает запись журнала для команды из текущего сообщения
+// и сохраняет ее в контексте обработчика
+func StartAudit(c telebot.Context, session *auth.Session) *models.Operation {
+	fields := strings.Fields(c.Text())
+	cmd := ""
+	var args []string
+	if len(fields) > 0 {
+		cmd = fields[0]
+		args = fields[1:]
+	}
+	if keep, ok := sensitiveArgs[cmd]; ok && len(args) > keep {
+		args = append(append([]string{}, args[:keep]...), "***")
+	}
+	return startOperation(c, session, cmd, args)
+}
+
+// startOperation создает запись журнала для команды cmd; используется и для действий
+// inline-кнопок, которые записываются как эквивалентная команда
+func startOperation(c telebot.Context, session *auth.Session, cmd string, args []string) *models.Operation {
+	operation := &models.Operation{
+		Time:         time.Now(),
+		Command:      cmd,
+		Args:         strings.Join(args, " "),
+		Confirmation: models.ConfirmationAwaiting,
+		Status:       models.OperationPending,
+	}
+	if sender := c.Sender(); sender != nil {
+		operation.TelegramID = sender.ID
+	}
+	if chat := c.Chat(); chat != nil {
+		operation.ChatID = chat.ID
+	}
+	login := "unknown"
+	if session != nil {
+		userID := session.UserID
+		operation.UserID = &userID
+		login = session.Login
+	}
+	operation.Text = strings.TrimSpace(fmt.Sprintf("%s: %s %s", login, cmd, operation.Args))
+
+	if err := repository.SaveOperation(operation); err != nil {
+		log.Printf("Error saving operation %q: %v", operation.Text, err)
+	}
+	c.Set(operationKey, operation)
+	return operation
+}
+
+// FinishAudit фиксирует результат подтверждения и выполнения команды
+func FinishAudit(c telebot.Context, confirmation string, err error) {
+	operation, _ := c.Get(operationKey).(*models.Operation)
+	if operation == nil {
+		return
+	}
+
+	now := time.Now()
+	operation.FinishedAt = &now
+	operation.Confirmation = confirmation
+	switch {
+	case operation.Status == models.OperationDenied:
+	case confirmation == models.ConfirmationRejected:
+		operation.Status = models.OperationCancelled
+	case err != nil:
+		operation.Status = models.OperationError
+		operation.Error = err.Error()
+	case operation.Status == models.OperationError:
+	default:
+		operation.Status = models.OperationSuccess
+	}
+
+	if err := repository.SaveOperation(operation); err != nil {
+		log.Printf("Error saving operation %q: %v", operation.Text, err)
+	}
+}
+
+// setAuditTarget запоминает namespace и ресурс, над которыми выполняется команда
+func setAuditTarget(c telebot.Context, namespace, resource string) {
+	if operation, _ := c.Get(operationKey).(*models.Operation); operation != nil {
+		operation.Namespace = namespace
+		operation.Resource = resource
+	}
+}
+
+// sendError отправляет пользователю сообщение об ошибке и помечает текущую операцию как
+// завершившуюся ошибкой: обработчики сообщают об ошибках в чат и возвращают nil
+func sendError(c telebot.Context, text string) error {
+	markAuditError(c, text)
+	return c.Send(text)
+}
+
+// markAuditError помечает текущую операцию как завершившуюся ошибкой
+func markAuditError(c telebot.Context, reason string) {
+	if operation, _ := c.Get(operationKey).(*models.Operation); operation != nil {
+		operation.Status = models.OperationError
+		operation.Error = reason
+	}
+}
+
+// markAuditDenied помечает текущую операцию как отклоненную из-за нехватки прав
+func markAuditDenied(c telebot.Context, reason string) {
+	if operation, _ := c.Get(operationKey).(*models.Operation); operation != nil {
+		operation.Status = models.OperationDenied
+		operation.Error = reason
+	}
+}
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"chatops/internal/chart"
+	"chatops/internal/db/models"
+	"chatops/internal/db/repository"
+	"chatops/internal/monitoring"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+const (
+	maxPanelSamples  = 10
+	maxDashboardText = 4000
+)
+
+// metric
+func QueriesHandler(c telebot.Context) error {
+	queries, err := repository.GetQueries()
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения каталога: %v", err))
+	}
+	dashboards, err := repository.GetDashboards()
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения дашбордов: %v", err))
+	}
+	if len(queries) == 0 && len(dashboards) == 0 {
+		return c.Send("Каталог запросов пуст. Добавьте запрос: /query_set <имя> <выражение>")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Запросы:\n")
+	for _, q := range queries {
+		sb.WriteString("• " + q.Name)
+		if q.Description != "" {
+			sb.WriteString(" — " + q.Description)
+		}
+		sb.WriteString("\n  " + q.Expr + "\n")
+	}
+	if len(dashboards) > 0 {
+		sb.WriteString("\nДашборды:\n")
+		for _, d := range dashboards {
+			namespace, service := dashboardParams(d, "", "")
+			sb.WriteString(fmt.Sprintf("• %s (%s/%s): %s\n", d.Name, namespace, service, strings.Join(d.QueryList(), ", ")))
+		}
+	}
+	return c.Send(truncateText(sb.String(), maxDashboardText))
+}
+
+// operator
+func QuerySetHandler(c telebot.Context) error {
+	text := strings.TrimSpace(c.Text())
+	fields := strings.Fields(text)
+	if len(fields) < 3 {
+		return sendError(c, "Использование: /query_set <имя> <выражение>\n"+
+			"Параметры: {{service}}, {{namespace}}; в регулярных выражениях - {{service|regex}}\n"+
+			`Например: /query_set error_rate sum(rate(http_requests_total{job=~"{{service|regex}}.*", code=~"5.."}[5m]))`)
+	}
+	name := fields[1]
+	if !monitoring.ValidCatalogName(name) {
+		return sendError(c, "Имя запроса может содержать только латиницу, цифры, _ и -")
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(text, fields[0]))
+	expr := strings.TrimSpace(strings.TrimPrefix(rest, name))
+	if err := monitoring.ValidateQueryTemplate(expr); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка в запросе: %v", err))
+	}
+
+	query := &models.SavedQuery{Name: name, Expr: expr}
+	// описание и единицы измерения из YAML-каталога сохраняются при замене выражения
+	if existing, err := repository.GetQuery(name); err == nil && existing != nil {
+		query.Description, query.Unit = existing.Description, existing.Unit
+	}
+	if err := repository.SaveQuery(query); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка сохранения запроса: %v", err))
+	}
+	return c.Send(fmt.Sprintf("Запрос %s сохранен: %s", name, expr))
+}
+
+// operator
+func QueryDeleteHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) != 2 {
+		return sendError(c, "Использование: /query_del <имя>")
+	}
+	dashboards, err := repository.GetDashboards()
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения дашбордов: %v", err))
+	}
+	var usedBy []string
+	for _, d := range dashboards {
+		if d.Uses(parts[1]) {
+			usedBy = append(usedBy, d.Name)
+		}
+	}
+	if len(usedBy) > 0 {
+		return sendError(c, fmt.Sprintf("Запрос %s используется в дашбордах: %s", parts[1], strings.Join(usedBy, ", ")))
+	}
+
+	removed, err := repository.DeleteQuery(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка удаления запроса: %v", err))
+	}
+	if removed == 0 {
+		return sendError(c, fmt.Sprintf("Запрос %s не найден", parts[1]))
+	}
+	return c.Send(fmt.Sprintf("Запрос %s удален", parts[1]))
+}
+
+// metric
+func QueryRunHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) < 2 || len(parts) > 4 {
+		return sendError(c, "Использование: /query <имя> [сервис] [namespace]")
+	}
+	query, err := repository.GetQuery(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения запроса: %v", err))
+	}
+	if query == nil {
+		return sendError(c, fmt.Sprintf("Запрос %s не найден, список: /queries", parts[1]))
+	}
+
+	params := map[string]string{"namespace": "default"}
+	if len(parts) > 2 {
+		params["service"] = parts[2]
+	}
+	if len(parts) > 3 {
+		params["namespace"] = parts[3]
+	}
+	return sendPanels(c, query.Name, []string{query.Name}, map[string]models.SavedQuery{query.Name: *query}, params)
+}
+
+// operator
+func DashSetHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) < 3 || len(parts) > 5 {
+		return sendError(c, "Использование: /dash_set <имя> <запрос1,запрос2,...> [сервис] [namespace]")
+	}
+	name := parts[1]
+	if !monitoring.ValidCatalogName(name) {
+		return sendError(c, "Имя дашборда может содержать только латиницу, цифры, _ и -")
+	}
+	dashboard := &models.Dashboard{Name: name, Queries: parts[2]}
+	if len(parts) > 3 {
+		dashboard.Service = parts[3]
+	}
+	if len(parts) > 4 {
+		dashboard.Namespace = parts[4]
+	}
+
+	names := dashboard.QueryList()
+	if len(names) == 0 {
+		return sendError(c, "Не задано ни одного запроса")
+	}
+	queries, err := repository.GetQueriesByName(names)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения запросов: %v", err))
+	}
+	for _, n := range names {
+		if _, ok := queries[n]; !ok {
+			return sendError(c, fmt.Sprintf("Запрос %s не найден в каталоге", n))
+		}
+	}
+	dashboard.Queries = strings.Join(names, ",")
+
+	if err := repository.SaveDashboard(dashboard); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка сохранения дашборда: %v", err))
+	}
+	namespace, service := dashboardParams(*dashboard, "", "")
+	return c.Send(fmt.Sprintf("Дашборд %s (%s/%s): %s", name, namespace, service, strings.Join(names, ", ")))
+}
+
+// operator
+func DashDeleteHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) != 2 {
+		return sendError(c, "Использование: /dash_del <имя>")
+	}
+	removed, err := repository.DeleteDashboard(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка удаления дашборда: %v", err))
+	}
+	if removed == 0 {
+		return sendError(c, fmt.Sprintf("Дашборд %s не найден", parts[1]))
+	}
+	return c.Send(fmt.Sprintf("Дашборд %s удален", parts[1]))
+}
+
+// metric
+func DashHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) < 2 || len(parts) > 4 {
+		return sendError(c, "Использование: /dash <имя> [сервис] [namespace]")
+	}
+	dashboard, err := repository.GetDashboard(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения дашборда: %v", err))
+	}
+	if dashboard == nil {
+		return sendError(c, fmt.Sprintf("Дашборд %s не найден, список: /queries", parts[1]))
+	}
+
+	var service, namespace string
+	if len(parts) > 2 {
+		service = parts[2]
+	}
+	if len(parts) > 3 {
+		namespace = parts[3]
+	}
+	namespace, service = dashboardParams(*dashboard, service, namespace)
+
+	names := dashboard.QueryList()
+	queries, err := repository.GetQueriesByName(names)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения запросов: %v", err))
+	}
+	params := map[string]string{"service": service, "namespace": namespace}
+	return sendPanels(c, fmt.Sprintf("%s (%s/%s)", dashboard.Name, namespace, service), names, queries, params)
+}
+
+// dashboardParams возвращает namespace и сервис дашборда с учетом переопределений и значений по умолчанию
+func dashboardParams(d models.Dashboard, service, namespace string) (string, string) {
+	if service == "" {
+		service = d.Service
+	}
+	if service == "" {
+		service = d.Name
+	}
+	if namespace == "" {
+		namespace = d.Namespace
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	return namespace, service
+}
+
+// sendPanels выполняет запросы каталога параллельно и отправляет результаты одним сообщением
+func sendPanels(c telebot.Context, title string, names []string, queries map[string]models.SavedQuery, params map[string]string) error {
+	var panels []monitoring.Panel
+	failed := make(map[string]error)
+	for _, name := range names {
+		q, ok := queries[name]
+		if !ok {
+			failed[name] = fmt.Errorf("запрос не найден в каталоге")
+			continue
+		}
+		expr, err := monitoring.RenderQuery(q.Expr, params)
+		if err != nil {
+			failed[name] = err
+			continue
+		}
+		panels = append(panels, monitoring.Panel{Name: q.Name, Title: q.Description, Query: expr, Unit: q.Unit})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	results := make(map[string]monitoring.PanelResult)
+	for _, r := range GlobalMonitorClient.RunPanels(ctx, panels) {
+		results[r.Panel.Name] = r
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📊 %s\n", title))
+	for _, name := range names {
+		sb.WriteString("\n")
+		if err, ok := failed[name]; ok {
+			sb.WriteString(fmt.Sprintf("❌ %s: %v\n", name, err))
+			continue
+		}
+		sb.WriteString(formatPanel(results[name]))
+	}
+	return c.Send(truncateText(sb.String(), maxDashboardText))
+}
+
+func formatPanel(r monitoring.PanelResult) string {
+	var sb strings.Builder
+	header := r.Panel.Name
+	if r.Panel.Title != "" {
+		header += " — " + r.Panel.Title
+	}
+	if r.Err != nil {
+		return fmt.Sprintf("❌ %s: %v\n", header, r.Err)
+	}
+	sb.WriteString(header + "\n")
+	for _, w := range r.Warnings {
+		sb.WriteString("  ⚠️ " + w + "\n")
+	}
+	if len(r.Samples) == 0 {
+		sb.WriteString("  нет данных\n")
+	}
+	samples := append([]monitoring.Sample(nil), r.Samples...)
+	sort.Slice(samples, func(i, j int) bool {
+		return chart.SeriesLabel(samples[i].Metric) < chart.SeriesLabel(samples[j].Metric)
+	})
+	for i, s := range samples {
+		if i == maxPanelSamples {
+			sb.WriteString(fmt.Sprintf("  … и еще %d\n", len(r.Samples)-maxPanelSamples))
+			break
+		}
+		value := formatSample(s.Value)
+		if r.Panel.Unit != "" {
+			value += " " + r.Panel.Unit
+		}
+		if len(s.Metric) == 0 {
+			sb.WriteString("  " + value + "\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("  %s: %s\n", chart.SeriesLabel(s.Metric), value))
+		}
+	}
+	return sb.String()
+}
+
+func truncateText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
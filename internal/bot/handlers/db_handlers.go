@@ -1,48 +1,157 @@
 package handlers
 
 import (
-	"chatops/internal/db/config"
 	"chatops/internal/db/models"
 	"chatops/internal/db/repository"
-	"errors"
+	"chatops/internal/kube"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	telebot "gopkg.in/telebot.v3"
 )
 
-func HistoryHandler(c telebot.Context) error {
-	ans := ""
-	var incidents []models.IncidentHistory
-	if err := config.DB.Find(&incidents).Error; err != nil {
-		ans = fmt.Sprintf("Ошибка получения инцидентов: %v", err)
-		c.Send(ans)
-		return errors.New(ans)
-	}
-	ans += "История инцидентов:\n"
-	for _, i := range incidents {
-		ans += fmt.Sprintf("ID: %d, Status: %s\n", i.ID, i.Status)
-	}
-	return c.Send(ans)
-}
+const operationsPageSize = 15
 
 // db
 func OperationsHandler(c telebot.Context) error {
-	ans := ""
-	var operations []models.Operation
-	if err := config.DB.Find(&operations).Error; err != nil {
-		ans = fmt.Sprintf("Ошибка получения операций: %v", err)
-		c.Send(ans)
-		return errors.New(ans)
-	}
-	ans += "Операции:\n"
+	filter, page, err := parseOperationsFilter(strings.Fields(c.Text())[1:], time.Now())
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка в параметрах: %v\nИспользование: /operations [user=логин] [resource=namespace[/name]|kind/namespace/name] [since=24h] [until=2006-01-02T15:04] [page=N]", err))
+	}
+	filter.Limit = operationsPageSize
+	filter.Offset = (page - 1) * operationsPageSize
+
+	operations, total, err := repository.FindOperations(filter)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения операций: %v", err))
+	}
+	if total == 0 {
+		return c.Send("Операции не найдены")
+	}
+
+	pages := int((total + operationsPageSize - 1) / operationsPageSize)
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Операции (страница %d из %d, всего %d):\n", page, pages, total))
 	for _, o := range operations {
-		ans += fmt.Sprintf("ID: %d, Text: %s, Time: %v\n",
-			o.ID, o.Text, o.Time)
+		sb.WriteString(formatOperation(o))
+		sb.WriteString("\n")
+	}
+	if page < pages {
+		sb.WriteString(fmt.Sprintf("\nСледующая страница: page=%d", page+1))
+	}
+	return c.Send(sb.String())
+}
+
+func formatOperation(o models.Operation) string {
+	login := "-"
+	if o.User != nil {
+		login = o.User.Login
+	}
+	line := fmt.Sprintf("#%d %s %s %s", o.ID, o.Time.Format("2006-01-02 15:04:05"), login, strings.TrimSpace(o.Command+" "+o.Args))
+	if o.Command == "" {
+		line = fmt.Sprintf("#%d %s %s", o.ID, o.Time.Format("2006-01-02 15:04:05"), o.Text)
 	}
-	return c.Send(ans)
+	if strings.Contains(o.Resource, "/") {
+		line += fmt.Sprintf(" [%s]", o.Resource)
+	} else if o.Resource != "" {
+		line += fmt.Sprintf(" [%s/%s]", o.Namespace, o.Resource)
+	} else if o.Namespace != "" {
+		line += fmt.Sprintf(" [%s]", o.Namespace)
+	}
+	line += " → " + o.Status
+	if o.Confirmation != "" {
+		line += " (" + o.Confirmation + ")"
+	}
+	if o.FinishedAt != nil {
+		line += fmt.Sprintf(" за %s", o.FinishedAt.Sub(o.Time).Round(time.Millisecond))
+	}
+	if o.Error != "" {
+		line += ": " + o.Error
+	}
+	return line
+}
+
+// parseOperationsFilter разбирает аргументы /operations вида key=value
+func parseOperationsFilter(args []string, now time.Time) (repository.OperationFilter, int, error) {
+	var filter repository.OperationFilter
+	page := 1
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return filter, 0, fmt.Errorf("ожидается key=value, получено %q", arg)
+		}
+		switch kv[0] {
+		case "user":
+			user, err := repository.GetUserByLogin(kv[1])
+			if err != nil {
+				return filter, 0, fmt.Errorf("пользователь %s не найден", kv[1])
+			}
+			filter.UserID = &user.ID
+		case "resource":
+			data := strings.Split(kv[1], "/")
+			switch len(data) {
+			case 1:
+				filter.Namespace = data[0]
+			case 2:
+				filter.Namespace, filter.Name = data[0], data[1]
+			default:
+				workload, err := kube.ParseWorkload(kv[1])
+				if err != nil {
+					return filter, 0, err
+				}
+				filter.Namespace = workload.Namespace
+				filter.Resource = workload.Kind + "/" + workload.Namespace + "/" + workload.Name
+			}
+		case "since":
+			t, err := parseTimeBound(kv[1], now)
+			if err != nil {
+				return filter, 0, err
+			}
+			filter.Since = t
+		case "until":
+			t, err := parseTimeBound(kv[1], now)
+			if err != nil {
+				return filter, 0, err
+			}
+			filter.Until = t
+		case "page":
+			n, err := strconv.Atoi(kv[1])
+			if err != nil || n < 1 {
+				return filter, 0, fmt.Errorf("некорректный номер страницы %q", kv[1])
+			}
+			page = n
+		default:
+			return filter, 0, fmt.Errorf("неизвестный параметр %q", kv[0])
+		}
+	}
+	return filter, page, nil
 }
 
-func ProofLoginPaswordHandler(login, password string) bool {
-	ok := repository.AuthenticateUser(login, password)
-	return ok
+// parseTimeBound принимает длительность назад от now (30m, 24h, 7d) или абсолютное время
+func parseTimeBound(value string, now time.Time) (time.Time, error) {
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("не удалось разобрать время %q", value)
+}
+
+// ProofLoginPaswordHandler возвращает пользователя при верных логине и пароле, иначе nil
+func ProofLoginPaswordHandler(login, password string) *models.User {
+	user, err := repository.GetUserByCredentials(login, password)
+	if err != nil {
+		return nil
+	}
+	return user
 }
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"chatops/internal/app"
+	"chatops/internal/bot/auth"
+	"chatops/internal/db/models"
+	"chatops/internal/db/repository"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+// AlertAckHandler обрабатывает нажатие кнопки подтверждения алерта
+func AlertAckHandler(c telebot.Context) error {
+	if GlobalSessionStore == nil {
+		return c.Respond(&telebot.CallbackResponse{Text: "Авторизация недоступна"})
+	}
+	session, ok := GlobalSessionStore.Get(c.Sender().ID)
+	if !ok {
+		return c.Respond(&telebot.CallbackResponse{Text: "Вы не авторизованы. Введите /start для авторизации.", ShowAlert: true})
+	}
+	if !auth.HasRole(session.Role, models.RoleOperator) {
+		return c.Respond(&telebot.CallbackResponse{Text: "Недостаточно прав", ShowAlert: true})
+	}
+	auth.WithSession(c, session)
+
+	fingerprint := c.Callback().Data
+	startOperation(c, session, "/alert_ack", []string{fingerprint})
+	acked, err := repository.AcknowledgeEscalations(fingerprint, session.UserID)
+	if err != nil {
+		FinishAudit(c, models.ConfirmationSkipped, err)
+		log.Printf("Error acknowledging alert %s: %v", fingerprint, err)
+		return c.Respond(&telebot.CallbackResponse{Text: "Ошибка подтверждения алерта"})
+	}
+	// Эскалаций нет: алерт уже подтвердили, он погас или эскалация для него не настроена
+	if acked == 0 {
+		FinishAudit(c, models.ConfirmationSkipped, errors.New("нет неподтвержденных эскалаций по алерту"))
+		return c.Respond(&telebot.CallbackResponse{Text: "Нечего подтверждать: алерт уже подтвержден или погас", ShowAlert: true})
+	}
+	FinishAudit(c, models.ConfirmationSkipped, nil)
+	log.Printf("Alert %s acknowledged by %s (%d escalations stopped)", fingerprint, session.Login, acked)
+
+	c.Respond(&telebot.CallbackResponse{Text: "Алерт подтвержден"})
+	if err := c.Edit(c.Message().Text + fmt.Sprintf("\n\n✅ Подтвердил: %s", session.Login)); err != nil {
+		log.Printf("Error updating acknowledged message: %v", err)
+	}
+	return nil
+}
+
+// admin
+func EscalationSetHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) < 4 {
+		return sendError(c, "Использование: /escalation_set <метка key=value> <минуты> <цель> [цель...]\nЦели: duty:<метка>, user:<логин>, chat:<id>")
+	}
+	label := parts[1]
+	if !strings.Contains(label, "=") {
+		return sendError(c, "Метка должна иметь вид key=value")
+	}
+	minutes, err := strconv.Atoi(parts[2])
+	if err != nil || minutes <= 0 {
+		return sendError(c, "Ошибка при чтении таймаута в минутах")
+	}
+	for _, target := range parts[3:] {
+		if err := app.ValidateEscalationTarget(target); err != nil {
+			return sendError(c, fmt.Sprintf("Ошибка: %v", err))
+		}
+	}
+
+	policy := &models.EscalationPolicy{
+		Label:          label,
+		TimeoutMinutes: minutes,
+		Tiers:          strings.Join(parts[3:], ","),
+	}
+	if err := repository.SaveEscalationPolicy(policy); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка сохранения политики: %v", err))
+	}
+	return c.Send(fmt.Sprintf("Политика эскалации для %s: каждые %d мин. → %s", label, minutes, strings.Join(parts[3:], " → ")))
+}
+
+// admin
+func EscalationDeleteHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) != 2 {
+		return sendError(c, "Использование: /escalation_del <метка key=value>")
+	}
+	removed, err := repository.DeleteEscalationPolicy(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка удаления политики: %v", err))
+	}
+	if removed == 0 {
+		return sendError(c, fmt.Sprintf("Политика для %s не найдена", parts[1]))
+	}
+	return c.Send(fmt.Sprintf("Политика эскалации для %s удалена", parts[1]))
+}
+
+// db
+func EscalationsHandler(c telebot.Context) error {
+	policies, err := repository.GetEscalationPolicies()
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения политик: %v", err))
+	}
+	if len(policies) == 0 {
+		return c.Send("Политики эскалации не заданы")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Политики эскалации:\n")
+	for _, p := range policies {
+		sb.WriteString(fmt.Sprintf("%s: каждые %d мин. → %s\n", p.Label, p.TimeoutMinutes, strings.Join(p.TierList(), " → ")))
+	}
+	return c.Send(sb.String())
+}
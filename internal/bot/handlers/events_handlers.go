@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"chatops/internal/db/repository"
+	"chatops/internal/kube"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+const (
+	maxEventLines = 30
+	eventsUsage   = "Использование: /events <namespace>[/<имя>] [--warnings] или /events <вид>/<namespace>/<имя> [--warnings]\n" +
+		"Для Deployment показываются также события его ReplicaSet и подов, для StatefulSet (sts) и DaemonSet (ds) - события их подов"
+)
+
+// kube
+func EventsHandler(c telebot.Context) error {
+	args := strings.Fields(c.Text())[1:]
+	opts := kube.ListEventsOptions{}
+	var target string
+	for _, arg := range args {
+		switch {
+		case arg == "--warnings":
+			opts.WarningsOnly = true
+		case strings.HasPrefix(arg, "--"):
+			return sendError(c, fmt.Sprintf("неизвестный параметр %s\n%s", arg, eventsUsage))
+		case target == "":
+			target = arg
+		default:
+			return sendError(c, fmt.Sprintf("лишний аргумент %q\n%s", arg, eventsUsage))
+		}
+	}
+	// без вида имя проверяется как Deployment, для StatefulSet и DaemonSet вид указывается явно
+	w := kube.Workload{Kind: kube.KindDeployment, Namespace: target}
+	if strings.Contains(target, "/") {
+		var err error
+		if w, err = kube.ParseWorkload(target); err != nil {
+			return sendError(c, fmt.Sprintf("%v\n%s", err, eventsUsage))
+		}
+	}
+	if w.Namespace == "" {
+		return sendError(c, eventsUsage)
+	}
+	namespace := w.Namespace
+	opts.Name, opts.Kind = w.Name, w.Kind
+	if !authorizeResource(c, "events", w.Kind, namespace, w.Name) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events, err := GlobalKubeClient.ListEvents(ctx, namespace, &opts)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения событий: %v", err))
+	}
+	kind := "Событий"
+	if opts.WarningsOnly {
+		kind = "Предупреждений"
+	}
+	if len(events) == 0 {
+		return c.Send(fmt.Sprintf("%s %s нет", kind, target))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📋 %s %s: %d\n", kind, target, len(events)))
+	now := time.Now()
+	for i, e := range events {
+		if i == maxEventLines {
+			sb.WriteString(fmt.Sprintf("…и еще %d\n", len(events)-maxEventLines))
+			break
+		}
+		icon := "ℹ️"
+		if e.Type == "Warning" {
+			icon = "⚠️"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s назад %s %s", icon, formatAge(now.Sub(e.Time)), e.Reason, e.Object))
+		if e.Count > 1 {
+			sb.WriteString(fmt.Sprintf(" (×%d)", e.Count))
+		}
+		sb.WriteString(": " + e.Message + "\n")
+	}
+	return c.Send(truncateText(sb.String(), maxDashboardText))
+}
+
+// kube
+func EventsSubscribeHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) == 1 {
+		subscriptions, err := repository.GetChatEventSubscriptions(c.Chat().ID)
+		if err != nil {
+			return sendError(c, fmt.Sprintf("Ошибка получения подписок: %v", err))
+		}
+		if len(subscriptions) == 0 {
+			return c.Send("Чат не подписан на события. Подписка: /events_sub <namespace>")
+		}
+		namespaces := make([]string, 0, len(subscriptions))
+		for _, s := range subscriptions {
+			namespaces = append(namespaces, s.Namespace)
+		}
+		return c.Send("Предупреждения Kubernetes приходят в чат из namespace: " + strings.Join(namespaces, ", "))
+	}
+	if len(parts) != 2 || strings.Contains(parts[1], "/") {
+		return sendError(c, "Использование: /events_sub [namespace]")
+	}
+	namespace := parts[1]
+	if !authorizeResource(c, "events_sub", "", namespace, "") {
+		return nil
+	}
+	if err := repository.SaveEventSubscription(c.Chat().ID, namespace); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка сохранения подписки: %v", err))
+	}
+	return c.Send(fmt.Sprintf("Предупреждения Kubernetes из %s будут приходить в этот чат в течение минуты", namespace))
+}
+
+// kube
+func EventsUnsubscribeHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) != 2 {
+		return sendError(c, "Использование: /events_unsub <namespace>")
+	}
+	removed, err := repository.DeleteEventSubscription(c.Chat().ID, parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка удаления подписки: %v", err))
+	}
+	if removed == 0 {
+		return sendError(c, fmt.Sprintf("Чат не подписан на события %s", parts[1]))
+	}
+	return c.Send(fmt.Sprintf("Подписка на события %s отменена", parts[1]))
+}
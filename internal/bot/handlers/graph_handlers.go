@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"chatops/internal/chart"
+	"chatops/internal/monitoring"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+const (
+	defaultGraphRange = time.Hour
+	maxGraphRange     = 30 * 24 * time.Hour
+	graphPoints       = 240
+	minGraphStep      = 15 * time.Second
+)
+
+// metric
+func GraphHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) < 3 || len(parts) > 5 {
+		return sendError(c, "Использование: /graph <сервис> <метрика> [период, например 1h, 6h, 7d] [namespace]")
+	}
+	service := parts[1]
+	metric := parts[2]
+	window := defaultGraphRange
+	if len(parts) > 3 {
+		d, err := parseDuration(parts[3])
+		if err != nil {
+			return sendError(c, fmt.Sprintf("Ошибка: %v", err))
+		}
+		if d > maxGraphRange {
+			return sendError(c, fmt.Sprintf("Период не должен превышать %s", maxGraphRange))
+		}
+		window = d
+	}
+	namespace := "default"
+	if len(parts) > 4 {
+		namespace = parts[4]
+	}
+
+	if !monitoring.ValidMetricName(metric) {
+		return sendError(c, fmt.Sprintf("Некорректное имя метрики %q", metric))
+	}
+
+	query := monitoring.NewSelector(metric).HasPrefix("job", service).Eq("namespace", namespace).String()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	end := time.Now()
+	series, err := GlobalMonitorClient.QueryRange(ctx, query, end.Add(-window), end, graphStep(window))
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return sendError(c, "Превышено время ожидания запроса (timeout)")
+		}
+		return sendError(c, fmt.Sprintf("Произошла ошибка: %v", err))
+	}
+
+	image, err := chart.Render(fmt.Sprintf("%s %s/%s %s", metric, namespace, service, window), series)
+	if errors.Is(err, chart.ErrNoData) {
+		return c.Send(fmt.Sprintf("Нет данных по %s за %s", query, window))
+	}
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка построения графика: %v", err))
+	}
+
+	return c.Send(&telebot.Photo{
+		File:    telebot.FromReader(bytes.NewReader(image)),
+		Caption: fmt.Sprintf("%s за %s (%d рядов)", query, window, len(series)),
+	})
+}
+
+// graphStep подбирает шаг range-запроса так, чтобы на графике было около graphPoints точек
+func graphStep(window time.Duration) time.Duration {
+	step := (window / graphPoints).Truncate(time.Second)
+	if step < minGraphStep {
+		step = minGraphStep
+	}
+	return step
+}
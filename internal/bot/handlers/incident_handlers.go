@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"chatops/internal/bot/auth"
+	"chatops/internal/db/models"
+	"chatops/internal/db/repository"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+const (
+	historyIncidentsLimit = 20
+	// maxTimelineEvents ограничивает хронологию в /history <id> последними событиями:
+	// к автоматически сгруппированным инцидентам алерты добавляются постоянно
+	maxTimelineEvents = 40
+)
+
+var incidentSeverities = map[string]bool{
+	"critical": true,
+	"high":     true,
+	"warning":  true,
+	"info":     true,
+}
+
+// db
+func HistoryHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) > 1 {
+		id, err := parseIncidentID(parts[1])
+		if err != nil {
+			return sendError(c, err.Error())
+		}
+		incident, err := repository.GetIncidentByID(id)
+		if err != nil {
+			return sendError(c, fmt.Sprintf("Инцидент #%d не найден", id))
+		}
+		return c.Send(truncateText(FormatIncidentTimeline(incident), maxDashboardText))
+	}
+
+	incidents, err := repository.GetLatestIncidents(historyIncidentsLimit)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения инцидентов: %v", err))
+	}
+	if len(incidents) == 0 {
+		return c.Send("История инцидентов пуста")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("История инцидентов (хронология: /history <id>):\n\n")
+	for i := range incidents {
+		sb.WriteString(formatIncidentSummary(&incidents[i]))
+		sb.WriteString("\n")
+	}
+	return c.Send(truncateText(sb.String(), maxDashboardText))
+}
+
+// db
+func IncidentOpenHandler(c telebot.Context) error {
+	parts := strings.SplitN(c.Text(), " ", 4)
+	if len(parts) < 4 {
+		return sendError(c, "Использование: /incident_open <critical|high|warning|info> <namespace>/<service> <описание>")
+	}
+	severity := strings.ToLower(parts[1])
+	if !incidentSeverities[severity] {
+		return sendError(c, fmt.Sprintf("Неизвестная критичность: %s", parts[1]))
+	}
+	data := strings.SplitN(parts[2], "/", 2)
+	if len(data) < 2 {
+		return sendError(c, "Ошибка в парсинге namespace/service")
+	}
+	title := strings.TrimSpace(parts[3])
+
+	incident, err := repository.OpenIncident(title, severity, data[0], data[1], sessionUserID(c))
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка создания инцидента: %v", err))
+	}
+	return c.Send(fmt.Sprintf("🚨 Открыт инцидент #%d: %s", incident.ID, incident.Title))
+}
+
+// db
+func IncidentAckHandler(c telebot.Context) error {
+	return transitionIncident(c, models.IncidentAcknowledged, "/ack <id>")
+}
+
+// db
+func IncidentMitigateHandler(c telebot.Context) error {
+	return transitionIncident(c, models.IncidentMitigated, "/mitigate <id> [комментарий]")
+}
+
+// db
+func IncidentResolveHandler(c telebot.Context) error {
+	return transitionIncident(c, models.IncidentResolved, "/resolve <id> [комментарий]")
+}
+
+// db
+func IncidentReopenHandler(c telebot.Context) error {
+	return transitionIncident(c, models.IncidentOpen, "/reopen <id> [комментарий]")
+}
+
+func transitionIncident(c telebot.Context, to, usage string) error {
+	parts := strings.SplitN(c.Text(), " ", 3)
+	if len(parts) < 2 {
+		return sendError(c, "Использование: "+usage)
+	}
+	id, err := parseIncidentID(parts[1])
+	if err != nil {
+		return sendError(c, err.Error())
+	}
+	note := ""
+	if len(parts) > 2 {
+		note = strings.TrimSpace(parts[2])
+	}
+
+	incident, err := repository.TransitionIncident(id, to, sessionUserID(c), note)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Не удалось изменить состояние инцидента #%d: %v", id, err))
+	}
+	return c.Send(fmt.Sprintf("%s Инцидент #%d: %s", incidentStatusIcon(incident.Status), incident.ID, incident.Status))
+}
+
+// db
+func IncidentAssignHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) != 3 {
+		return sendError(c, "Использование: /assign <id> <логин>")
+	}
+	id, err := parseIncidentID(parts[1])
+	if err != nil {
+		return sendError(c, err.Error())
+	}
+	assignee, err := repository.GetUserByLogin(parts[2])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Пользователь %s не найден", parts[2]))
+	}
+	if err := repository.AssignIncident(id, assignee, sessionUserID(c)); err != nil {
+		return sendError(c, fmt.Sprintf("Не удалось назначить инцидент #%d: %v", id, err))
+	}
+	return c.Send(fmt.Sprintf("Инцидент #%d назначен на %s", id, assignee.Login))
+}
+
+// db
+func IncidentCommentHandler(c telebot.Context) error {
+	parts := strings.SplitN(c.Text(), " ", 3)
+	if len(parts) < 3 || strings.TrimSpace(parts[2]) == "" {
+		return sendError(c, "Использование: /comment <id> <текст>")
+	}
+	id, err := parseIncidentID(parts[1])
+	if err != nil {
+		return sendError(c, err.Error())
+	}
+	if err := repository.AddIncidentComment(id, sessionUserID(c), strings.TrimSpace(parts[2])); err != nil {
+		return sendError(c, fmt.Sprintf("Не удалось добавить комментарий к инциденту #%d: %v", id, err))
+	}
+	return c.Send(fmt.Sprintf("Комментарий добавлен к инциденту #%d", id))
+}
+
+// FormatIncidentTimeline форматирует инцидент и его хронологию для отправки в Telegram
+func FormatIncidentTimeline(incident *models.IncidentHistory) string {
+	var sb strings.Builder
+
+	title := incident.Title
+	if title == "" {
+		title = "(без названия)"
+	}
+	sb.WriteString(fmt.Sprintf("%s #%d %s\n", incidentStatusIcon(incident.Status), incident.ID, title))
+	sb.WriteString(fmt.Sprintf("Состояние: %s", incident.Status))
+	if incident.Severity != "" {
+		sb.WriteString(fmt.Sprintf(", критичность: %s", incident.Severity))
+	}
+	sb.WriteString("\n")
+	if incident.Namespace != "" || incident.Service != "" {
+		sb.WriteString(fmt.Sprintf("Сервис: %s/%s\n", incident.Namespace, incident.Service))
+	}
+	if incident.Assignee != nil {
+		sb.WriteString(fmt.Sprintf("Исполнитель: %s\n", incident.Assignee.Login))
+	}
+	if len(incident.Alerts) > 0 {
+		sb.WriteString(fmt.Sprintf("Алертов: %d\n", len(incident.Alerts)))
+	}
+
+	events := incident.Events
+	if len(events) > maxTimelineEvents {
+		sb.WriteString(fmt.Sprintf("  …ранее еще %d событий\n", len(events)-maxTimelineEvents))
+		events = events[len(events)-maxTimelineEvents:]
+	}
+	for _, event := range events {
+		who := "система"
+		if event.User != nil {
+			who = event.User.Login
+		}
+		sb.WriteString(fmt.Sprintf("  %s [%s] %s: %s\n", event.Time.Format("01-02 15:04"), event.Kind, who, event.Text))
+	}
+	if len(incident.Events) == 0 {
+		sb.WriteString(fmt.Sprintf("  %s: %s\n", incident.Time.Format("01-02 15:04"), incident.Status))
+	}
+	return sb.String()
+}
+
+// formatIncidentSummary форматирует инцидент одной строкой для списка /history
+func formatIncidentSummary(incident *models.IncidentHistory) string {
+	title := incident.Title
+	if title == "" {
+		title = "(без названия)"
+	}
+	details := []string{incident.Time.Format("01-02 15:04"), incident.Status}
+	if incident.Severity != "" {
+		details = append(details, incident.Severity)
+	}
+	if incident.Namespace != "" || incident.Service != "" {
+		details = append(details, incident.Namespace+"/"+incident.Service)
+	}
+	if incident.Assignee != nil {
+		details = append(details, incident.Assignee.Login)
+	}
+	if len(incident.Alerts) > 0 {
+		details = append(details, fmt.Sprintf("алертов: %d", len(incident.Alerts)))
+	}
+	return fmt.Sprintf("%s #%d %s (%s)", incidentStatusIcon(incident.Status), incident.ID, truncateText(title, 80), strings.Join(details, ", "))
+}
+
+func incidentStatusIcon(status string) string {
+	switch status {
+	case models.IncidentOpen:
+		return "🔥"
+	case models.IncidentAcknowledged:
+		return "👀"
+	case models.IncidentMitigated:
+		return "🩹"
+	case models.IncidentResolved:
+		return "✅"
+	default:
+		return "❔"
+	}
+}
+
+func parseIncidentID(s string) (uint, error) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(s, "#"), 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("Некорректный ID инцидента: %s", s)
+	}
+	return uint(id), nil
+}
+
+func sessionUserID(c telebot.Context) *uint {
+	session := auth.SessionFromContext(c)
+	if session == nil {
+		return nil
+	}
+	id := session.UserID
+	return &id
+}
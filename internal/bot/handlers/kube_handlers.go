@@ -26,20 +26,21 @@ func ScaleHandler(c telebot.Context) error {
     
     parts := strings.SplitN(c.Text(), " ", 3)
     if len(parts) < 3 {
-        return c.Send("Неправильное кол-во параметров")
+        return sendError(c, "Неправильное кол-во параметров")
     }
     
-    data := strings.SplitN(parts[1], "/", 2)
-    if len(data) < 2 {
-        return c.Send("Ошибка в парсинге namespace/name")
+    workload, err := kube.ParseWorkload(parts[1])
+    if err != nil {
+        return sendError(c, fmt.Sprintf("Ошибка в парсинге [вид/]namespace/name: %v", err))
     }
     
-    namespace := data[0]
-    name := data[1]
+    if !authorizeWorkload(c, "scale", workload) {
+        return nil
+    }
     
     num, err := strconv.Atoi(parts[2])
     if err != nil {
-        return c.Send("Ошибки при чтении числа реплик")
+        return sendError(c, "Ошибки при чтении числа реплик")
     }
     
     ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
@@ -59,7 +60,7 @@ func ScaleHandler(c telebot.Context) error {
         }
     }()
     
-    err = GlobalKubeClient.ScaleDeploymentWithLogs(ctx, namespace, name, int32(num), logCh)
+    err = GlobalKubeClient.ScaleWorkloadWithLogs(ctx, workload, int32(num), logCh)
     if err != nil {
 		str := fmt.Sprintf("Ошибка при выполнении команды: %v", err)
 		fmt.Println(str)
@@ -74,14 +75,15 @@ func RestartHandler(c telebot.Context) error {
 
 	parts := strings.SplitN(c.Text(), " ", 2)
 	if len(parts) < 2 {
-		return c.Send("Неправильное кол-во параметров ")
+		return sendError(c, "Неправильное кол-во параметров ")
+	}
+	workload, err := kube.ParseWorkload(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка в парсинге [вид/]namespace/name: %v", err))
 	}
-	data := strings.SplitN(parts[1], "/", 2)
-	if len(data) < 2 {
-		return c.Send("Ошибка в парсинге namespace/name ")
+	if !authorizeWorkload(c, "restart", workload) {
+		return nil
 	}
-	namespace := data[0]
-	name := data[1]
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -92,7 +94,7 @@ func RestartHandler(c telebot.Context) error {
             c.Send(msg) // Отправляем каждое сообщение сразу
         }
     }()
-	err := GlobalKubeClient.RestartDeploymentWithLogs(ctx, namespace, name, logCh)
+	err = GlobalKubeClient.RestartWorkloadWithLogs(ctx, workload, logCh)
 	if err != nil {
         str := fmt.Sprintf("Ошибка при выполнении команды: %v", err)
 		fmt.Println(str)
@@ -106,17 +108,18 @@ func RestartHandler(c telebot.Context) error {
 func RollbackHandler(c telebot.Context) error {
 	parts := strings.SplitN(c.Text(), " ", 3)
 	if len(parts) < 3 {
-		return c.Send("Неправильное кол-во параметров ")
+		return sendError(c, "Неправильное кол-во параметров ")
 	}
-	data := strings.SplitN(parts[1], "/", 2)
-	if len(data) < 2 {
-		return c.Send("Ошибка в парсинге namespace/name ")
+	workload, err := kube.ParseWorkload(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка в парсинге [вид/]namespace/name: %v", err))
+	}
+	if !authorizeWorkload(c, "rollback", workload) {
+		return nil
 	}
-	namespace := data[0]
-	name := data[1]
 	num, err := strconv.ParseInt(parts[2], 10, 64)
 	if err != nil {
-		return c.Send("Ошибки при чтении числа реплик ")
+		return sendError(c, "Ошибки при чтении числа реплик ")
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -126,7 +129,7 @@ func RollbackHandler(c telebot.Context) error {
             c.Send(msg) // Отправляем каждое сообщение сразу
         }
     }()
-	err = GlobalKubeClient.RollbackDeploymentWithLogs(ctx, namespace, name, num, logCh)
+	err = GlobalKubeClient.RollbackWorkloadWithLogs(ctx, workload, num, logCh)
 	if err != nil {
 		str := fmt.Sprintf("Ошибка при выполнении команды: %v", err)
 		fmt.Println(str)
@@ -140,20 +143,21 @@ func RollbackHandler(c telebot.Context) error {
 func RevisionsHandler(c telebot.Context) error {
 	parts := strings.SplitN(c.Text(), " ", 2)
 	if len(parts) < 2 {
-		return c.Send("Неправильное кол-во параметров ")
+		return sendError(c, "Неправильное кол-во параметров ")
+	}
+	workload, err := kube.ParseWorkload(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка в парсинге [вид/]namespace/name: %v", err))
 	}
-	data := strings.SplitN(parts[1], "/", 2)
-	if len(data) < 2 {
-		return c.Send("Ошибка в парсинге namespace/name ")
+	if !authorizeWorkload(c, "revisions", workload) {
+		return nil
 	}
-	namespace := data[0]
-	name := data[1]
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
     
-	ans, err := GlobalKubeClient.ListAvailableRevisions(ctx, namespace, name)
+	ans, err := GlobalKubeClient.ListWorkloadRevisions(ctx, workload)
 	if err != nil {
         str := fmt.Sprintf("Ошибка при выполнении команды: %v", err)
 		fmt.Println(str)
@@ -171,29 +175,228 @@ func RevisionsHandler(c telebot.Context) error {
 
 
 func ListPodsHandler(c telebot.Context) error {
-	parts := strings.SplitN(c.Text(), " ", 2)
-	if len(parts) < 2 {
-		return c.Send("Неправильное кол-во параметров ")
+	req, err := parseListPodsArgs(strings.Fields(c.Text())[1:])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("%v\n%s", err, listPodsUsage))
+	}
+	if !authorizeResource(c, "list_pods", kube.KindDeployment, req.namespace, req.opts.Deployment) {
+		return nil
 	}
-
-	namespace := parts[1]
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	pods, err := GlobalKubeClient.ListPods(ctx, req.namespace, &req.opts)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения подов: %v", err))
+	}
+	if len(pods) == 0 {
+		return c.Send(fmt.Sprintf("Подов %s не найдено", req.target()))
+	}
 
+	pages := (len(pods) + listPodsPageSize - 1) / listPodsPageSize
+	if req.page > pages {
+		return c.Send(fmt.Sprintf("Подов %s: %d, страниц: %d", req.target(), len(pods), pages))
+	}
+	from := (req.page - 1) * listPodsPageSize
+	to := from + listPodsPageSize
+	if to > len(pods) {
+		to = len(pods)
+	}
+	pagePods := pods[from:to]
 
-	ans, err := GlobalKubeClient.ListPods(ctx, namespace)
-	if err != nil {
-		str := fmt.Sprintf("Ошибка при выполнении команды: %v", err)
-		fmt.Println(str)
-		return err
+	header := fmt.Sprintf("📦 Поды %s: %d", req.target(), len(pods))
+	if pages > 1 {
+		header += fmt.Sprintf(", %d–%d (страница %d из %d)", from+1, to, req.page, pages)
+	}
+	for _, msg := range paginateTable(header, renderTable(podTableColumns, podTableRows(pagePods, time.Now()))) {
+		if err := c.Send(msg, telebot.ModeMarkdownV2); err != nil {
+			return err
+		}
+	}
+
+	var footer strings.Builder
+	for _, pod := range pagePods {
+		for _, problem := range containerProblems(pod) {
+			footer.WriteString(fmt.Sprintf("⚠️ %s/%s\n", pod.Name, problem))
+		}
 	}
-	var str string
-	for _, pod := range ans {
-		str += pod + "\n"
-		
+	if req.page < pages {
+		footer.WriteString(fmt.Sprintf("Следующая страница: /list_pods %s --page %d\n", req.args(), req.page+1))
 	}
+	if footer.Len() == 0 {
+		return nil
+	}
+	return c.Send(truncateText(footer.String(), maxDashboardText))
+}
+
+const (
+	listPodsPageSize = 50
+	listPodsUsage    = "Использование: /list_pods <namespace>[/<deployment>] [-l селектор меток] [--field-selector селектор полей] [--page N]\n" +
+		"Например: /list_pods prod -l app=api,tier!=cache --field-selector status.phase!=Running"
+)
+
+var podTableColumns = []string{"NAME", "READY", "STATUS", "RESTARTS", "AGE", "NODE", "IP"}
+
+// listPodsRequest - разобранные аргументы /list_pods
+type listPodsRequest struct {
+	namespace string
+	opts      kube.ListPodsOptions
+	page      int
+}
 
-	return c.Send(str)
+// target возвращает описание выборки подов для сообщений
+func (r *listPodsRequest) target() string {
+	target := r.namespace
+	if r.opts.Deployment != "" {
+		target += "/" + r.opts.Deployment
+	}
+	var filters []string
+	if r.opts.LabelSelector != "" {
+		filters = append(filters, r.opts.LabelSelector)
+	}
+	if r.opts.FieldSelector != "" {
+		filters = append(filters, r.opts.FieldSelector)
+	}
+	if len(filters) > 0 {
+		target += " (" + strings.Join(filters, "; ") + ")"
+	}
+	return target
+}
+
+// args возвращает аргументы команды без номера страницы
+func (r *listPodsRequest) args() string {
+	args := r.namespace
+	if r.opts.Deployment != "" {
+		args += "/" + r.opts.Deployment
+	}
+	if r.opts.LabelSelector != "" {
+		args += " -l " + r.opts.LabelSelector
+	}
+	if r.opts.FieldSelector != "" {
+		args += " --field-selector " + r.opts.FieldSelector
+	}
+	return args
+}
+
+// parseListPodsArgs разбирает <namespace>[/<deployment>] и флаги -l, --field-selector, --page
+func parseListPodsArgs(args []string) (*listPodsRequest, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("не указан namespace")
+	}
+	req := &listPodsRequest{page: 1}
+	namespace, deployment, found := strings.Cut(args[0], "/")
+	if namespace == "" || (found && (deployment == "" || strings.Contains(deployment, "/"))) {
+		return nil, fmt.Errorf("ошибка в парсинге %q", args[0])
+	}
+	req.namespace, req.opts.Deployment = namespace, deployment
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if i+1 >= len(args) {
+			return nil, fmt.Errorf("не указано значение %s", arg)
+		}
+		i++
+		switch arg {
+		case "-l", "--selector":
+			req.opts.LabelSelector = args[i]
+		case "--field-selector":
+			req.opts.FieldSelector = args[i]
+		case "--page":
+			page, err := strconv.Atoi(args[i])
+			if err != nil || page < 1 {
+				return nil, fmt.Errorf("--page должен быть положительным числом")
+			}
+			req.page = page
+		default:
+			return nil, fmt.Errorf("неизвестный параметр %s", arg)
+		}
+	}
+	return req, nil
+}
+
+// podTableRows раскладывает поды в строки таблицы, как в kubectl get pods -o wide
+func podTableRows(pods []kube.PodInfo, now time.Time) [][]string {
+	rows := make([][]string, 0, len(pods))
+	for _, pod := range pods {
+		restarts := strconv.Itoa(int(pod.Restarts))
+		if pod.Restarts > 0 {
+			if last := lastRestart(pod); !last.IsZero() {
+				restarts += fmt.Sprintf(" (%s)", formatAge(now.Sub(last)))
+			}
+		}
+		age := "-"
+		if !pod.CreatedAt.IsZero() {
+			age = formatAge(now.Sub(pod.CreatedAt))
+		}
+		rows = append(rows, []string{
+			pod.Name,
+			fmt.Sprintf("%d/%d", pod.ReadyContainers, pod.TotalContainers),
+			pod.Status,
+			restarts,
+			age,
+			valueOrDash(pod.NodeName),
+			valueOrDash(pod.IP),
+		})
+	}
+	return rows
+}
+
+// lastRestart возвращает время последнего завершения контейнера пода
+func lastRestart(pod kube.PodInfo) time.Time {
+	var last time.Time
+	for _, ct := range pod.Containers {
+		if ct.LastTerminatedAt.After(last) {
+			last = ct.LastTerminatedAt
+		}
+	}
+	return last
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// formatAge форматирует возраст кратко, как kubectl: 45s, 12m, 5h, 3d
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// containerProblems описывает проблемные контейнеры пода: ожидание с причиной,
+// ненулевой код выхода и причину последнего перезапуска
+func containerProblems(pod kube.PodInfo) []string {
+	var result []string
+	for _, ct := range pod.Containers {
+		var problems []string
+		if ct.State == kube.ContainerWaiting && ct.Reason != "" && ct.Reason != "PodInitializing" && ct.Reason != "ContainerCreating" {
+			problems = append(problems, ct.Reason)
+		}
+		if ct.State == kube.ContainerTerminated && ct.ExitCode != 0 {
+			problems = append(problems, strings.TrimSpace(fmt.Sprintf("%s (код %d)", ct.Reason, ct.ExitCode)))
+		}
+		if ct.LastTerminationReason != "" && ct.Restarts > 0 {
+			problems = append(problems, fmt.Sprintf("последний выход: %s (код %d)", ct.LastTerminationReason, ct.LastExitCode))
+		}
+		if len(problems) == 0 {
+			continue
+		}
+		name := ct.Name
+		if ct.Kind != kube.ContainerRegular {
+			name += " [" + ct.Kind + "]"
+		}
+		result = append(result, fmt.Sprintf("%s: %s, перезапусков: %d", name, strings.Join(problems, ", "), ct.Restarts))
+	}
+	return result
 }
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"chatops/internal/kube"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+const (
+	defaultLogsTail   = 200
+	maxLogsTail       = 5000
+	maxInlineLogs     = 3800
+	defaultTailFor    = time.Minute
+	maxTailFor        = 5 * time.Minute
+	tailInitialLines  = 10
+	tailRefresh       = 2 * time.Second
+	logsUsage         = "Использование: /logs <namespace>/<pod> [контейнер] [--tail N] [--since 10m] [--previous] [--grep шаблон]\nЛоги всех подов deployment: /logs <namespace>/deploy/<имя> ...\n--grep фильтрует полученные строки"
+	tailUsage         = "Использование: /tail <namespace>/<pod> [контейнер] [--for 1m] [--grep шаблон]"
+	maxTailLineLength = 1000
+)
+
+// logsRequest - разобранные аргументы /logs и /tail
+type logsRequest struct {
+	namespace  string
+	pod        string
+	deployment string
+	opts       kube.PodLogsOptions
+	grep       *regexp.Regexp
+	duration   time.Duration
+}
+
+// target возвращает цель в виде namespace/pod или namespace/deploy/имя
+func (r *logsRequest) target() string {
+	if r.deployment != "" {
+		return r.namespace + "/deploy/" + r.deployment
+	}
+	return r.namespace + "/" + r.pod
+}
+
+func (r *logsRequest) matches(line string) bool {
+	return r.grep == nil || r.grep.MatchString(line)
+}
+
+// parseLogsArgs разбирает аргументы команды; follow - разбор для /tail
+func parseLogsArgs(args []string, follow bool) (*logsRequest, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("не указан под")
+	}
+	req := &logsRequest{duration: defaultTailFor}
+	parts := strings.Split(args[0], "/")
+	switch {
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		req.namespace, req.pod = parts[0], parts[1]
+	case len(parts) == 3 && (parts[1] == "deploy" || parts[1] == "deployment") && parts[0] != "" && parts[2] != "" && !follow:
+		req.namespace, req.deployment = parts[0], parts[2]
+	default:
+		return nil, fmt.Errorf("ошибка в парсинге %q", args[0])
+	}
+
+	var sinceSet, tailSet bool
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		value := func() (string, error) {
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("не указано значение %s", arg)
+			}
+			i++
+			return args[i], nil
+		}
+		switch {
+		case arg == "--previous" && !follow:
+			req.opts.Previous = true
+		case arg == "--tail" && !follow:
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n <= 0 || n > maxLogsTail {
+				return nil, fmt.Errorf("--tail должен быть числом от 1 до %d", maxLogsTail)
+			}
+			req.opts.TailLines, tailSet = n, true
+		case arg == "--since" && !follow:
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			d, err := time.ParseDuration(v)
+			if err != nil || d < time.Second {
+				return nil, fmt.Errorf("некорректная длительность --since %q", v)
+			}
+			req.opts.SinceSeconds, sinceSet = int64(d/time.Second), true
+		case arg == "--for" && follow:
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 || d > maxTailFor {
+				return nil, fmt.Errorf("--for должен быть длительностью до %s", maxTailFor)
+			}
+			req.duration = d
+		case arg == "--grep":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			re, err := regexp.Compile(v)
+			if err != nil {
+				return nil, fmt.Errorf("некорректный шаблон --grep: %v", err)
+			}
+			req.grep = re
+		case strings.HasPrefix(arg, "--"):
+			return nil, fmt.Errorf("неизвестный параметр %s", arg)
+		case req.opts.Container == "":
+			req.opts.Container = arg
+		default:
+			return nil, fmt.Errorf("лишний аргумент %q", arg)
+		}
+	}
+
+	// без --tail ограничиваем объем: последние строки или все строки за --since, но не больше maxLogsTail
+	if !tailSet {
+		req.opts.TailLines = defaultLogsTail
+		if sinceSet {
+			req.opts.TailLines = maxLogsTail
+		}
+	}
+	return req, nil
+}
+
+// kube
+func LogsHandler(c telebot.Context) error {
+	req, err := parseLogsArgs(strings.Fields(c.Text())[1:], false)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("%v\n%s", err, logsUsage))
+	}
+	kind, name := "Pod", req.pod
+	if req.deployment != "" {
+		kind, name = kube.KindDeployment, req.deployment
+	}
+	if !authorizeResource(c, "logs", kind, req.namespace, name) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	var lines []string
+	if req.deployment != "" {
+		logs, err := GlobalKubeClient.GetDeploymentLogs(ctx, req.namespace, req.deployment, &req.opts)
+		if err != nil {
+			return sendError(c, fmt.Sprintf("Ошибка получения логов: %v", err))
+		}
+		for _, l := range logs {
+			if req.matches(l.Text) {
+				lines = append(lines, fmt.Sprintf("%s | %s", l.Pod, l.Text))
+			}
+		}
+	} else {
+		logs, err := GlobalKubeClient.GetPodLogs(ctx, req.namespace, req.pod, &req.opts)
+		if err != nil {
+			return sendError(c, fmt.Sprintf("Ошибка получения логов: %v", err))
+		}
+		for _, l := range strings.Split(strings.TrimRight(logs, "\n"), "\n") {
+			if l != "" && req.matches(l) {
+				lines = append(lines, l)
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		return c.Send(fmt.Sprintf("Логи %s пусты", req.target()))
+	}
+	text := strings.Join(lines, "\n")
+	header := fmt.Sprintf("📜 %s (%d строк)", req.target(), len(lines))
+	if len(escapeMarkdown(header))+len(escapeCode(text))+10 <= maxInlineLogs {
+		return c.Send(fmt.Sprintf("%s\n```\n%s\n```", escapeMarkdown(header), escapeCode(text)), telebot.ModeMarkdownV2)
+	}
+
+	doc := &telebot.Document{
+		File:     telebot.FromReader(bytes.NewReader([]byte(text + "\n"))),
+		FileName: strings.ReplaceAll(req.target(), "/", "_") + ".log",
+		MIME:     "text/plain",
+		Caption:  header,
+	}
+	return c.Send(doc)
+}
+
+// kube
+func TailHandler(c telebot.Context) error {
+	req, err := parseLogsArgs(strings.Fields(c.Text())[1:], true)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("%v\n%s", err, tailUsage))
+	}
+	if !authorizeResource(c, "logs", "Pod", req.namespace, req.pod) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), req.duration)
+	defer cancel()
+
+	req.opts.Follow = true
+	req.opts.TailLines = tailInitialLines
+	stream, err := GlobalKubeClient.StreamPodLogs(ctx, req.namespace, req.pod, &req.opts)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения логов: %v", err))
+	}
+	defer stream.Close()
+
+	header := fmt.Sprintf("📡 %s, %s", req.target(), req.duration)
+	msg, err := c.Bot().Send(c.Recipient(), escapeMarkdown(header+": ожидание строк…"), telebot.ModeMarkdownV2)
+	if err != nil {
+		return err
+	}
+
+	lineCh := make(chan string)
+	go func() {
+		defer close(lineCh)
+		scanner := bufio.NewScanner(stream)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			select {
+			case lineCh <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var lines []string
+	var shown string
+	render := func(status string) {
+		text := escapeMarkdown(header+status) + "\n```\n" + escapeCode(strings.Join(lines, "\n")) + "\n```"
+		if len(lines) == 0 {
+			text = escapeMarkdown(header + status + ": новых строк нет")
+		}
+		if text == shown {
+			return
+		}
+		if _, err := c.Bot().Edit(msg, text, telebot.ModeMarkdownV2); err == nil {
+			shown = text
+		}
+	}
+
+	ticker := time.NewTicker(tailRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case line, ok := <-lineCh:
+			if !ok {
+				render(" ⏹")
+				return nil
+			}
+			if !req.matches(line) {
+				continue
+			}
+			lines = append(lines, truncateText(line, maxTailLineLength))
+			// в сообщении остаются последние строки, которые в него помещаются
+			for len(lines) > 1 && len(escapeCode(strings.Join(lines, "\n")))+len(header)*2+20 > maxInlineLogs {
+				lines = lines[1:]
+			}
+		case <-ticker.C:
+			render(" ▶️")
+		}
+	}
+}
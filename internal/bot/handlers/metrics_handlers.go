@@ -3,9 +3,12 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"log"
+	"strconv"
 	"strings"
 	"time"
 
+	"chatops/internal/kube"
 	"chatops/internal/monitoring"
 
 	telebot "gopkg.in/telebot.v3"
@@ -22,7 +25,7 @@ func SetMonitorClient(client *monitoring.Client) {
 func MetricHandler(c telebot.Context) error {
 	parts := strings.Split(c.Text(), " ")
 	if len(parts) < 3 {
-		return c.Send("Использование: /metric <сервис> <метрика> [namespace]")
+		return sendError(c, "Использование: /metric <сервис> <метрика> [namespace]")
 	}
 	service := parts[1]
 	metric := parts[2]
@@ -31,7 +34,11 @@ func MetricHandler(c telebot.Context) error {
 		namespace = parts[3]
 	}
 
-	req := fmt.Sprintf(`%s{job=~"^%s.*", namespace="%s"}`, metric, service, namespace)
+	if !monitoring.ValidMetricName(metric) {
+		return sendError(c, fmt.Sprintf("Некорректное имя метрики %q", metric))
+	}
+
+	req := monitoring.NewSelector(metric).HasPrefix("job", service).Eq("namespace", namespace).String()
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -41,9 +48,9 @@ fmt.Println("Getting metric...")
 
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return c.Send("Превышено время ожидания запроса (timeout)")
+			return sendError(c, "Превышено время ожидания запроса (timeout)")
 		}
-		return c.Send(fmt.Sprintf("Произошла ошибка: %v", err))
+		return sendError(c, fmt.Sprintf("Произошла ошибка: %v", err))
 	}
 
 
@@ -52,18 +59,21 @@ fmt.Println("Getting metric...")
     fmt.Println("Resp metric:")
     fmt.Println(response)
 
-	var allValues string
+	for _, warning := range response.Warnings {
+		result += fmt.Sprintf("⚠️ %s\n", warning)
+	}
 
-	for _, result := range response.Data.Result {
-		allValues += fmt.Sprintf("%v: ", result.Metric["pod"])
-		for _, v := range result.Value {
-			allValues += fmt.Sprintf("%v ", v)
-		}
+	samples, err := response.Data.Samples()
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Произошла ошибка: %v", err))
 	}
 
-	allValues = strings.TrimSpace(allValues)
+	var allValues []string
+	for _, sample := range samples {
+		allValues = append(allValues, fmt.Sprintf("%v: %s", sample.Metric["pod"], strconv.FormatFloat(sample.Value, 'f', -1, 64)))
+	}
 
-	return c.Send(result + allValues)
+	return c.Send(result + strings.Join(allValues, "\n"))
 }
 
 // metric
@@ -71,7 +81,7 @@ fmt.Println("Getting metric...")
 func ListMetricsHandler(c telebot.Context) error {
 	parts := strings.SplitN(c.Text(), " ", 3)
 	if len(parts) < 3 {
-		return c.Send("Неправильное кол-во параметров ")
+		return sendError(c, "Неправильное кол-во параметров ")
 	}
 	service := parts[1]
 	req := service
@@ -85,9 +95,9 @@ func ListMetricsHandler(c telebot.Context) error {
 	fmt.Println(response)
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return c.Send("Превышено время ожидания запроса (timeout)")
+			return sendError(c, "Превышено время ожидания запроса (timeout)")
 		}
-		return c.Send(fmt.Sprintf("Произошла ошибка: %v", err))
+		return sendError(c, fmt.Sprintf("Произошла ошибка: %v", err))
 	}
 	var matchedMetrics []string
 	for _, str := range response {
@@ -107,7 +117,7 @@ func StatusHandler(c telebot.Context) error {
 	parts := strings.Split(c.Text(), " ")
 
 	if len(parts) < 2 {
-		return c.Send("Usage: /status <job_name> [namespace]")
+		return sendError(c, "Usage: /status <job_name> [namespace]")
 	}
 	job := parts[1]
 	namespace := "default"
@@ -119,26 +129,56 @@ func StatusHandler(c telebot.Context) error {
 	defer cancel()
 
 	fmt.Println("Getting status dashboard for job:", job, "in namespace:", namespace)
-	response, err := GlobalMonitorClient.GetStatusDashboard(ctx, namespace, job)
+	// Данные Kubernetes показываются только при праве на Deployment, как в /list_pods и /events;
+	// без него дашборд строится по метрикам
+	var deployment *kube.DeploymentStatus
+	var workloadPods []monitoring.WorkloadPod
+	if authorizeResource(c, "status", kube.KindDeployment, namespace, job) {
+		deployment, workloadPods = deploymentForDashboard(ctx, namespace, job)
+	}
+	response, err := GlobalMonitorClient.GetStatusDashboard(ctx, namespace, job, workloadPods)
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return c.Send("Превышено время ожидания запроса (timeout)")
+			return sendError(c, "Превышено время ожидания запроса (timeout)")
 		}
+		markAuditError(c, err.Error())
 		return c.Send(fmt.Sprintf("❌ *Произошла ошибка:*\n`%v`", escapeMarkdown(err.Error())), telebot.ModeMarkdownV2)
 	}
 
 	fmt.Printf("Successfully got dashboard: %+v\n", response)
 
-	return c.Send(FormatDashboardForTelegram(response), telebot.ModeMarkdownV2)
+	return c.Send(FormatDashboardForTelegram(response, deployment), telebot.ModeMarkdownV2)
 
 }
 
-// FormatDashboardForTelegram форматирует данные дашборда в строку для отправки в Telegram
-func FormatDashboardForTelegram(dashboard *monitoring.ServiceStatusDashboard) string {
+// deploymentForDashboard получает из Kubernetes состояние Deployment сервиса и его поды для дашборда.
+// Если Kubernetes не подключен или Deployment не найден, возвращает nil.
+func deploymentForDashboard(ctx context.Context, namespace, name string) (*kube.DeploymentStatus, []monitoring.WorkloadPod) {
+	if GlobalKubeClient == nil {
+		return nil, nil
+	}
+	deployment, err := GlobalKubeClient.GetDeploymentStatus(ctx, namespace, name)
+	if err != nil {
+		log.Printf("Warning: could not get deployment %s/%s: %v", namespace, name, err)
+		return nil, nil
+	}
+	pods := make([]monitoring.WorkloadPod, 0, len(deployment.Pods))
+	for _, pod := range deployment.Pods {
+		pods = append(pods, monitoring.WorkloadPod{Name: pod.Name, Phase: pod.Phase, Ready: pod.Ready, Restarts: int64(pod.Restarts)})
+	}
+	return deployment, pods
+}
+
+// FormatDashboardForTelegram форматирует данные дашборда и Deployment (nil - без него) в строку для отправки в Telegram
+func FormatDashboardForTelegram(dashboard *monitoring.ServiceStatusDashboard, deployment *kube.DeploymentStatus) string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("*Статус сервиса: `%s`*\n\n", escapeMarkdown(dashboard.ServiceName)))
 
+	if deployment != nil {
+		sb.WriteString(formatDeploymentStatus(deployment))
+	}
+
 	if len(dashboard.Alerts) > 0 {
 		sb.WriteString("🔥 *Активные алерты:*\\n ")
 		for _, alert := range dashboard.Alerts {
@@ -186,8 +226,8 @@ func FormatDashboardForTelegram(dashboard *monitoring.ServiceStatusDashboard) st
 			sb.WriteString(fmt.Sprintf("*Под:* `%s`\n", escapeMarkdown(pod.PodName)))
 			sb.WriteString(fmt.Sprintf("*Статус:* %s %s\n", statusIcon, escapeMarkdown(statusText)))
 
-			sb.WriteString(fmt.Sprintf("*CPU:* `%.2f / %.2f` cores\n", pod.CPUUsageCores, pod.CPULimitCores))
-			sb.WriteString(fmt.Sprintf("*Память:* `%.0f / %.0f` MiB\n", memUsageMiB, memLimitMiB))
+			sb.WriteString(fmt.Sprintf("*CPU:* `%.2f / %.2f` cores%s\n", pod.CPUUsageCores, pod.CPULimitCores, limitWarning(pod.CPURatio())))
+			sb.WriteString(fmt.Sprintf("*Память:* `%.0f / %.0f` MiB%s\n", memUsageMiB, memLimitMiB, limitWarning(pod.MemoryRatio())))
 			sb.WriteString(fmt.Sprintf("*Перезапуски:* `%d`\n", pod.Restarts))
 			if pod.OOMKilled {
 				sb.WriteString("*OOMKilled:* 💀 `true`\n")
@@ -200,6 +240,55 @@ func FormatDashboardForTelegram(dashboard *monitoring.ServiceStatusDashboard) st
 	return sb.String()
 }
 
+// formatDeploymentStatus форматирует реплики, ревизию, образы, выкатку и последние события Deployment
+func formatDeploymentStatus(d *kube.DeploymentStatus) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("📦 *Deployment:* `%s`\n", escapeMarkdown(d.Name)))
+	sb.WriteString(fmt.Sprintf("*Реплики:* `%d` желаемых, `%d` готовых, `%d` обновленных\n", d.Desired, d.Ready, d.Updated))
+	if d.Revision > 0 {
+		sb.WriteString(fmt.Sprintf("*Ревизия:* `%d`\n", d.Revision))
+	}
+	for _, image := range d.Images {
+		sb.WriteString(fmt.Sprintf("*Образ:* `%s`\n", escapeMarkdown(image)))
+	}
+
+	switch d.Rollout {
+	case kube.RolloutComplete:
+		sb.WriteString("*Выкатка:* ✅ завершена\n")
+	case kube.RolloutProgressing:
+		sb.WriteString("*Выкатка:* 🔄 в процессе\n")
+	case kube.RolloutFailed:
+		sb.WriteString("*Выкатка:* ❌ не удалась\n")
+	}
+	if d.Rollout != kube.RolloutComplete && d.RolloutMessage != "" {
+		sb.WriteString(fmt.Sprintf("> %s\n", escapeMarkdown(d.RolloutMessage)))
+	}
+
+	if len(d.Events) > 0 {
+		sb.WriteString("📋 *Последние события:*\n")
+		for _, e := range d.Events {
+			icon := "ℹ️"
+			if e.Type == "Warning" {
+				icon = "⚠️"
+			}
+			sb.WriteString(fmt.Sprintf("%s `%s` %s %s: %s\n", icon, e.Time.Format("15:04:05"),
+				escapeMarkdown(e.Object), escapeMarkdown(e.Reason), escapeMarkdown(e.Message)))
+		}
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// limitWarning возвращает отметку о потреблении выше monitoring.LimitWarningRatio от лимита
+func limitWarning(ratio float64) string {
+	if ratio <= monitoring.LimitWarningRatio {
+		return ""
+	}
+	return fmt.Sprintf(" ⚠️ `%.0f%%`", ratio*100)
+}
+
 // escapeMarkdown escapes characters that have special meaning in Telegram's MarkdownV2.
 func escapeMarkdown(s string) string {
 	r := strings.NewReplacer(
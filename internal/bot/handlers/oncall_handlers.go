@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"chatops/internal/db/models"
+	"chatops/internal/db/repository"
+
+	telebot "gopkg.in/telebot.v3"
+	"gorm.io/gorm"
+)
+
+const onCallTimeLayout = "2006-01-02 15:04"
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// db
+func OnCallHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	var schedules []models.OnCallSchedule
+	if len(parts) > 1 {
+		schedule, err := repository.GetOnCallSchedule(parts[1])
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Send(fmt.Sprintf("Расписание для %s не задано", parts[1]))
+		}
+		if err != nil {
+			return sendError(c, fmt.Sprintf("Ошибка получения расписания: %v", err))
+		}
+		schedules = append(schedules, *schedule)
+	} else {
+		var err error
+		schedules, err = repository.GetOnCallSchedules()
+		if err != nil {
+			return sendError(c, fmt.Sprintf("Ошибка получения расписаний: %v", err))
+		}
+	}
+	if len(schedules) == 0 {
+		return c.Send("Расписания дежурств не заданы")
+	}
+
+	now := time.Now()
+	var blocks []string
+	for i := range schedules {
+		blocks = append(blocks, formatOnCall(&schedules[i], now))
+	}
+	return c.Send(strings.Join(blocks, "\n\n"))
+}
+
+// admin
+func OnCallScheduleHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) < 6 || len(parts) > 7 {
+		return sendError(c, "Использование: /oncall_schedule <метка key=value> <daily|weekly> <HH:MM> <часовой пояс> <логин1,логин2,...> [день недели mon..sun]")
+	}
+	label := parts[1]
+	if !strings.Contains(label, "=") {
+		return sendError(c, "Метка должна иметь вид key=value")
+	}
+
+	schedule := &models.OnCallSchedule{
+		Label:          label,
+		Rotation:       parts[2],
+		HandoffTime:    parts[3],
+		HandoffWeekday: int(time.Monday),
+		Timezone:       parts[4],
+		Members:        parts[5],
+		StartsAt:       time.Now(),
+	}
+	// при изменении расписания точка отсчета ротации сохраняется, иначе сменится текущий дежурный
+	// и сдвинутся границы смен, от которых считались обмены и подмены
+	existing, err := repository.GetOnCallSchedule(label)
+	switch {
+	case err == nil:
+		schedule.StartsAt = existing.StartsAt
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return sendError(c, fmt.Sprintf("Ошибка получения расписания: %v", err))
+	}
+	if len(parts) == 7 {
+		weekday, ok := weekdayNames[strings.ToLower(parts[6])]
+		if !ok {
+			return sendError(c, "День недели: mon, tue, wed, thu, fri, sat или sun")
+		}
+		schedule.HandoffWeekday = int(weekday)
+	}
+	if err := schedule.Validate(); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка: %v", err))
+	}
+	for _, login := range schedule.MemberList() {
+		if _, err := repository.GetUserByLogin(login); err != nil {
+			return sendError(c, fmt.Sprintf("Пользователь %s не найден", login))
+		}
+	}
+
+	if err := repository.SaveOnCallSchedule(schedule); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка сохранения расписания: %v", err))
+	}
+	return c.Send("Расписание сохранено\n\n" + formatOnCall(schedule, time.Now()))
+}
+
+// operator
+func OnCallSwapHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) != 4 {
+		return sendError(c, "Использование: /oncall_swap <метка key=value> <логин1> <логин2>\nБлижайшие смены двух участников ротации меняются местами.")
+	}
+	schedule, err := repository.GetOnCallSchedule(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Расписание для %s не найдено", parts[1]))
+	}
+	first, err := repository.GetUserByLogin(parts[2])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Пользователь %s не найден", parts[2]))
+	}
+	second, err := repository.GetUserByLogin(parts[3])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Пользователь %s не найден", parts[3]))
+	}
+	if first.ID == second.ID {
+		return sendError(c, "Нельзя поменяться сменой с самим собой")
+	}
+
+	// смены ищутся с учетом уже назначенных подмен, чтобы новые подмены не пересекались со старыми
+	now := time.Now()
+	existing, err := repository.GetUpcomingOnCallOverrides(schedule.ID, now)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения подмен: %v", err))
+	}
+	firstShift, err := schedule.NextDutyOf(first.Login, now, existing)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка: %v", err))
+	}
+	secondShift, err := schedule.NextDutyOf(second.Login, now, existing)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка: %v", err))
+	}
+
+	reason := fmt.Sprintf("обмен сменами %s ↔ %s", first.Login, second.Login)
+	overrides := []*models.OnCallOverride{
+		{ScheduleID: schedule.ID, UserID: second.ID, StartsAt: firstShift.Start, EndsAt: firstShift.End, Reason: reason},
+		{ScheduleID: schedule.ID, UserID: first.ID, StartsAt: secondShift.Start, EndsAt: secondShift.End, Reason: reason},
+	}
+	for _, override := range overrides {
+		if err := repository.CreateOnCallOverride(override); err != nil {
+			return sendError(c, fmt.Sprintf("Ошибка сохранения подмены: %v", err))
+		}
+	}
+
+	loc, _ := schedule.Location()
+	return c.Send(fmt.Sprintf(
+		"Смены обменяны в %s:\n- %s дежурит за %s: %s — %s\n- %s дежурит за %s: %s — %s",
+		schedule.Label,
+		second.Login, first.Login, firstShift.Start.In(loc).Format(onCallTimeLayout), firstShift.End.In(loc).Format(onCallTimeLayout),
+		first.Login, second.Login, secondShift.Start.In(loc).Format(onCallTimeLayout), secondShift.End.In(loc).Format(onCallTimeLayout),
+	))
+}
+
+// operator
+func OnCallOverrideHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) < 5 {
+		return sendError(c, "Использование: /oncall_override <метка key=value> <логин> <с> <по> [причина]\n"+
+			"Время: now, +8h, +3d или 2006-01-02T15:04 / 2006-01-02 в часовом поясе расписания; \"по\" вида +Nd/+Nh считается от \"с\".")
+	}
+	schedule, err := repository.GetOnCallSchedule(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Расписание для %s не найдено", parts[1]))
+	}
+	user, err := repository.GetUserByLogin(parts[2])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Пользователь %s не найден", parts[2]))
+	}
+	loc, err := schedule.Location()
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка: %v", err))
+	}
+
+	now := time.Now().In(loc)
+	from, err := parseOnCallTime(parts[3], now)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка: %v", err))
+	}
+	to, err := parseOnCallTime(parts[4], from)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка: %v", err))
+	}
+	if !to.After(from) {
+		return sendError(c, "Конец подмены должен быть позже начала")
+	}
+	if !to.After(now) {
+		return sendError(c, "Подмена уже закончилась")
+	}
+
+	override := &models.OnCallOverride{
+		ScheduleID: schedule.ID,
+		UserID:     user.ID,
+		StartsAt:   from,
+		EndsAt:     to,
+		Reason:     strings.Join(parts[5:], " "),
+	}
+	if err := repository.CreateOnCallOverride(override); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка сохранения подмены: %v", err))
+	}
+	return c.Send(fmt.Sprintf("В %s дежурит %s с %s по %s", schedule.Label, user.Login,
+		from.In(loc).Format(onCallTimeLayout), to.In(loc).Format(onCallTimeLayout)))
+}
+
+// parseOnCallTime разбирает момент времени: now, смещение +Nd/+Nh относительно base
+// или дату в часовом поясе base
+func parseOnCallTime(value string, base time.Time) (time.Time, error) {
+	if value == "now" {
+		return base, nil
+	}
+	if offset, ok := strings.CutPrefix(value, "+"); ok {
+		if days, err := strconv.Atoi(strings.TrimSuffix(offset, "d")); err == nil && strings.HasSuffix(offset, "d") {
+			return base.AddDate(0, 0, days), nil
+		}
+		if d, err := time.ParseDuration(offset); err == nil {
+			return base.Add(d), nil
+		}
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, base.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("не удалось разобрать время %q", value)
+}
+
+func formatOnCall(schedule *models.OnCallSchedule, now time.Time) string {
+	loc, err := schedule.Location()
+	if err != nil {
+		return fmt.Sprintf("📅 %s: ошибка расписания: %v", schedule.Label, err)
+	}
+
+	var sb strings.Builder
+	handoff := schedule.HandoffTime
+	if schedule.Rotation == models.RotationWeekly {
+		handoff = fmt.Sprintf("%s %s", strings.ToLower(time.Weekday(schedule.HandoffWeekday).String()[:3]), handoff)
+	}
+	sb.WriteString(fmt.Sprintf("📅 %s (%s, передача %s %s)\n", schedule.Label, schedule.Rotation, handoff, schedule.Timezone))
+	sb.WriteString(fmt.Sprintf("🔄 Ротация: %s\n", strings.Join(schedule.MemberList(), " → ")))
+
+	shift, err := schedule.ShiftAt(now)
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("Ошибка расчета смены: %v", err))
+		return sb.String()
+	}
+	if user, err := repository.GetOnCallUser(schedule, now); err != nil {
+		sb.WriteString(fmt.Sprintf("👤 Сейчас: ошибка: %v\n", err))
+	} else {
+		sb.WriteString(fmt.Sprintf("👤 Сейчас: @%s до %s\n", user.Login, shift.End.In(loc).Format(onCallTimeLayout)))
+	}
+	if user, err := repository.GetOnCallUser(schedule, shift.End); err == nil {
+		sb.WriteString(fmt.Sprintf("⏭ Далее: @%s\n", user.Login))
+	}
+
+	overrides, err := repository.GetUpcomingOnCallOverrides(schedule.ID, now)
+	if err == nil && len(overrides) > 0 {
+		sb.WriteString("🔁 Подмены:\n")
+		for _, o := range overrides {
+			line := fmt.Sprintf("- @%s %s — %s", o.User.Login, o.StartsAt.In(loc).Format(onCallTimeLayout), o.EndsAt.In(loc).Format(onCallTimeLayout))
+			if o.Reason != "" {
+				line += fmt.Sprintf(" (%s)", o.Reason)
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"chatops/internal/monitoring"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+const (
+	maxPromQLMessages  = 5    // сколько сообщений с результатом отправляется за один запрос
+	maxPromQLMessage   = 3800 // запас до лимита Telegram в 4096 символов
+	maxPromQLCellWidth = 48
+)
+
+// PromQLLimits - ограничения на произвольные PromQL-запросы из чата
+type PromQLLimits struct {
+	Timeout    time.Duration // таймаут вычисления запроса в Prometheus
+	MaxSeries  int           // максимум рядов в результате
+	MaxSamples int64         // максимум загруженных сэмплов по статистике Prometheus, 0 - без проверки
+}
+
+var GlobalPromQLLimits = PromQLLimits{
+	Timeout:    15 * time.Second,
+	MaxSeries:  200,
+	MaxSamples: 5000000,
+}
+
+// SetPromQLLimits задает ограничения для /promql
+func SetPromQLLimits(limits PromQLLimits) {
+	GlobalPromQLLimits = limits
+}
+
+// metric
+func PromQLHandler(c telebot.Context) error {
+	text := strings.TrimSpace(c.Text())
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return sendError(c, "Использование: /promql <выражение>\nНапример: /promql sum(rate(http_requests_total[5m])) by (job)")
+	}
+	expr := strings.TrimSpace(strings.TrimPrefix(text, fields[0]))
+
+	limits := GlobalPromQLLimits
+	// запас на сетевые задержки сверх таймаута вычисления в Prometheus
+	ctx, cancel := context.WithTimeout(context.Background(), limits.Timeout+5*time.Second)
+	defer cancel()
+
+	resp, err := GlobalMonitorClient.QueryWithOptions(ctx, expr, monitoring.QueryOptions{
+		Timeout: limits.Timeout,
+		Limit:   limits.MaxSeries + 1,
+		Stats:   limits.MaxSamples > 0,
+	})
+	if err != nil {
+		var promErr *monitoring.PrometheusError
+		switch {
+		case ctx.Err() == context.DeadlineExceeded, errors.As(err, &promErr) && promErr.Type == "timeout":
+			return sendError(c, fmt.Sprintf("Превышено время ожидания запроса (%s)", limits.Timeout))
+		case errors.As(err, &promErr):
+			return sendError(c, fmt.Sprintf("Prometheus отклонил запрос (%s): %s", promErr.Type, promErr.Message))
+		}
+		return sendError(c, fmt.Sprintf("Произошла ошибка: %v", err))
+	}
+
+	if stats := resp.Data.Stats; stats != nil && limits.MaxSamples > 0 && stats.Samples.TotalQueryableSamples > limits.MaxSamples {
+		return sendError(c, fmt.Sprintf("Запрос слишком тяжелый: обработано %d сэмплов при лимите %d. Сузьте селектор или интервал",
+			stats.Samples.TotalQueryableSamples, limits.MaxSamples))
+	}
+	if n := resp.Data.Len(); n > limits.MaxSeries {
+		return sendError(c, fmt.Sprintf("Запрос вернул больше %d рядов. Добавьте фильтры по меткам или агрегацию (sum by, topk)", limits.MaxSeries))
+	}
+	if resp.Data.Len() == 0 {
+		return c.Send("Пустой результат")
+	}
+
+	header, columns, rows := promQLTable(resp.Data)
+	for _, warning := range resp.Warnings {
+		header += "\n⚠️ " + warning
+	}
+	for _, msg := range paginateTable(header, renderTable(columns, rows)) {
+		if err := c.Send(msg, telebot.ModeMarkdownV2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// promQLTable раскладывает результат запроса в таблицу. Метки с одинаковым значением
+// во всех рядах выносятся в заголовок, чтобы не повторять их в каждой строке.
+func promQLTable(result monitoring.QueryResult) (string, []string, [][]string) {
+	switch result.ResultType {
+	case monitoring.ResultTypeScalar:
+		return "scalar", []string{"value"}, [][]string{{formatSample(result.Scalar.Value)}}
+	case monitoring.ResultTypeString:
+		return "string", []string{"value"}, [][]string{{result.String.Value}}
+	}
+
+	var metrics []map[string]string
+	var values [][]string
+	if result.ResultType == monitoring.ResultTypeMatrix {
+		for _, s := range result.Matrix {
+			metrics = append(metrics, s.Metric)
+			last := "-"
+			if len(s.Points) > 0 {
+				last = formatSample(s.Points[len(s.Points)-1].Value)
+			}
+			values = append(values, []string{strconv.Itoa(len(s.Points)), last})
+		}
+	} else {
+		for _, s := range result.Vector {
+			metrics = append(metrics, s.Metric)
+			values = append(values, []string{formatSample(s.Value)})
+		}
+	}
+
+	common, varying := splitLabels(metrics)
+	header := fmt.Sprintf("%s, рядов: %d", result.ResultType, len(metrics))
+	if len(common) > 0 {
+		header += "\n" + strings.Join(common, ", ")
+	}
+
+	columns := append([]string(nil), varying...)
+	if result.ResultType == monitoring.ResultTypeMatrix {
+		columns = append(columns, "points", "last")
+	} else {
+		columns = append(columns, "value")
+	}
+	rows := make([][]string, 0, len(metrics))
+	for i, metric := range metrics {
+		row := make([]string, 0, len(columns))
+		for _, label := range varying {
+			row = append(row, metric[label])
+		}
+		rows = append(rows, append(row, values[i]...))
+	}
+	sort.SliceStable(rows, func(a, b int) bool { return strings.Join(rows[a], "\x00") < strings.Join(rows[b], "\x00") })
+	return header, columns, rows
+}
+
+// splitLabels делит метки на общие для всех рядов (в виде name="value") и различающиеся
+func splitLabels(metrics []map[string]string) ([]string, []string) {
+	names := make(map[string]struct{})
+	for _, m := range metrics {
+		for name := range m {
+			names[name] = struct{}{}
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var common, varying []string
+	for _, name := range sorted {
+		value, same := metrics[0][name]
+		for _, m := range metrics[1:] {
+			if v, ok := m[name]; !ok || v != value {
+				same = false
+				break
+			}
+		}
+		if same && len(metrics) > 1 {
+			common = append(common, fmt.Sprintf("%s=%q", name, value))
+		} else {
+			varying = append(varying, name)
+		}
+	}
+	return common, varying
+}
+
+func formatSample(v float64) string {
+	return strconv.FormatFloat(v, 'g', 10, 64)
+}
+
+// renderTable выравнивает столбцы пробелами; слишком длинные значения обрезаются
+func renderTable(columns []string, rows [][]string) []string {
+	widths := make([]int, len(columns))
+	cell := func(s string) string {
+		if utf8.RuneCountInString(s) > maxPromQLCellWidth {
+			return string([]rune(s)[:maxPromQLCellWidth-1]) + "…"
+		}
+		return s
+	}
+	for i, col := range columns {
+		widths[i] = utf8.RuneCountInString(col)
+	}
+	for _, row := range rows {
+		for i, v := range row {
+			if n := utf8.RuneCountInString(cell(v)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	line := func(values []string) string {
+		var sb strings.Builder
+		for i, v := range values {
+			v = cell(v)
+			sb.WriteString(v)
+			if i < len(values)-1 {
+				sb.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(v)+2))
+			}
+		}
+		return sb.String()
+	}
+
+	lines := []string{line(columns)}
+	for _, row := range rows {
+		lines = append(lines, line(row))
+	}
+	return lines
+}
+
+// paginateTable разбивает таблицу на сообщения MarkdownV2 с блоком кода; строка заголовков
+// таблицы повторяется на каждой странице. Отправляется не больше maxPromQLMessages сообщений.
+func paginateTable(header string, lines []string) []string {
+	head, body := lines[0], lines[1:]
+	limit := maxPromQLMessage - len(escapeMarkdown(header)) - len(escapeCode(head))
+	var pages [][]string
+	var page []string
+	size := 0
+	for _, l := range body {
+		n := len(escapeCode(l)) + 1
+		if len(page) > 0 && size+n > limit {
+			pages = append(pages, page)
+			page, size = nil, 0
+		}
+		page = append(page, l)
+		size += n
+	}
+	pages = append(pages, page)
+
+	shown := len(pages)
+	if shown > maxPromQLMessages {
+		shown = maxPromQLMessages
+	}
+	messages := make([]string, 0, shown)
+	for i := 0; i < shown; i++ {
+		var sb strings.Builder
+		sb.WriteString(escapeMarkdown(header))
+		if len(pages) > 1 {
+			sb.WriteString(escapeMarkdown(fmt.Sprintf("\nстраница %d из %d", i+1, len(pages))))
+		}
+		sb.WriteString("\n```\n")
+		sb.WriteString(escapeCode(head + "\n" + strings.Join(pages[i], "\n")))
+		sb.WriteString("\n```")
+		if i == shown-1 && shown < len(pages) {
+			rest := 0
+			for _, p := range pages[shown:] {
+				rest += len(p)
+			}
+			sb.WriteString(escapeMarkdown(fmt.Sprintf("\nЕще %d строк не показано, уточните запрос", rest)))
+		}
+		messages = append(messages, sb.String())
+	}
+	return messages
+}
+
+// escapeCode экранирует символы, которые нельзя оставлять внутри блока кода MarkdownV2
+func escapeCode(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`").Replace(s)
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"chatops/internal/app"
+	"chatops/internal/bot/auth"
+	"chatops/internal/db/models"
+	"chatops/internal/monitoring"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+// silenceButtonDuration - длительность тишины, создаваемой кнопкой в уведомлении
+const silenceButtonDuration = time.Hour
+
+// operator
+func SilenceHandler(c telebot.Context) error {
+	// Матчеры отделяются от исходного текста с учетом кавычек: alertname="Disk full"
+	_, args, _ := strings.Cut(strings.TrimSpace(c.Text()), " ")
+	expr, rest := monitoring.CutMatchers(args)
+	parts := strings.Fields(rest)
+	if expr == "" || len(parts) < 2 {
+		return sendError(c, "Использование: /silence <матчеры> <длительность> <комментарий>\n"+
+			"Пример: /silence alertname=PodCrash,namespace=payments 2h плановые работы\n"+
+			`Значения с пробелами - в кавычках: alertname="Disk full"`)
+	}
+	matchers, err := monitoring.ParseMatchers(expr)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка: %v", err))
+	}
+	duration, err := parseDuration(parts[0])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка: %v", err))
+	}
+	if !authorizeSilence(c, matchers, "") {
+		return nil
+	}
+
+	id, err := createSilence(c, matchers, duration, strings.Join(parts[1:], " "))
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка создания тишины: %v", err))
+	}
+	return c.Send(fmt.Sprintf("🔕 Тишина %s создана на %s\nМатчеры: %s", id, duration, formatMatchers(matchers)))
+}
+
+// db
+func SilencesHandler(c telebot.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	silences, err := GlobalMonitorClient.ListSilences(ctx)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения тишин: %v", err))
+	}
+
+	var current []monitoring.Silence
+	for _, s := range silences {
+		if s.State() != monitoring.SilenceExpired {
+			current = append(current, s)
+		}
+	}
+	if len(current) == 0 {
+		return c.Send("Активных тишин нет")
+	}
+	sort.Slice(current, func(i, j int) bool { return current[i].EndsAt.Before(current[j].EndsAt) })
+
+	var sb strings.Builder
+	sb.WriteString("🔕 Тишины:\n")
+	for _, s := range current {
+		sb.WriteString(fmt.Sprintf("\n%s [%s]\n", s.ID, s.State()))
+		sb.WriteString(fmt.Sprintf("  %s\n", formatMatchers(s.Matchers)))
+		sb.WriteString(fmt.Sprintf("  до %s (осталось %s), автор %s\n", s.EndsAt.Local().Format("2006-01-02 15:04"),
+			time.Until(s.EndsAt).Round(time.Minute), s.CreatedBy))
+		if s.Comment != "" {
+			sb.WriteString(fmt.Sprintf("  💬 %s\n", s.Comment))
+		}
+	}
+	return c.Send(sb.String())
+}
+
+// operator
+func UnsilenceHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) != 2 {
+		return sendError(c, "Использование: /unsilence <id>")
+	}
+	id := parts[1]
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	silences, err := GlobalMonitorClient.ListSilences(ctx)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения тишин: %v", err))
+	}
+	var silence *monitoring.Silence
+	for i := range silences {
+		if silences[i].ID == id {
+			silence = &silences[i]
+			break
+		}
+	}
+	if silence == nil {
+		return sendError(c, fmt.Sprintf("Тишина %s не найдена", id))
+	}
+	if silence.State() == monitoring.SilenceExpired {
+		return sendError(c, fmt.Sprintf("Тишина %s уже истекла", id))
+	}
+	if !authorizeSilence(c, silence.Matchers, "silence/"+id) {
+		return nil
+	}
+
+	if err := GlobalMonitorClient.ExpireSilence(ctx, id); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка снятия тишины: %v", err))
+	}
+	return c.Send(fmt.Sprintf("🔔 Тишина %s снята", id))
+}
+
+// AlertSilenceHandler обрабатывает кнопку "Тишина 1ч" в уведомлении об алерте:
+// создает тишину на точное совпадение всех меток алерта
+func AlertSilenceHandler(c telebot.Context) error {
+	if GlobalSessionStore == nil {
+		return c.Respond(&telebot.CallbackResponse{Text: "Авторизация недоступна"})
+	}
+	session, ok := GlobalSessionStore.Get(c.Sender().ID)
+	if !ok {
+		return c.Respond(&telebot.CallbackResponse{Text: "Вы не авторизованы. Введите /start для авторизации.", ShowAlert: true})
+	}
+	if !auth.HasRole(session.Role, models.RoleOperator) {
+		return c.Respond(&telebot.CallbackResponse{Text: "Недостаточно прав", ShowAlert: true})
+	}
+	auth.WithSession(c, session)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	fingerprint := c.Callback().Data
+	alerts, err := GlobalMonitorClient.GetActiveAlerts(ctx, monitoring.AlertFilter{})
+	if err != nil {
+		log.Printf("Error loading alerts for silence %s: %v", fingerprint, err)
+		return c.Respond(&telebot.CallbackResponse{Text: "Не удалось получить алерты"})
+	}
+	var alert *monitoring.Alert
+	for i := range alerts {
+		if app.AlertFingerprint(alerts[i]) == fingerprint {
+			alert = &alerts[i]
+			break
+		}
+	}
+	if alert == nil {
+		return c.Respond(&telebot.CallbackResponse{Text: "Алерт уже не активен"})
+	}
+
+	matchers := monitoring.MatchersForLabels(alert.Labels)
+	startOperation(c, session, "/silence", []string{formatMatchers(matchers), silenceButtonDuration.String()})
+	if !authorizeSilence(c, matchers, "") {
+		FinishAudit(c, models.ConfirmationSkipped, nil)
+		return c.Respond()
+	}
+
+	id, err := createSilence(c, matchers, silenceButtonDuration, "Заглушено из уведомления об алерте")
+	FinishAudit(c, models.ConfirmationSkipped, err)
+	if err != nil {
+		log.Printf("Error creating silence for alert %s: %v", fingerprint, err)
+		return c.Respond(&telebot.CallbackResponse{Text: "Ошибка создания тишины"})
+	}
+
+	c.Respond(&telebot.CallbackResponse{Text: "Алерт заглушен на 1 час"})
+	if err := c.Edit(c.Message().Text + fmt.Sprintf("\n\n🔕 %s заглушил на %s (тишина %s)", session.Login, silenceButtonDuration, id)); err != nil {
+		log.Printf("Error updating silenced message: %v", err)
+	}
+	return nil
+}
+
+// authorizeSilence проверяет право создавать или снимать тишину с указанными матчерами:
+// тишина по namespace требует доступа к нему, тишина без namespace доступна только администратору
+func authorizeSilence(c telebot.Context, matchers []monitoring.Matcher, resource string) bool {
+	for _, m := range matchers {
+		if m.Name == "namespace" && m.IsEqual && !m.IsRegex {
+			allowed := authorizeResource(c, "silence", "", m.Value, "")
+			setAuditTarget(c, m.Value, resource)
+			return allowed
+		}
+	}
+
+	setAuditTarget(c, "", resource)
+	session := auth.SessionFromContext(c)
+	if session != nil && session.Role == models.RoleAdmin {
+		return true
+	}
+	markAuditDenied(c, "тишина без namespace")
+	c.Send("⛔ Тишина без матчера namespace=<имя> затрагивает все namespace и доступна только администратору")
+	return false
+}
+
+func createSilence(c telebot.Context, matchers []monitoring.Matcher, duration time.Duration, comment string) (string, error) {
+	createdBy := "chatops"
+	if session := auth.SessionFromContext(c); session != nil {
+		createdBy = session.Login
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	id, err := GlobalMonitorClient.CreateSilence(ctx, monitoring.Silence{
+		Matchers:  matchers,
+		StartsAt:  now,
+		EndsAt:    now.Add(duration),
+		CreatedBy: createdBy,
+		Comment:   comment,
+	})
+	if err != nil {
+		return "", err
+	}
+	if operation, _ := c.Get(operationKey).(*models.Operation); operation != nil {
+		operation.Resource = "silence/" + id
+	}
+	log.Printf("Silence %s created by %s for %s", id, createdBy, duration)
+	return id, nil
+}
+
+// parseDuration разбирает длительность в формате Go (30m, 2h) или в днях (3d)
+func parseDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("некорректная длительность %q", value)
+	}
+	return d, nil
+}
+
+func formatMatchers(matchers []monitoring.Matcher) string {
+	parts := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		parts = append(parts, m.String())
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
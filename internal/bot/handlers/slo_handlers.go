@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"chatops/internal/app"
+	"chatops/internal/db/models"
+	"chatops/internal/db/repository"
+	"chatops/internal/monitoring"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+// metric
+func SLOHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) > 2 {
+		return sendError(c, "Использование: /slo [сервис]")
+	}
+	if len(parts) == 1 {
+		slos, err := repository.GetSLOs()
+		if err != nil {
+			return sendError(c, fmt.Sprintf("Ошибка получения SLO: %v", err))
+		}
+		if len(slos) == 0 {
+			return c.Send("SLO не заданы. Добавьте SLO: /slo_set")
+		}
+		var sb strings.Builder
+		sb.WriteString("SLO:\n")
+		for _, s := range slos {
+			slo := app.SLOFromModel(s)
+			sb.WriteString(fmt.Sprintf("• %s/%s %s (%s): %s за %s\n", s.Namespace, s.Service, s.Name, s.Kind,
+				app.FormatRatio(slo.Target), monitoring.PromDuration(slo.Window)))
+		}
+		return c.Send(truncateText(sb.String(), maxDashboardText))
+	}
+
+	service := parts[1]
+	slos, err := repository.GetServiceSLOs(service)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения SLO: %v", err))
+	}
+	if len(slos) == 0 {
+		return c.Send(fmt.Sprintf("Для сервиса %s SLO не заданы, список: /slo", service))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🎯 SLO сервиса %s\n", service))
+	for _, s := range slos {
+		sb.WriteString("\n")
+		status, err := GlobalMonitorClient.EvaluateSLO(ctx, app.SLOFromModel(s), monitoring.DefaultBurnRateRules)
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("❌ %s: %v\n", s.Name, err))
+			continue
+		}
+		sb.WriteString(formatSLOStatus(status))
+	}
+	return c.Send(truncateText(sb.String(), maxDashboardText))
+}
+
+// operator
+func SLOSetHandler(c telebot.Context) error {
+	text := strings.TrimSpace(c.Text())
+	fields := strings.Fields(text)
+	if len(fields) < 7 {
+		return sendError(c, "Использование: /slo_set <[namespace/]сервис> <имя> <availability|latency> <цель, %> <окно, дней> <запрос>\n"+
+			"Запрос возвращает долю хороших событий за окно {{window}}, доступны также {{service}} и {{namespace}}\n"+
+			`Например: /slo_set prod/api availability availability 99.9 30 sum(rate(http_requests_total{job="{{service}}", code!~"5.."}[{{window}}])) / sum(rate(http_requests_total{job="{{service}}"}[{{window}}]))`)
+	}
+
+	namespace, service := "default", fields[1]
+	if ns, svc, ok := strings.Cut(fields[1], "/"); ok {
+		namespace, service = ns, svc
+	}
+	if namespace == "" || service == "" {
+		return sendError(c, "Сервис должен иметь вид сервис или namespace/сервис")
+	}
+	name := fields[2]
+	if !monitoring.ValidCatalogName(name) {
+		return sendError(c, "Имя SLO может содержать только латиницу, цифры, _ и -")
+	}
+	target, err := strconv.ParseFloat(strings.TrimSuffix(fields[4], "%"), 64)
+	if err != nil {
+		return sendError(c, "Ошибка при чтении цели в процентах")
+	}
+	days, err := strconv.Atoi(fields[5])
+	if err != nil || days <= 0 {
+		return sendError(c, "Ошибка при чтении окна в днях")
+	}
+	// запрос - остаток сообщения после первых шести полей, пробелы внутри сохраняются
+	query := text
+	for _, field := range fields[:6] {
+		query = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(query), field))
+	}
+
+	slo := &models.SLO{
+		Service:    service,
+		Name:       name,
+		Namespace:  namespace,
+		Kind:       fields[3],
+		Query:      query,
+		Target:     target / 100,
+		WindowDays: days,
+	}
+	if err := app.SLOFromModel(*slo).Validate(); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка в SLO: %v", err))
+	}
+	if err := repository.SaveSLO(slo); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка сохранения SLO: %v", err))
+	}
+	return c.Send(fmt.Sprintf("SLO %s/%s %s: %s за %d дн.", namespace, service, name, app.FormatRatio(slo.Target), days))
+}
+
+// operator
+func SLODeleteHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) != 3 {
+		return sendError(c, "Использование: /slo_del <сервис> <имя>")
+	}
+	removed, err := repository.DeleteSLO(parts[1], parts[2])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка удаления SLO: %v", err))
+	}
+	if removed == 0 {
+		return sendError(c, fmt.Sprintf("SLO %s сервиса %s не найден", parts[2], parts[1]))
+	}
+	return c.Send(fmt.Sprintf("SLO %s сервиса %s удален", parts[2], parts[1]))
+}
+
+func formatSLOStatus(status *monitoring.SLOStatus) string {
+	var sb strings.Builder
+	slo := status.SLO
+	icon := "✅"
+	switch {
+	case !monitoring.IsFinite(status.BudgetRemaining):
+		icon = "🤷"
+	case status.BudgetRemaining <= 0:
+		icon = "❌"
+	case len(status.Firing) > 0:
+		icon = "🔥"
+	}
+
+	sb.WriteString(fmt.Sprintf("%s %s (%s): цель %s за %s\n", icon, slo.Name, slo.Kind,
+		app.FormatRatio(slo.Target), monitoring.PromDuration(slo.Window)))
+	sb.WriteString(fmt.Sprintf("  Выполнение: %s\n", app.FormatRatio(status.Attainment)))
+	sb.WriteString(fmt.Sprintf("  Остаток бюджета ошибок: %s\n", app.FormatRatio(status.BudgetRemaining)))
+
+	var rates []string
+	seen := make(map[time.Duration]bool)
+	for _, rule := range monitoring.DefaultBurnRateRules {
+		if seen[rule.Long] {
+			continue
+		}
+		seen[rule.Long] = true
+		rate := status.BurnRates[rule.Long]
+		if monitoring.IsFinite(rate) {
+			rates = append(rates, fmt.Sprintf("%s ×%.2f", monitoring.PromDuration(rule.Long), rate))
+		} else {
+			rates = append(rates, fmt.Sprintf("%s нет данных", monitoring.PromDuration(rule.Long)))
+		}
+	}
+	sb.WriteString("  Скорость расхода: " + strings.Join(rates, ", ") + "\n")
+	for _, rule := range status.Firing {
+		sb.WriteString(fmt.Sprintf("  🔥 %s: за %s и %s быстрее ×%.1f\n", rule.Severity,
+			monitoring.PromDuration(rule.Long), monitoring.PromDuration(rule.Short), rule.Threshold(slo.Window)))
+	}
+	return sb.String()
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"chatops/internal/bot/auth"
+	"chatops/internal/db/models"
+	"chatops/internal/db/repository"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+var GlobalSessionStore *auth.SessionStore
+
+// SetSessionStore sets the global session store for handlers
+func SetSessionStore(store *auth.SessionStore) {
+	GlobalSessionStore = store
+}
+
+// admin
+func UserAddHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) < 3 {
+		return sendError(c, "Использование: /user_add <логин> <пароль> [роль] [должность]")
+	}
+	login := parts[1]
+	password := parts[2]
+	role := models.RoleViewer
+	if len(parts) > 3 {
+		role = parts[3]
+	}
+	if !auth.ValidRole(role) {
+		return sendError(c, fmt.Sprintf("Неизвестная роль: %s (допустимо: viewer, operator, admin)", role))
+	}
+	jobStatus := "engineer"
+	if len(parts) > 4 {
+		jobStatus = strings.Join(parts[4:], " ")
+	}
+
+	if _, err := repository.GetUserByLogin(login); err == nil {
+		return c.Send(fmt.Sprintf("Пользователь %s уже существует", login))
+	}
+
+	user, err := repository.CreateUserWithRole(login, password, jobStatus, role)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка создания пользователя: %v", err))
+	}
+	return c.Send(fmt.Sprintf("Пользователь %s создан (ID: %d, роль: %s)", user.Login, user.ID, user.Role))
+}
+
+// admin
+func UserPasswordHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) != 3 {
+		return sendError(c, "Использование: /user_passwd <логин> <новый пароль>")
+	}
+
+	user, err := repository.GetUserByLogin(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Пользователь %s не найден", parts[1]))
+	}
+	if err := repository.SetUserPassword(user.ID, parts[2]); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка смены пароля: %v", err))
+	}
+	if GlobalSessionStore != nil {
+		GlobalSessionStore.DeleteByUserID(user.ID)
+	}
+	return c.Send(fmt.Sprintf("Пароль пользователя %s изменен, активные сессии завершены", user.Login))
+}
+
+// admin
+func UserDisableHandler(c telebot.Context) error {
+	return setUserDisabled(c, true)
+}
+
+// admin
+func UserEnableHandler(c telebot.Context) error {
+	return setUserDisabled(c, false)
+}
+
+func setUserDisabled(c telebot.Context, disabled bool) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) != 2 {
+		return sendError(c, "Неправильное кол-во параметров, укажите логин пользователя")
+	}
+
+	user, err := repository.GetUserByLogin(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Пользователь %s не найден", parts[1]))
+	}
+	if session := auth.SessionFromContext(c); disabled && session != nil && session.UserID == user.ID {
+		return sendError(c, "Нельзя отключить собственную учетную запись")
+	}
+	if err := repository.SetUserDisabled(user.ID, disabled); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка обновления пользователя: %v", err))
+	}
+
+	if !disabled {
+		return c.Send(fmt.Sprintf("Учетная запись %s включена", user.Login))
+	}
+	if GlobalSessionStore != nil {
+		GlobalSessionStore.DeleteByUserID(user.ID)
+	}
+	return c.Send(fmt.Sprintf("Учетная запись %s отключена, активные сессии завершены", user.Login))
+}
+
+// admin
+func GrantHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) != 3 {
+		return sendError(c, "Использование: /grant <логин> <ns=namespace|deploy=namespace/name|sts=namespace/name|ds=namespace/name>")
+	}
+	if err := auth.ValidateScope(parts[2]); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка: %v", err))
+	}
+
+	user, err := repository.GetUserByLogin(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Пользователь %s не найден", parts[1]))
+	}
+	if _, err := repository.CreateUserGrant(user.ID, parts[2]); err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка выдачи права: %v", err))
+	}
+	return c.Send(fmt.Sprintf("Пользователю %s выдано право %s", user.Login, parts[2]))
+}
+
+// admin
+func RevokeHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) != 3 {
+		return sendError(c, "Использование: /revoke <логин> <ns=namespace|deploy=namespace/name|sts=namespace/name|ds=namespace/name>")
+	}
+
+	user, err := repository.GetUserByLogin(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Пользователь %s не найден", parts[1]))
+	}
+	removed, err := repository.DeleteUserGrant(user.ID, parts[2])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка отзыва права: %v", err))
+	}
+	if removed == 0 {
+		return c.Send(fmt.Sprintf("У пользователя %s нет права %s", user.Login, parts[2]))
+	}
+	return c.Send(fmt.Sprintf("У пользователя %s отозвано право %s", user.Login, parts[2]))
+}
+
+// admin
+func GrantsHandler(c telebot.Context) error {
+	parts := strings.Fields(c.Text())
+	if len(parts) != 2 {
+		return sendError(c, "Использование: /grants <логин>")
+	}
+
+	user, err := repository.GetUserByLogin(parts[1])
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Пользователь %s не найден", parts[1]))
+	}
+	scopes, err := repository.GetUserGrants(user.ID)
+	if err != nil {
+		return sendError(c, fmt.Sprintf("Ошибка получения прав: %v", err))
+	}
+	if len(scopes) == 0 {
+		return c.Send(fmt.Sprintf("У пользователя %s (роль %s) нет прав на ресурсы", user.Login, user.Role))
+	}
+	return c.Send(fmt.Sprintf("Права пользователя %s (роль %s):\n%s", user.Login, user.Role, strings.Join(scopes, "\n")))
+}
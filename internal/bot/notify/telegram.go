@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+// AckButtonUnique - идентификатор inline-кнопки подтверждения алерта
+const AckButtonUnique = "alert_ack"
+
+// AckButton возвращает кнопку подтверждения; Data содержит fingerprint алерта
+func AckButton(ackKey string) telebot.InlineButton {
+	return telebot.InlineButton{
+		Unique: AckButtonUnique,
+		Text:   "✅ Подтвердить",
+		Data:   ackKey,
+	}
+}
+
+// SilenceButtonUnique - идентификатор inline-кнопки, заглушающей алерт на час
+const SilenceButtonUnique = "alert_silence"
+
+// SilenceButton возвращает кнопку создания тишины на 1 час; Data содержит fingerprint алерта
+func SilenceButton(fingerprint string) telebot.InlineButton {
+	return telebot.InlineButton{
+		Unique: SilenceButtonUnique,
+		Text:   "🔕 Тишина 1ч",
+		Data:   fingerprint,
+	}
+}
+
+// TelegramNotifier отправляет уведомления через Telegram-бота
+type TelegramNotifier struct {
+	bot *telebot.Bot
+}
+
+func NewTelegramNotifier(bot *telebot.Bot) *TelegramNotifier {
+	return &TelegramNotifier{bot: bot}
+}
+
+// Send отправляет текст в чат chatID
+func (n *TelegramNotifier) Send(ctx context.Context, chatID int64, text string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := n.bot.Send(&telebot.Chat{ID: chatID}, text)
+	return err
+}
+
+// SendPhoto отправляет изображение PNG с подписью
+func (n *TelegramNotifier) SendPhoto(ctx context.Context, chatID int64, photo []byte, caption string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := n.bot.Send(&telebot.Chat{ID: chatID}, &telebot.Photo{
+		File:    telebot.FromReader(bytes.NewReader(photo)),
+		Caption: caption,
+	})
+	return err
+}
+
+// SendWithAck отправляет текст с кнопками подтверждения и заглушения алерта
+func (n *TelegramNotifier) SendWithAck(ctx context.Context, chatID int64, text, ackKey string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := n.bot.Send(&telebot.Chat{ID: chatID}, text, &telebot.ReplyMarkup{
+		InlineKeyboard: [][]telebot.InlineButton{{AckButton(ackKey), SilenceButton(ackKey)}},
+	})
+	return err
+}
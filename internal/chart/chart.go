@@ -0,0 +1,319 @@
+// Package chart рисует линейные графики временных рядов Prometheus в PNG без внешних зависимостей
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"chatops/internal/monitoring"
+)
+
+const (
+	width      = 960
+	height     = 480
+	textScale  = 2
+	maxLegend  = 8
+	lineHeight = (glyphHeight + 3) * textScale
+)
+
+// ErrNoData возвращается, если в рядах нет ни одного конечного значения
+var ErrNoData = errors.New("no data points to plot")
+
+var (
+	background = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	axisColor  = color.RGBA{0x33, 0x33, 0x33, 0xFF}
+	gridColor  = color.RGBA{0xE0, 0xE0, 0xE0, 0xFF}
+	palette    = []color.RGBA{
+		{0x1F, 0x77, 0xB4, 0xFF},
+		{0xFF, 0x7F, 0x0E, 0xFF},
+		{0x2C, 0xA0, 0x2C, 0xFF},
+		{0xD6, 0x27, 0x28, 0xFF},
+		{0x94, 0x67, 0xBD, 0xFF},
+		{0x8C, 0x56, 0x4B, 0xFF},
+		{0xE3, 0x77, 0xC2, 0xFF},
+		{0x17, 0xBE, 0xCF, 0xFF},
+	}
+)
+
+// Render рисует ряды на одном графике и возвращает PNG
+func Render(title string, series []monitoring.Series) ([]byte, error) {
+	var tMin, tMax time.Time
+	vMin, vMax := math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for _, p := range s.Points {
+			if !finite(p.Value) {
+				continue
+			}
+			if tMin.IsZero() || p.Time.Before(tMin) {
+				tMin = p.Time
+			}
+			if p.Time.After(tMax) {
+				tMax = p.Time
+			}
+			vMin = math.Min(vMin, p.Value)
+			vMax = math.Max(vMax, p.Value)
+		}
+	}
+	if tMin.IsZero() {
+		return nil, ErrNoData
+	}
+	if !tMax.After(tMin) {
+		tMin, tMax = tMin.Add(-time.Minute), tMax.Add(time.Minute)
+	}
+	if vMin == vMax {
+		pad := math.Max(math.Abs(vMin)*0.1, 1)
+		vMin, vMax = vMin-pad, vMax+pad
+	}
+	yTicks, yStep := niceTicks(vMin, vMax, 6)
+	vMin, vMax = yTicks[0], yTicks[len(yTicks)-1]
+
+	legend := len(series)
+	if legend > maxLegend {
+		legend = maxLegend + 1
+	}
+	plot := image.Rect(120, 20+lineHeight*2, width-30, height-40-lineHeight*((legend+1)/2))
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, 0, 0, width, height, background)
+
+	toX := func(t time.Time) int {
+		return plot.Min.X + int(math.Round(float64(t.Sub(tMin))/float64(tMax.Sub(tMin))*float64(plot.Dx())))
+	}
+	toY := func(v float64) int {
+		return plot.Max.Y - int(math.Round((v-vMin)/(vMax-vMin)*float64(plot.Dy())))
+	}
+
+	drawText(img, plot.Min.X, 14, truncate(title, (width-plot.Min.X)/(glyphAdvance*textScale)), axisColor, textScale)
+
+	for _, v := range yTicks {
+		y := toY(v)
+		drawLine(img, plot.Min.X, y, plot.Max.X, y, gridColor)
+		label := formatValue(v, yStep)
+		drawText(img, plot.Min.X-10-textWidth(label, textScale), y-glyphHeight*textScale/2, label, axisColor, textScale)
+	}
+	layout := "15:04"
+	if tMax.Sub(tMin) > 24*time.Hour {
+		layout = "01-02 15:04"
+	}
+	for _, t := range timeTicks(tMin, tMax, 8) {
+		x := toX(t)
+		drawLine(img, x, plot.Min.Y, x, plot.Max.Y, gridColor)
+		label := t.Local().Format(layout)
+		drawText(img, x-textWidth(label, textScale)/2, plot.Max.Y+10, label, axisColor, textScale)
+	}
+	drawLine(img, plot.Min.X, plot.Min.Y, plot.Min.X, plot.Max.Y, axisColor)
+	drawLine(img, plot.Min.X, plot.Max.Y, plot.Max.X, plot.Max.Y, axisColor)
+
+	for i, s := range series {
+		c := palette[i%len(palette)]
+		points := append([]monitoring.SamplePoint(nil), s.Points...)
+		sort.Slice(points, func(a, b int) bool { return points[a].Time.Before(points[b].Time) })
+
+		var prev *monitoring.SamplePoint
+		for j := range points {
+			p := &points[j]
+			if !finite(p.Value) {
+				prev = nil
+				continue
+			}
+			if prev == nil {
+				fillRect(img, toX(p.Time)-1, toY(p.Value)-1, 3, 3, c)
+			} else {
+				drawThickLine(img, toX(prev.Time), toY(prev.Value), toX(p.Time), toY(p.Value), c)
+			}
+			prev = p
+		}
+	}
+
+	legendTop := plot.Max.Y + 40
+	columnWidth := (width - plot.Min.X) / 2
+	maxChars := (columnWidth - 30) / (glyphAdvance * textScale)
+	for i := 0; i < legend; i++ {
+		x := plot.Min.X + (i%2)*columnWidth
+		y := legendTop + (i/2)*lineHeight
+		if i == maxLegend {
+			drawText(img, x, y, fmt.Sprintf("+%d more", len(series)-maxLegend), axisColor, textScale)
+			break
+		}
+		fillRect(img, x, y, glyphHeight*textScale, glyphHeight*textScale, palette[i%len(palette)])
+		drawText(img, x+glyphHeight*textScale+8, y, truncate(SeriesLabel(series[i].Metric), maxChars), axisColor, textScale)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SeriesLabel возвращает подпись ряда: pod или instance, если есть, иначе набор меток
+func SeriesLabel(metric map[string]string) string {
+	for _, key := range []string{"pod", "instance"} {
+		if v, ok := metric[key]; ok {
+			return v
+		}
+	}
+	keys := make([]string, 0, len(metric))
+	for key := range metric {
+		if key != "__name__" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%q", key, metric[key]))
+	}
+	return metric["__name__"] + "{" + strings.Join(parts, ",") + "}"
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+func truncate(text string, maxChars int) string {
+	runes := []rune(text)
+	if maxChars < 4 || len(runes) <= maxChars {
+		return text
+	}
+	return string(runes[:maxChars-3]) + "..."
+}
+
+// niceTicks подбирает "круглые" деления оси значений, покрывающие [lo, hi]
+func niceTicks(lo, hi float64, count int) ([]float64, float64) {
+	step := niceNum(niceNum(hi-lo, false)/float64(count-1), true)
+	start := math.Floor(lo/step) * step
+	end := math.Ceil(hi/step) * step
+	var ticks []float64
+	for i := 0; start+float64(i)*step <= end+step/2; i++ {
+		v := start + float64(i)*step
+		if math.Abs(v) < step*1e-9 {
+			v = 0
+		}
+		ticks = append(ticks, v)
+	}
+	return ticks, step
+}
+
+func niceNum(x float64, round bool) float64 {
+	exp := math.Floor(math.Log10(x))
+	f := x / math.Pow(10, exp)
+	var nf float64
+	switch {
+	case round && f < 1.5, !round && f <= 1:
+		nf = 1
+	case round && f < 3, !round && f <= 2:
+		nf = 2
+	case round && f < 7, !round && f <= 5:
+		nf = 5
+	default:
+		nf = 10
+	}
+	return nf * math.Pow(10, exp)
+}
+
+var timeSteps = []time.Duration{
+	time.Minute, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour,
+	24 * time.Hour, 48 * time.Hour, 7 * 24 * time.Hour,
+}
+
+// timeTicks подбирает не более count делений оси времени с "круглым" шагом
+func timeTicks(from, to time.Time, count int) []time.Time {
+	step := timeSteps[len(timeSteps)-1]
+	for _, s := range timeSteps {
+		if to.Sub(from)/s <= time.Duration(count) {
+			step = s
+			break
+		}
+	}
+	var ticks []time.Time
+	for t := from.Truncate(step); !t.After(to); t = t.Add(step) {
+		if !t.Before(from) {
+			ticks = append(ticks, t)
+		}
+	}
+	return ticks
+}
+
+// formatValue форматирует значение деления с точностью, соответствующей шагу
+func formatValue(v, step float64) string {
+	magnitude := math.Abs(v)
+	for _, unit := range []struct {
+		size   float64
+		suffix string
+	}{{1e12, "T"}, {1e9, "G"}, {1e6, "M"}, {1e3, "k"}} {
+		if magnitude >= unit.size {
+			return trimZeros(fmt.Sprintf("%.2f", v/unit.size)) + unit.suffix
+		}
+	}
+	decimals := 0
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step)))
+	}
+	return fmt.Sprintf("%.*f", decimals, v)
+}
+
+func trimZeros(s string) string {
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+func fillRect(img *image.RGBA, x, y, w, h int, c color.Color) {
+	for dy := 0; dy < h; dy++ {
+		for dx := 0; dx < w; dx++ {
+			img.Set(x+dx, y+dy, c)
+		}
+	}
+}
+
+// drawLine рисует отрезок алгоритмом Брезенхэма
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func drawThickLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	drawLine(img, x0, y0, x1, y1, c)
+	drawLine(img, x0, y0+1, x1, y1+1, c)
+	drawLine(img, x0+1, y0, x1+1, y1, c)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
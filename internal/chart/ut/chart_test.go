@@ -0,0 +1,75 @@
+package chart_test
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+	"time"
+
+	"chatops/internal/chart"
+	"chatops/internal/monitoring"
+)
+
+func sineSeries(pod string, phase float64) monitoring.Series {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := monitoring.Series{Metric: map[string]string{"__name__": "cpu", "pod": pod}}
+	for i := 0; i < 120; i++ {
+		value := 50 + 40*math.Sin(float64(i)/10+phase)
+		if i == 60 {
+			value = math.NaN()
+		}
+		s.Points = append(s.Points, monitoring.SamplePoint{Time: start.Add(time.Duration(i) * 30 * time.Second), Value: value})
+	}
+	return s
+}
+
+func TestRender(t *testing.T) {
+	data, err := chart.Render("container_cpu_usage_seconds_total", []monitoring.Series{sineSeries("api-1", 0), sineSeries("api-2", 1)})
+	if err != nil {
+		t.Fatalf("Render() returned an error: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Render() produced invalid PNG: %v", err)
+	}
+	if img.Bounds().Dx() != 960 || img.Bounds().Dy() != 480 {
+		t.Errorf("unexpected image size %v", img.Bounds())
+	}
+
+	// На графике должны быть пиксели цвета первого ряда
+	first := color.RGBA{0x1F, 0x77, 0xB4, 0xFF}
+	found := false
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y && !found; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			if color.RGBAModel.Convert(img.At(x, y)) == first {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		t.Error("series line not found in rendered image")
+	}
+}
+
+func TestRender_NoData(t *testing.T) {
+	series := []monitoring.Series{{Points: []monitoring.SamplePoint{{Time: time.Now(), Value: math.NaN()}}}}
+	if _, err := chart.Render("empty", series); !errors.Is(err, chart.ErrNoData) {
+		t.Errorf("expected ErrNoData, got %v", err)
+	}
+	if _, err := chart.Render("empty", nil); !errors.Is(err, chart.ErrNoData) {
+		t.Errorf("expected ErrNoData for no series, got %v", err)
+	}
+}
+
+func TestSeriesLabel(t *testing.T) {
+	if got := chart.SeriesLabel(map[string]string{"pod": "api-1", "job": "api"}); got != "api-1" {
+		t.Errorf("SeriesLabel() = %q, want api-1", got)
+	}
+	if got := chart.SeriesLabel(map[string]string{"__name__": "up", "job": "api"}); got != `up{job="api"}` {
+		t.Errorf("SeriesLabel() = %q", got)
+	}
+}
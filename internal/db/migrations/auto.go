@@ -9,5 +9,23 @@ func AutoMigrate() error {
 	if err := config.InitDB(); err != nil {
 		return err
 	}
-	return config.DB.AutoMigrate(&models.User{}, &models.UserLabel{}, &models.IncidentHistory{}, &models.Operation{})
+	return config.DB.AutoMigrate(
+		&models.User{},
+		&models.UserLabel{},
+		&models.IncidentHistory{},
+		&models.IncidentAlert{},
+		&models.IncidentEvent{},
+		&models.Operation{},
+		&models.UserGrant{},
+		&models.AlertNotification{},
+		&models.EscalationPolicy{},
+		&models.AlertEscalation{},
+		&models.OnCallSchedule{},
+		&models.OnCallOverride{},
+		&models.SavedQuery{},
+		&models.Dashboard{},
+		&models.SLO{},
+		&models.SLONotification{},
+		&models.EventSubscription{},
+	)
 }
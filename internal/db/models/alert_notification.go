@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// AlertNotification хранит состояние уведомлений по алерту,
+// чтобы не рассылать повторно уже отправленные уведомления после перезапуска бота
+type AlertNotification struct {
+	ID              uint      `gorm:"primaryKey"`
+	Fingerprint     string    `gorm:"not null;index"`
+	AlertName       string    `gorm:"not null"`
+	Labels          string    `gorm:"type:text"` // метки алерта в JSON
+	FirstNotifiedAt time.Time `gorm:"not null"`
+	LastNotifiedAt  time.Time `gorm:"not null"`
+	ResolvedAt      *time.Time
+}
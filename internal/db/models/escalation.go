@@ -0,0 +1,41 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// EscalationPolicy задает цепочку эскалации для алертов с меткой Label.
+// Tiers - цели через запятую в порядке эскалации:
+// "duty:<label>" (дежурные с меткой), "user:<login>" или "chat:<id>".
+type EscalationPolicy struct {
+	ID             uint   `gorm:"primaryKey"`
+	Label          string `gorm:"not null;uniqueIndex"`
+	TimeoutMinutes int    `gorm:"not null"`
+	Tiers          string `gorm:"not null"`
+}
+
+// TierList возвращает цели эскалации по порядку
+func (p EscalationPolicy) TierList() []string {
+	var tiers []string
+	for _, tier := range strings.Split(p.Tiers, ",") {
+		if tier = strings.TrimSpace(tier); tier != "" {
+			tiers = append(tiers, tier)
+		}
+	}
+	return tiers
+}
+
+// AlertEscalation - состояние эскалации по конкретному алерту
+type AlertEscalation struct {
+	ID             uint      `gorm:"primaryKey"`
+	Fingerprint    string    `gorm:"not null;index"`
+	PolicyID       uint      `gorm:"not null"`
+	AlertName      string    `gorm:"not null"`
+	Labels         string    `gorm:"type:text"` // метки алерта в JSON
+	Level          int       `gorm:"not null;default:0"`
+	NextAt         time.Time `gorm:"not null"`
+	AcknowledgedAt *time.Time
+	AcknowledgedBy *uint
+	ResolvedAt     *time.Time
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// EventSubscription - подписка чата на события Warning namespace Kubernetes
+type EventSubscription struct {
+	ID        uint   `gorm:"primaryKey"`
+	ChatID    int64  `gorm:"not null;uniqueIndex:idx_event_subscription_chat_namespace"`
+	Namespace string `gorm:"not null;uniqueIndex:idx_event_subscription_chat_namespace"`
+	CreatedAt time.Time
+}
@@ -4,8 +4,73 @@ import (
 	"time"
 )
 
+// Состояния инцидента
+const (
+	IncidentOpen         = "open"
+	IncidentAcknowledged = "acknowledged"
+	IncidentMitigated    = "mitigated"
+	IncidentResolved     = "resolved"
+)
+
+// Типы событий в хронологии инцидента
+const (
+	IncidentEventOpened   = "opened"
+	IncidentEventState    = "state"
+	IncidentEventAssigned = "assigned"
+	IncidentEventComment  = "comment"
+	IncidentEventAlert    = "alert"
+)
+
+var incidentTransitions = map[string][]string{
+	IncidentOpen:         {IncidentAcknowledged, IncidentMitigated, IncidentResolved},
+	IncidentAcknowledged: {IncidentMitigated, IncidentResolved},
+	IncidentMitigated:    {IncidentAcknowledged, IncidentResolved},
+	IncidentResolved:     {IncidentOpen},
+}
+
+// CanTransitionIncident проверяет, допустим ли переход инцидента из состояния from в to
+func CanTransitionIncident(from, to string) bool {
+	for _, next := range incidentTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
 type IncidentHistory struct {
-	Time   time.Time `gorm:"not null"`
-	ID     uint      `gorm:"primaryKey"`
-	Status string    `gorm:"not null"`
+	Time       time.Time `gorm:"not null"`
+	ID         uint      `gorm:"primaryKey"`
+	Status     string    `gorm:"not null"`
+	Title      string    `gorm:"not null;default:''"`
+	Severity   string
+	Namespace  string
+	Service    string
+	GroupKey   string `gorm:"index"` // ключ группы алертов для автоматически созданных инцидентов
+	AssigneeID *uint
+	Assignee   *User `gorm:"foreignKey:AssigneeID"`
+	ResolvedAt *time.Time
+	Alerts     []IncidentAlert `gorm:"foreignKey:IncidentID"`
+	Events     []IncidentEvent `gorm:"foreignKey:IncidentID"`
+}
+
+// IncidentAlert связывает инцидент с алертом Alertmanager по fingerprint
+type IncidentAlert struct {
+	ID          uint   `gorm:"primaryKey"`
+	IncidentID  uint   `gorm:"not null;index"`
+	Fingerprint string `gorm:"not null;index"`
+	AlertName   string
+	StartsAt    time.Time
+	ResolvedAt  *time.Time
+}
+
+// IncidentEvent - запись в хронологии инцидента
+type IncidentEvent struct {
+	ID         uint      `gorm:"primaryKey"`
+	IncidentID uint      `gorm:"not null;index"`
+	Time       time.Time `gorm:"not null"`
+	UserID     *uint
+	User       *User  `gorm:"foreignKey:UserID"`
+	Kind       string `gorm:"not null"`
+	Text       string
 }
@@ -0,0 +1,240 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Виды ротации дежурств
+const (
+	RotationDaily  = "daily"
+	RotationWeekly = "weekly"
+)
+
+// OnCallSchedule - расписание дежурств для метки (команды).
+// Members - логины через запятую в порядке ротации; смена передается
+// каждый день (или каждую неделю в HandoffWeekday) в HandoffTime по часовому поясу Timezone.
+type OnCallSchedule struct {
+	ID             uint      `gorm:"primaryKey"`
+	Label          string    `gorm:"not null;uniqueIndex"`
+	Rotation       string    `gorm:"not null"`
+	HandoffTime    string    `gorm:"not null"` // HH:MM
+	HandoffWeekday int       `gorm:"not null;default:1"`
+	Timezone       string    `gorm:"not null;default:UTC"`
+	Members        string    `gorm:"not null"`
+	StartsAt       time.Time `gorm:"not null"` // начало смены первого участника
+}
+
+// OnCallOverride подменяет дежурного на интервал времени (обмен сменами, отпуск)
+type OnCallOverride struct {
+	ID         uint      `gorm:"primaryKey"`
+	ScheduleID uint      `gorm:"not null;index"`
+	UserID     uint      `gorm:"not null"`
+	User       User      `gorm:"foreignKey:UserID"`
+	StartsAt   time.Time `gorm:"not null"`
+	EndsAt     time.Time `gorm:"not null"`
+	Reason     string
+}
+
+// OnCallShift - смена одного участника расписания
+type OnCallShift struct {
+	Login string
+	Start time.Time
+	End   time.Time
+}
+
+// MemberList возвращает логины участников ротации по порядку
+func (s OnCallSchedule) MemberList() []string {
+	var members []string
+	for _, m := range strings.Split(s.Members, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			members = append(members, m)
+		}
+	}
+	return members
+}
+
+// Location возвращает часовой пояс расписания
+func (s OnCallSchedule) Location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.Timezone)
+}
+
+// Validate проверяет корректность параметров расписания
+func (s OnCallSchedule) Validate() error {
+	if s.Rotation != RotationDaily && s.Rotation != RotationWeekly {
+		return fmt.Errorf("неизвестный вид ротации %q (daily или weekly)", s.Rotation)
+	}
+	if _, _, err := parseHandoffTime(s.HandoffTime); err != nil {
+		return err
+	}
+	if s.HandoffWeekday < 0 || s.HandoffWeekday > 6 {
+		return fmt.Errorf("некорректный день недели %d", s.HandoffWeekday)
+	}
+	if _, err := s.Location(); err != nil {
+		return fmt.Errorf("неизвестный часовой пояс %q", s.Timezone)
+	}
+	if len(s.MemberList()) == 0 {
+		return fmt.Errorf("в ротации нет участников")
+	}
+	return nil
+}
+
+// ShiftStart возвращает начало смены, идущей в момент t
+func (s OnCallSchedule) ShiftStart(t time.Time) (time.Time, error) {
+	loc, err := s.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	hour, minute, err := parseHandoffTime(s.HandoffTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	local := t.In(loc)
+	start := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+	switch s.Rotation {
+	case RotationWeekly:
+		diff := (int(local.Weekday()) - s.HandoffWeekday + 7) % 7
+		start = start.AddDate(0, 0, -diff)
+		if start.After(local) {
+			start = start.AddDate(0, 0, -7)
+		}
+	default:
+		if start.After(local) {
+			start = start.AddDate(0, 0, -1)
+		}
+	}
+	return start, nil
+}
+
+// ShiftAt возвращает смену по ротации (без учета подмен), идущую в момент t
+func (s OnCallSchedule) ShiftAt(t time.Time) (OnCallShift, error) {
+	members := s.MemberList()
+	if len(members) == 0 {
+		return OnCallShift{}, fmt.Errorf("в ротации нет участников")
+	}
+	start, err := s.ShiftStart(t)
+	if err != nil {
+		return OnCallShift{}, err
+	}
+	anchor, err := s.ShiftStart(s.StartsAt)
+	if err != nil {
+		return OnCallShift{}, err
+	}
+
+	periodDays := 1
+	if s.Rotation == RotationWeekly {
+		periodDays = 7
+	}
+	index := calendarDays(anchor, start) / periodDays
+	index = ((index % len(members)) + len(members)) % len(members)
+
+	return OnCallShift{
+		Login: members[index],
+		Start: start,
+		End:   start.AddDate(0, 0, periodDays),
+	}, nil
+}
+
+// NextShiftOf возвращает ближайшую смену участника login, идущую или начинающуюся не раньше t
+func (s OnCallSchedule) NextShiftOf(login string, t time.Time) (OnCallShift, error) {
+	members := s.MemberList()
+	found := false
+	for _, m := range members {
+		if m == login {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return OnCallShift{}, fmt.Errorf("%s не участвует в ротации", login)
+	}
+
+	shift, err := s.ShiftAt(t)
+	for i := 0; err == nil && i <= len(members); i++ {
+		if shift.Login == login {
+			return shift, nil
+		}
+		shift, err = s.ShiftAt(shift.End)
+	}
+	if err != nil {
+		return OnCallShift{}, err
+	}
+	return OnCallShift{}, fmt.Errorf("смена %s не найдена", login)
+}
+
+// NextDutyOf возвращает ближайшую смену участника login с учетом подмен overrides (с загруженным User).
+// Смены login, целиком отданные по подмене, пропускаются. Если ближайшая смена пересекается
+// с подменой лишь частично или сама получена login по подмене, возвращается ошибка:
+// такую смену нельзя однозначно передать другому.
+func (s OnCallSchedule) NextDutyOf(login string, t time.Time, overrides []OnCallOverride) (OnCallShift, error) {
+	var latest time.Time
+	var taken *OnCallOverride
+	for i := range overrides {
+		o := &overrides[i]
+		if o.EndsAt.After(latest) {
+			latest = o.EndsAt
+		}
+		if o.User.Login == login && o.EndsAt.After(t) && (taken == nil || o.StartsAt.Before(taken.StartsAt)) {
+			taken = o
+		}
+	}
+
+	from := t
+	for {
+		shift, err := s.NextShiftOf(login, from)
+		if err != nil {
+			return OnCallShift{}, err
+		}
+		if taken != nil && !taken.StartsAt.After(shift.Start) {
+			return OnCallShift{}, fmt.Errorf("ближайшая смена %s уже назначена подменой %s — %s",
+				login, taken.StartsAt.Format("2006-01-02 15:04"), taken.EndsAt.Format("2006-01-02 15:04"))
+		}
+		if !shift.Start.Before(latest) {
+			return shift, nil
+		}
+
+		covered, overlaps := coverage(shift, overrides)
+		if !overlaps {
+			return shift, nil
+		}
+		if !covered {
+			return OnCallShift{}, fmt.Errorf("смена %s %s — %s частично занята подменой",
+				login, shift.Start.Format("2006-01-02 15:04"), shift.End.Format("2006-01-02 15:04"))
+		}
+		from = shift.End
+	}
+}
+
+// coverage сообщает, пересекается ли смена с подменами и закрыта ли она одной подменой целиком
+func coverage(shift OnCallShift, overrides []OnCallOverride) (covered, overlaps bool) {
+	for _, o := range overrides {
+		if !o.StartsAt.Before(shift.End) || !o.EndsAt.After(shift.Start) {
+			continue
+		}
+		overlaps = true
+		if !o.StartsAt.After(shift.Start) && !o.EndsAt.Before(shift.End) {
+			covered = true
+		}
+	}
+	return covered, overlaps
+}
+
+func parseHandoffTime(value string) (int, int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("время передачи смены должно быть в формате HH:MM, получено %q", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// calendarDays возвращает число календарных дней от from до to без учета перевода часов
+func calendarDays(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
@@ -4,8 +4,37 @@ import (
 	"time"
 )
 
+// Статусы выполнения операции
+const (
+	OperationPending   = "pending"
+	OperationSuccess   = "success"
+	OperationError     = "error"
+	OperationDenied    = "denied"
+	OperationCancelled = "cancelled"
+)
+
+// Результаты подтверждения операции
+const (
+	ConfirmationAwaiting  = "awaiting"
+	ConfirmationConfirmed = "confirmed"
+	ConfirmationRejected  = "rejected"
+	ConfirmationSkipped   = "skipped"
+)
+
 type Operation struct {
-	Time time.Time `gorm:"not null"`
-	ID   uint      `gorm:"primaryKey"`
-	Text string    `gorm:"not null"`
+	Time         time.Time `gorm:"not null;index"`
+	ID           uint      `gorm:"primaryKey"`
+	Text         string    `gorm:"not null"`
+	TelegramID   int64     `gorm:"index"`
+	UserID       *uint     `gorm:"index"`
+	User         *User     `gorm:"foreignKey:UserID"`
+	ChatID       int64
+	Command      string `gorm:"index"`
+	Args         string
+	Namespace    string `gorm:"index"`
+	Resource     string
+	Confirmation string
+	FinishedAt   *time.Time
+	Status       string `gorm:"not null;default:pending"`
+	Error        string
 }
@@ -0,0 +1,45 @@
+package models
+
+import "strings"
+
+// SavedQuery - именованный запрос PromQL из каталога.
+// Expr - шаблон с параметрами {{service}}, {{namespace}} (см. monitoring.RenderQuery).
+type SavedQuery struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"not null;uniqueIndex"`
+	Description string
+	Expr        string `gorm:"type:text;not null"`
+	Unit        string
+}
+
+// Dashboard - набор запросов каталога, которые /dash выполняет вместе.
+// Queries - имена запросов через запятую в порядке вывода; Service и Namespace -
+// параметры по умолчанию (если Service пуст, используется имя дашборда).
+type Dashboard struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"not null;uniqueIndex"`
+	Service   string
+	Namespace string
+	Queries   string `gorm:"not null"`
+}
+
+// QueryList возвращает имена запросов дашборда по порядку
+func (d Dashboard) QueryList() []string {
+	var names []string
+	for _, name := range strings.Split(d.Queries, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Uses сообщает, входит ли запрос в дашборд
+func (d Dashboard) Uses(query string) bool {
+	for _, name := range d.QueryList() {
+		if name == query {
+			return true
+		}
+	}
+	return false
+}
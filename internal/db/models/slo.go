@@ -0,0 +1,14 @@
+package models
+
+// SLO - цель уровня обслуживания сервиса (см. monitoring.SLO).
+// Query - шаблон запроса доли хороших событий с параметром {{window}}.
+type SLO struct {
+	ID         uint    `gorm:"primaryKey"`
+	Service    string  `gorm:"not null;uniqueIndex:idx_slo_service_name"`
+	Name       string  `gorm:"not null;uniqueIndex:idx_slo_service_name"`
+	Namespace  string  `gorm:"not null;default:default"`
+	Kind       string  `gorm:"not null"` // availability или latency
+	Query      string  `gorm:"type:text;not null"`
+	Target     float64 `gorm:"not null"` // например 0.999
+	WindowDays int     `gorm:"not null;default:30"`
+}
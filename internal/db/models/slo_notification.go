@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// SLONotification хранит последнее предупреждение о расходе бюджета SLO,
+// чтобы после перезапуска бота не повторять предупреждения и сообщить о восстановлении
+type SLONotification struct {
+	ID             uint      `gorm:"primaryKey"`
+	SLOID          uint      `gorm:"not null;uniqueIndex"`
+	Severity       string    `gorm:"not null"`
+	LastNotifiedAt time.Time `gorm:"not null"`
+}
@@ -1,9 +1,20 @@
 package models
 
+// Роли пользователей бота в порядке возрастания прав
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
 type User struct {
 	ID        uint   `gorm:"primaryKey"`
 	Login     string `gorm:"not null"`
 	Password  string `gorm:"not null"`
 	IsDuty    bool   `gorm:"default:false"`
 	JobStatus string `gorm:"not null"`
+	Role      string `gorm:"not null;default:viewer"`
+	Disabled  bool   `gorm:"default:false"`
+	// TelegramChatID - личный чат с ботом, запоминается при входе через /start
+	TelegramChatID int64 `gorm:"index"`
 }
@@ -0,0 +1,11 @@
+package models
+
+// UserGrant описывает право пользователя на операции с ресурсами Kubernetes.
+// Scope имеет вид "ns=<namespace>", "ns=*" или "deploy=", "sts=", "ds=<namespace>/<name>"
+// для Deployment, StatefulSet и DaemonSet.
+type UserGrant struct {
+	ID     uint   `gorm:"primaryKey"`
+	UserID uint   `gorm:"not null;index"`
+	User   User   `gorm:"foreignKey:UserID"`
+	Scope  string `gorm:"not null"`
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"chatops/internal/db/config"
+	"chatops/internal/db/models"
+)
+
+// GetActiveAlertNotifications получает состояния уведомлений по еще не погасшим алертам
+func GetActiveAlertNotifications() ([]models.AlertNotification, error) {
+	var notifications []models.AlertNotification
+	err := config.DB.Where("resolved_at IS NULL").Find(&notifications).Error
+	return notifications, err
+}
+
+// SaveAlertNotification создает или обновляет состояние уведомлений по алерту
+func SaveAlertNotification(notification *models.AlertNotification) error {
+	if notification.ID == 0 {
+		return config.DB.Create(notification).Error
+	}
+	return config.DB.Save(notification).Error
+}
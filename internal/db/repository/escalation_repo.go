@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"chatops/internal/db/config"
+	"chatops/internal/db/models"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// SaveEscalationPolicy создает или заменяет политику эскалации для метки
+func SaveEscalationPolicy(policy *models.EscalationPolicy) error {
+	return config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "label"}},
+		DoUpdates: clause.AssignmentColumns([]string{"timeout_minutes", "tiers"}),
+	}).Create(policy).Error
+}
+
+// GetEscalationPolicies получает все политики эскалации
+func GetEscalationPolicies() ([]models.EscalationPolicy, error) {
+	var policies []models.EscalationPolicy
+	err := config.DB.Order("label").Find(&policies).Error
+	return policies, err
+}
+
+// DeleteEscalationPolicy удаляет политику эскалации для метки
+func DeleteEscalationPolicy(label string) (int64, error) {
+	res := config.DB.Where("label = ?", label).Delete(&models.EscalationPolicy{})
+	return res.RowsAffected, res.Error
+}
+
+// GetOpenEscalations получает эскалации по активным алертам, включая подтвержденные
+func GetOpenEscalations() ([]models.AlertEscalation, error) {
+	var escalations []models.AlertEscalation
+	err := config.DB.Where("resolved_at IS NULL").Find(&escalations).Error
+	return escalations, err
+}
+
+// SaveEscalation создает или обновляет состояние эскалации
+func SaveEscalation(escalation *models.AlertEscalation) error {
+	if escalation.ID == 0 {
+		return config.DB.Create(escalation).Error
+	}
+	return config.DB.Save(escalation).Error
+}
+
+// AcknowledgeEscalations подтверждает все активные эскалации по fingerprint алерта
+func AcknowledgeEscalations(fingerprint string, userID uint) (int64, error) {
+	now := time.Now()
+	res := config.DB.Model(&models.AlertEscalation{}).
+		Where("fingerprint = ? AND resolved_at IS NULL AND acknowledged_at IS NULL", fingerprint).
+		Updates(map[string]interface{}{"acknowledged_at": &now, "acknowledged_by": userID})
+	return res.RowsAffected, res.Error
+}
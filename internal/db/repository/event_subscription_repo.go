@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"chatops/internal/db/config"
+	"chatops/internal/db/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// SaveEventSubscription подписывает чат на события namespace; повторная подписка ничего не меняет
+func SaveEventSubscription(chatID int64, namespace string) error {
+	return config.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.EventSubscription{ChatID: chatID, Namespace: namespace}).Error
+}
+
+// GetEventSubscriptions получает все подписки на события
+func GetEventSubscriptions() ([]models.EventSubscription, error) {
+	var subscriptions []models.EventSubscription
+	err := config.DB.Order("namespace, chat_id").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// GetChatEventSubscriptions получает подписки чата
+func GetChatEventSubscriptions(chatID int64) ([]models.EventSubscription, error) {
+	var subscriptions []models.EventSubscription
+	err := config.DB.Where("chat_id = ?", chatID).Order("namespace").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// DeleteEventSubscription отписывает чат от событий namespace
+func DeleteEventSubscription(chatID int64, namespace string) (int64, error) {
+	res := config.DB.Where("chat_id = ? AND namespace = ?", chatID, namespace).Delete(&models.EventSubscription{})
+	return res.RowsAffected, res.Error
+}
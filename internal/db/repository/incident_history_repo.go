@@ -3,7 +3,10 @@ package repository
 import (
 	"chatops/internal/db/config"
 	"chatops/internal/db/models"
+	"fmt"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 func CreateIncident(status string) (*models.IncidentHistory, error) {
@@ -15,11 +18,178 @@ func CreateIncident(status string) (*models.IncidentHistory, error) {
 	return incident, err
 }
 
-// GetUserIncidents получает все инциденты пользователя
+// OpenIncident создает инцидент в состоянии open и первое событие хронологии
+func OpenIncident(title, severity, namespace, service string, openedBy *uint) (*models.IncidentHistory, error) {
+	now := time.Now()
+	incident := &models.IncidentHistory{
+		Time:      now,
+		Status:    models.IncidentOpen,
+		Title:     title,
+		Severity:  severity,
+		Namespace: namespace,
+		Service:   service,
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(incident).Error; err != nil {
+			return err
+		}
+		return addIncidentEvent(tx, incident.ID, openedBy, models.IncidentEventOpened, title)
+	})
+	return incident, err
+}
+
+// OpenAlertIncident создает инцидент для новой группы сработавших алертов
+func OpenAlertIncident(groupKey, title, severity, namespace, service string) (*models.IncidentHistory, error) {
+	now := time.Now()
+	incident := &models.IncidentHistory{
+		Time:      now,
+		Status:    models.IncidentOpen,
+		Title:     title,
+		Severity:  severity,
+		Namespace: namespace,
+		Service:   service,
+		GroupKey:  groupKey,
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(incident).Error; err != nil {
+			return err
+		}
+		return addIncidentEvent(tx, incident.ID, nil, models.IncidentEventOpened, "автоматически по алертам группы "+groupKey)
+	})
+	return incident, err
+}
+
+// GetActiveAlertIncidents получает незакрытые инциденты, созданные по алертам, вместе с их активными алертами
+func GetActiveAlertIncidents() ([]models.IncidentHistory, error) {
+	var incidents []models.IncidentHistory
+	err := config.DB.Preload("Alerts", "resolved_at IS NULL").
+		Where("group_key <> '' AND status <> ?", models.IncidentResolved).
+		Order("time asc").
+		Find(&incidents).Error
+	return incidents, err
+}
+
+// AddIncidentAlert привязывает алерт к инциденту и отмечает это в хронологии
+func AddIncidentAlert(incidentID uint, fingerprint, alertName string, startsAt time.Time) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.IncidentAlert{
+			IncidentID:  incidentID,
+			Fingerprint: fingerprint,
+			AlertName:   alertName,
+			StartsAt:    startsAt,
+		}).Error; err != nil {
+			return err
+		}
+		return addIncidentEvent(tx, incidentID, nil, models.IncidentEventAlert, fmt.Sprintf("сработал %s (%s)", alertName, fingerprint))
+	})
+}
+
+// ResolveIncidentAlert отмечает, что алерт инцидента больше не активен
+func ResolveIncidentAlert(incidentID uint, fingerprint string) error {
+	now := time.Now()
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.IncidentAlert{}).
+			Where("incident_id = ? AND fingerprint = ? AND resolved_at IS NULL", incidentID, fingerprint).
+			Update("resolved_at", &now)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return addIncidentEvent(tx, incidentID, nil, models.IncidentEventAlert, fmt.Sprintf("погас алерт %s", fingerprint))
+	})
+}
+
+// GetIncidentByID получает инцидент вместе с исполнителем, алертами и хронологией
+func GetIncidentByID(id uint) (*models.IncidentHistory, error) {
+	var incident models.IncidentHistory
+	err := config.DB.Preload("Assignee").
+		Preload("Alerts").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("time asc, id asc") }).
+		Preload("Events.User").
+		First(&incident, id).Error
+	return &incident, err
+}
+
+// TransitionIncident переводит инцидент в новое состояние с проверкой допустимости перехода
+func TransitionIncident(id uint, to string, userID *uint, note string) (*models.IncidentHistory, error) {
+	var incident models.IncidentHistory
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&incident, id).Error; err != nil {
+			return err
+		}
+		if !models.CanTransitionIncident(incident.Status, to) {
+			return fmt.Errorf("переход %s → %s недопустим", incident.Status, to)
+		}
+
+		// Updates с map записывает новые значения в incident, исходное состояние запоминаем заранее
+		from := incident.Status
+		updates := map[string]interface{}{"status": to}
+		if to == models.IncidentResolved {
+			now := time.Now()
+			updates["resolved_at"] = &now
+		} else {
+			updates["resolved_at"] = nil
+		}
+		if err := tx.Model(&incident).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		text := fmt.Sprintf("%s → %s", from, to)
+		if note != "" {
+			text += ": " + note
+		}
+		return addIncidentEvent(tx, id, userID, models.IncidentEventState, text)
+	})
+	return &incident, err
+}
+
+// AssignIncident назначает исполнителя инцидента
+func AssignIncident(id uint, assignee *models.User, byUserID *uint) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.IncidentHistory{}).Where("id = ?", id).Update("assignee_id", assignee.ID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return addIncidentEvent(tx, id, byUserID, models.IncidentEventAssigned, "назначен "+assignee.Login)
+	})
+}
+
+// AddIncidentComment добавляет комментарий в хронологию инцидента
+func AddIncidentComment(id uint, userID *uint, text string) error {
+	if err := config.DB.First(&models.IncidentHistory{}, id).Error; err != nil {
+		return err
+	}
+	return addIncidentEvent(config.DB, id, userID, models.IncidentEventComment, text)
+}
+
+func addIncidentEvent(tx *gorm.DB, incidentID uint, userID *uint, kind, text string) error {
+	return tx.Create(&models.IncidentEvent{
+		IncidentID: incidentID,
+		Time:       time.Now(),
+		UserID:     userID,
+		Kind:       kind,
+		Text:       text,
+	}).Error
+}
+
+// GetUserIncidents получает все инциденты, назначенные пользователю
 func GetUserIncidents(userID uint) ([]models.IncidentHistory, error) {
 	var incidents []models.IncidentHistory
-	err := config.DB.Where("user_id = ?", userID).
-		Order("created_at desc").
+	err := config.DB.Where("assignee_id = ?", userID).
+		Order("time desc").
+		Find(&incidents).Error
+	return incidents, err
+}
+
+// GetLatestIncidents получает последние инциденты вместе с исполнителем и алертами, без хронологии
+func GetLatestIncidents(limit int) ([]models.IncidentHistory, error) {
+	var incidents []models.IncidentHistory
+	err := config.DB.Preload("Assignee").
+		Preload("Alerts").
+		Order("time desc").
+		Limit(limit).
 		Find(&incidents).Error
 	return incidents, err
 }
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"chatops/internal/db/config"
+	"chatops/internal/db/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveOnCallSchedule создает или заменяет расписание дежурств для метки.
+// Начало ротации (starts_at) у существующего расписания не меняется.
+func SaveOnCallSchedule(schedule *models.OnCallSchedule) error {
+	return config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "label"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rotation", "handoff_time", "handoff_weekday", "timezone", "members"}),
+	}).Create(schedule).Error
+}
+
+// GetOnCallSchedule получает расписание дежурств для метки
+func GetOnCallSchedule(label string) (*models.OnCallSchedule, error) {
+	var schedule models.OnCallSchedule
+	err := config.DB.Where("label = ?", label).First(&schedule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// GetOnCallSchedules получает все расписания дежурств
+func GetOnCallSchedules() ([]models.OnCallSchedule, error) {
+	var schedules []models.OnCallSchedule
+	err := config.DB.Order("label").Find(&schedules).Error
+	return schedules, err
+}
+
+// CreateOnCallOverride сохраняет подмену дежурного
+func CreateOnCallOverride(override *models.OnCallOverride) error {
+	return config.DB.Omit("User").Create(override).Error
+}
+
+// GetOnCallOverride получает подмену, действующую в момент at; более поздняя подмена имеет приоритет.
+// Если подмены нет, возвращает nil без ошибки.
+func GetOnCallOverride(scheduleID uint, at time.Time) (*models.OnCallOverride, error) {
+	var override models.OnCallOverride
+	err := config.DB.Preload("User").
+		Where("schedule_id = ? AND starts_at <= ? AND ends_at > ?", scheduleID, at, at).
+		Order("id DESC").
+		First(&override).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+// GetUpcomingOnCallOverrides получает подмены расписания, которые еще не закончились
+func GetUpcomingOnCallOverrides(scheduleID uint, at time.Time) ([]models.OnCallOverride, error) {
+	var overrides []models.OnCallOverride
+	err := config.DB.Preload("User").
+		Where("schedule_id = ? AND ends_at > ?", scheduleID, at).
+		Order("starts_at").
+		Find(&overrides).Error
+	return overrides, err
+}
+
+// GetOnCallUser вычисляет дежурного по расписанию в момент at с учетом подмен
+func GetOnCallUser(schedule *models.OnCallSchedule, at time.Time) (*models.User, error) {
+	override, err := GetOnCallOverride(schedule.ID, at)
+	if err != nil {
+		return nil, err
+	}
+	if override != nil {
+		return &override.User, nil
+	}
+
+	shift, err := schedule.ShiftAt(at)
+	if err != nil {
+		return nil, err
+	}
+	user, err := GetUserByLogin(shift.Login)
+	if err != nil {
+		return nil, fmt.Errorf("дежурный %s из расписания %s не найден: %w", shift.Login, schedule.Label, err)
+	}
+	return user, nil
+}
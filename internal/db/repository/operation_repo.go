@@ -6,15 +6,38 @@ import (
 	"time"
 )
 
+// OperationFilter задает условия выборки операций из журнала
+type OperationFilter struct {
+	UserID    *uint
+	Namespace string
+	// Resource - точное имя ресурса, для нагрузок вида kind/namespace/name
+	Resource string
+	// Name - имя ресурса любого вида в Namespace
+	Name   string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+	Offset int
+}
+
 // CreateOperation создает новую операцию
 func CreateOperation(text string) error {
 	operation := &models.Operation{
-		Time: time.Now(),
-		Text: text,
+		Time:   time.Now(),
+		Text:   text,
+		Status: models.OperationSuccess,
 	}
 	return config.DB.Create(operation).Error
 }
 
+// SaveOperation создает или обновляет запись журнала операций
+func SaveOperation(operation *models.Operation) error {
+	if operation.ID == 0 {
+		return config.DB.Create(operation).Error
+	}
+	return config.DB.Omit("User").Save(operation).Error
+}
+
 // GetRecentOperations получает все операции за последние 5 минут
 func GetRecentOperations() ([]models.Operation, error) {
 	var operations []models.Operation
@@ -29,11 +52,49 @@ func GetRecentOperations() ([]models.Operation, error) {
 func GetUserOperations(userID uint) ([]models.Operation, error) {
 	var operations []models.Operation
 	err := config.DB.Where("user_id = ?", userID).
-		Order("created_at desc").
+		Order("time desc").
 		Find(&operations).Error
 	return operations, err
 }
 
+// FindOperations возвращает страницу операций по фильтру и общее число подходящих записей
+func FindOperations(filter OperationFilter) ([]models.Operation, int64, error) {
+	query := config.DB.Model(&models.Operation{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Namespace != "" {
+		query = query.Where("namespace = ?", filter.Namespace)
+	}
+	if filter.Resource != "" {
+		query = query.Where("resource = ?", filter.Resource)
+	}
+	if filter.Name != "" {
+		query = query.Where("resource = ? OR resource LIKE ?", filter.Name, "%/"+filter.Namespace+"/"+filter.Name)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("time >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("time <= ?", filter.Until)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var operations []models.Operation
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	err := query.Preload("User").
+		Order("time desc").
+		Offset(filter.Offset).
+		Find(&operations).Error
+	return operations, total, err
+}
+
 // GetOperationByID получает операцию по ID
 func GetOperationByID(id uint) (*models.Operation, error) {
 	var operation models.Operation
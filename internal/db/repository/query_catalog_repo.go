@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"chatops/internal/db/config"
+	"chatops/internal/db/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveQuery создает или заменяет запрос каталога с тем же именем
+func SaveQuery(query *models.SavedQuery) error {
+	return config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"description", "expr", "unit"}),
+	}).Create(query).Error
+}
+
+// GetQuery получает запрос каталога по имени, nil - если запроса нет
+func GetQuery(name string) (*models.SavedQuery, error) {
+	var query models.SavedQuery
+	err := config.DB.Where("name = ?", name).First(&query).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &query, nil
+}
+
+// GetQueries получает все запросы каталога
+func GetQueries() ([]models.SavedQuery, error) {
+	var queries []models.SavedQuery
+	err := config.DB.Order("name").Find(&queries).Error
+	return queries, err
+}
+
+// GetQueriesByName получает запросы каталога по списку имен
+func GetQueriesByName(names []string) (map[string]models.SavedQuery, error) {
+	var queries []models.SavedQuery
+	if err := config.DB.Where("name IN ?", names).Find(&queries).Error; err != nil {
+		return nil, err
+	}
+	byName := make(map[string]models.SavedQuery, len(queries))
+	for _, q := range queries {
+		byName[q.Name] = q
+	}
+	return byName, nil
+}
+
+// DeleteQuery удаляет запрос каталога
+func DeleteQuery(name string) (int64, error) {
+	res := config.DB.Where("name = ?", name).Delete(&models.SavedQuery{})
+	return res.RowsAffected, res.Error
+}
+
+// SaveDashboard создает или заменяет дашборд с тем же именем
+func SaveDashboard(dashboard *models.Dashboard) error {
+	return config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"service", "namespace", "queries"}),
+	}).Create(dashboard).Error
+}
+
+// GetDashboard получает дашборд по имени, nil - если дашборда нет
+func GetDashboard(name string) (*models.Dashboard, error) {
+	var dashboard models.Dashboard
+	err := config.DB.Where("name = ?", name).First(&dashboard).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &dashboard, nil
+}
+
+// GetDashboards получает все дашборды
+func GetDashboards() ([]models.Dashboard, error) {
+	var dashboards []models.Dashboard
+	err := config.DB.Order("name").Find(&dashboards).Error
+	return dashboards, err
+}
+
+// DeleteDashboard удаляет дашборд
+func DeleteDashboard(name string) (int64, error) {
+	res := config.DB.Where("name = ?", name).Delete(&models.Dashboard{})
+	return res.RowsAffected, res.Error
+}
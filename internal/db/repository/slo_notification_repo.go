@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"chatops/internal/db/config"
+	"chatops/internal/db/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// GetSLONotifications получает состояния предупреждений по SLO, бюджет которых сгорает
+func GetSLONotifications() ([]models.SLONotification, error) {
+	var notifications []models.SLONotification
+	err := config.DB.Find(&notifications).Error
+	return notifications, err
+}
+
+// SaveSLONotification создает или обновляет состояние предупреждений по SLO
+func SaveSLONotification(notification *models.SLONotification) error {
+	return config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "slo_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"severity", "last_notified_at"}),
+	}).Create(notification).Error
+}
+
+// DeleteSLONotification удаляет состояние предупреждений по SLO после восстановления
+func DeleteSLONotification(sloID uint) error {
+	return config.DB.Where("slo_id = ?", sloID).Delete(&models.SLONotification{}).Error
+}
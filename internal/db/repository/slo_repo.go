@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"chatops/internal/db/config"
+	"chatops/internal/db/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// SaveSLO создает или заменяет SLO сервиса с тем же именем
+func SaveSLO(slo *models.SLO) error {
+	return config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "service"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"namespace", "kind", "query", "target", "window_days"}),
+	}).Create(slo).Error
+}
+
+// GetSLOs получает все SLO
+func GetSLOs() ([]models.SLO, error) {
+	var slos []models.SLO
+	err := config.DB.Order("service, name").Find(&slos).Error
+	return slos, err
+}
+
+// GetServiceSLOs получает SLO сервиса
+func GetServiceSLOs(service string) ([]models.SLO, error) {
+	var slos []models.SLO
+	err := config.DB.Where("service = ?", service).Order("name").Find(&slos).Error
+	return slos, err
+}
+
+// DeleteSLO удаляет SLO сервиса
+func DeleteSLO(service, name string) (int64, error) {
+	res := config.DB.Where("service = ? AND name = ?", service, name).Delete(&models.SLO{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"chatops/internal/db/config"
+	"chatops/internal/db/models"
+)
+
+// CreateUserGrant выдает пользователю право на namespace или deployment
+func CreateUserGrant(userID uint, scope string) (*models.UserGrant, error) {
+	grant := &models.UserGrant{
+		UserID: userID,
+		Scope:  scope,
+	}
+	err := config.DB.Create(grant).Error
+	return grant, err
+}
+
+// GetUserGrants получает области доступа пользователя
+func GetUserGrants(userID uint) ([]string, error) {
+	var scopes []string
+	err := config.DB.Model(&models.UserGrant{}).
+		Where("user_id = ?", userID).
+		Order("scope").
+		Pluck("scope", &scopes).Error
+	return scopes, err
+}
+
+// DeleteUserGrant отзывает право пользователя
+func DeleteUserGrant(userID uint, scope string) (int64, error) {
+	res := config.DB.Where("user_id = ? AND scope = ?", userID, scope).Delete(&models.UserGrant{})
+	return res.RowsAffected, res.Error
+}
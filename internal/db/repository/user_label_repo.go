@@ -3,6 +3,10 @@ package repository
 import (
 	"chatops/internal/db/config"
 	"chatops/internal/db/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 // CreateUserLabel создает новую метку для пользователя
@@ -41,10 +45,26 @@ func GetLabelByName(label string) (*models.UserLabel, error) {
 	return &userLabel, err
 }
 
-// GetDutyUsersByLabel получает всех дежурных пользователей с определенной меткой
+// GetDutyUsersByLabel получает дежурных для метки. Если для метки задано расписание дежурств,
+// дежурный вычисляется по нему; иначе возвращаются пользователи с меткой и флагом is_duty.
 func GetDutyUsersByLabel(label string) ([]models.User, error) {
+	schedule, err := GetOnCallSchedule(label)
+	if err == nil {
+		user, err := GetOnCallUser(schedule, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if user.Disabled {
+			return nil, nil
+		}
+		return []models.User{*user}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
 	var users []models.User
-	err := config.DB.Joins("JOIN user_labels ON user_labels.user_id = users.id").
+	err = config.DB.Joins("JOIN user_labels ON user_labels.user_id = users.id").
 		Where("user_labels.label = ? AND users.is_duty = ?", label, true).
 		Find(&users).Error
 	return users, err
@@ -3,20 +3,59 @@ package repository
 import (
 	"chatops/internal/db/config"
 	"chatops/internal/db/models"
+	"errors"
+	"log"
 )
 
-// CreateUser создает нового пользователя
+// ErrInvalidCredentials возвращается при неверном логине, пароле или отключенной учетной записи
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// CreateUser создает нового пользователя с ролью viewer
 func CreateUser(login string, password string, jobStatus string) (*models.User, error) {
+	return CreateUserWithRole(login, password, jobStatus, models.RoleViewer)
+}
+
+// CreateUserWithRole создает нового пользователя с указанной ролью; пароль сохраняется в виде хеша
+func CreateUserWithRole(login, password, jobStatus, role string) (*models.User, error) {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
 	user := &models.User{
 		Login:     login,
-		Password:  password,
+		Password:  hash,
 		JobStatus: jobStatus,
 		IsDuty:    false,
+		Role:      role,
 	}
-	err := config.DB.Create(user).Error
+	err = config.DB.Create(user).Error
 	return user, err
 }
 
+// GetUserByLogin получает пользователя по логину
+func GetUserByLogin(login string) (*models.User, error) {
+	var user models.User
+	err := config.DB.Where("login = ?", login).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetUserPassword сохраняет новый пароль пользователя в виде хеша
+func SetUserPassword(userID uint, password string) error {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return config.DB.Model(&models.User{}).Where("id = ?", userID).Update("password", hash).Error
+}
+
+// SetUserDisabled включает или отключает учетную запись
+func SetUserDisabled(userID uint, disabled bool) error {
+	return config.DB.Model(&models.User{}).Where("id = ?", userID).Update("disabled", disabled).Error
+}
+
 // GetUserByID получает пользователя по ID
 func GetUserByID(id uint) (*models.User, error) {
 	var user models.User
@@ -29,6 +68,16 @@ func UpdateUserDutyStatus(userID uint, isDuty bool) error {
 	return config.DB.Model(&models.User{}).Where("id = ?", userID).Update("is_duty", isDuty).Error
 }
 
+// UpdateUserRole обновляет роль пользователя
+func UpdateUserRole(userID uint, role string) error {
+	return config.DB.Model(&models.User{}).Where("id = ?", userID).Update("role", role).Error
+}
+
+// SetUserTelegramChatID привязывает пользователя к чату Telegram для уведомлений
+func SetUserTelegramChatID(userID uint, chatID int64) error {
+	return config.DB.Model(&models.User{}).Where("id = ?", userID).Update("telegram_chat_id", chatID).Error
+}
+
 // GetAllUsers получает всех пользователей
 func GetAllUsers() ([]models.User, error) {
 	var users []models.User
@@ -45,7 +94,25 @@ func GetDutyUsers() ([]models.User, error) {
 
 // AuthenticateUser проверяет существование пользователя по логину и паролю
 func AuthenticateUser(login, password string) bool {
-	var user models.User
-	err := config.DB.Where("login = ? AND password = ?", login, password).First(&user).Error
+	_, err := GetUserByCredentials(login, password)
 	return err == nil
 }
+
+// GetUserByCredentials возвращает пользователя по логину и паролю.
+// Пароль, сохраненный открытым текстом, при успешном входе заменяется хешем.
+func GetUserByCredentials(login, password string) (*models.User, error) {
+	user, err := GetUserByLogin(login)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if user.Disabled || !CheckPassword(user.Password, password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if !IsPasswordHashed(user.Password) {
+		if err := SetUserPassword(user.ID, password); err != nil {
+			log.Printf("Failed to migrate password of user %s to hash: %v", user.Login, err)
+		}
+	}
+	return user, nil
+}
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"chatops/internal/db/migrations"
+	"chatops/internal/db/models"
+	"chatops/internal/db/repository"
+	"testing"
+)
+
+func TestIncidentTransitions(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{models.IncidentOpen, models.IncidentAcknowledged, true},
+		{models.IncidentOpen, models.IncidentResolved, true},
+		{models.IncidentAcknowledged, models.IncidentMitigated, true},
+		{models.IncidentMitigated, models.IncidentResolved, true},
+		{models.IncidentResolved, models.IncidentOpen, true},
+		{models.IncidentAcknowledged, models.IncidentOpen, false},
+		{models.IncidentResolved, models.IncidentAcknowledged, false},
+		{models.IncidentOpen, models.IncidentOpen, false},
+		{"unknown", models.IncidentResolved, false},
+	}
+
+	for _, tt := range tests {
+		if got := models.CanTransitionIncident(tt.from, tt.to); got != tt.allowed {
+			t.Errorf("CanTransitionIncident(%s, %s) = %v, ожидалось %v", tt.from, tt.to, got, tt.allowed)
+		}
+	}
+}
+
+func TestTransitionIncidentTimeline(t *testing.T) {
+	if err := migrations.AutoMigrate(); err != nil {
+		t.Skipf("база данных недоступна: %v", err)
+	}
+
+	incident, err := repository.OpenIncident("timeline test", "warning", "default", "api", nil)
+	if err != nil {
+		t.Fatalf("OpenIncident: %v", err)
+	}
+	if _, err := repository.TransitionIncident(incident.ID, models.IncidentAcknowledged, nil, ""); err != nil {
+		t.Fatalf("TransitionIncident(acknowledged): %v", err)
+	}
+	updated, err := repository.TransitionIncident(incident.ID, models.IncidentResolved, nil, "откатили релиз")
+	if err != nil {
+		t.Fatalf("TransitionIncident(resolved): %v", err)
+	}
+	if updated.Status != models.IncidentResolved {
+		t.Errorf("статус %s, ожидался %s", updated.Status, models.IncidentResolved)
+	}
+
+	stored, err := repository.GetIncidentByID(incident.ID)
+	if err != nil {
+		t.Fatalf("GetIncidentByID: %v", err)
+	}
+	var states []string
+	for _, e := range stored.Events {
+		if e.Kind == models.IncidentEventState {
+			states = append(states, e.Text)
+		}
+	}
+	expected := []string{"open → acknowledged", "acknowledged → resolved: откатили релиз"}
+	if len(states) != len(expected) {
+		t.Fatalf("в хронологии переходы %q, ожидались %q", states, expected)
+	}
+	for i := range expected {
+		if states[i] != expected[i] {
+			t.Errorf("переход %d: %q, ожидался %q", i, states[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"chatops/internal/db/migrations"
+	"chatops/internal/db/models"
+	"chatops/internal/db/repository"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestOnCallDailyRotation(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("часовой пояс недоступен: %v", err)
+	}
+	schedule := models.OnCallSchedule{
+		Rotation:    models.RotationDaily,
+		HandoffTime: "09:00",
+		Timezone:    "Europe/Moscow",
+		Members:     "alice, bob,carol",
+		StartsAt:    time.Date(2024, 1, 1, 10, 0, 0, 0, loc),
+	}
+	if err := schedule.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	tests := []struct {
+		at    time.Time
+		login string
+	}{
+		{time.Date(2024, 1, 1, 9, 0, 0, 0, loc), "alice"},
+		{time.Date(2024, 1, 2, 8, 59, 0, 0, loc), "alice"},
+		{time.Date(2024, 1, 2, 9, 0, 0, 0, loc), "bob"},
+		{time.Date(2024, 1, 3, 23, 0, 0, 0, loc), "carol"},
+		{time.Date(2024, 1, 4, 6, 30, 0, 0, time.UTC), "alice"},
+		{time.Date(2023, 12, 31, 12, 0, 0, 0, loc), "carol"},
+	}
+	for _, tt := range tests {
+		shift, err := schedule.ShiftAt(tt.at)
+		if err != nil {
+			t.Fatalf("ShiftAt(%s): %v", tt.at, err)
+		}
+		if shift.Login != tt.login {
+			t.Errorf("ShiftAt(%s) = %s, ожидался %s", tt.at, shift.Login, tt.login)
+		}
+		if !shift.End.Equal(shift.Start.AddDate(0, 0, 1)) || shift.Start.After(tt.at) || !shift.End.After(tt.at) {
+			t.Errorf("ShiftAt(%s): некорректные границы смены %s - %s", tt.at, shift.Start, shift.End)
+		}
+	}
+}
+
+func TestOnCallWeeklyRotationAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("часовой пояс недоступен: %v", err)
+	}
+	schedule := models.OnCallSchedule{
+		Rotation:       models.RotationWeekly,
+		HandoffTime:    "09:00",
+		HandoffWeekday: int(time.Monday),
+		Timezone:       "America/New_York",
+		Members:        "alice,bob,carol",
+		StartsAt:       time.Date(2024, 3, 6, 12, 0, 0, 0, loc),
+	}
+
+	// 10 марта в Нью-Йорке переход на летнее время - передача смены остается в 09:00 по местному времени
+	shift, err := schedule.ShiftAt(time.Date(2024, 3, 11, 9, 0, 0, 0, loc))
+	if err != nil {
+		t.Fatalf("ShiftAt: %v", err)
+	}
+	if shift.Login != "bob" {
+		t.Errorf("ожидался bob, получен %s", shift.Login)
+	}
+	if start := shift.Start.In(loc); start.Hour() != 9 || start.Weekday() != time.Monday {
+		t.Errorf("смена должна начинаться в понедельник 09:00, получено %s", start)
+	}
+	if end := shift.End.In(loc); end.Hour() != 9 || end.Day() != 18 {
+		t.Errorf("смена должна заканчиваться 18 марта в 09:00, получено %s", end)
+	}
+
+	next, err := schedule.NextShiftOf("carol", time.Date(2024, 3, 5, 0, 0, 0, 0, loc))
+	if err != nil {
+		t.Fatalf("NextShiftOf: %v", err)
+	}
+	if !next.Start.Equal(time.Date(2024, 3, 18, 9, 0, 0, 0, loc)) {
+		t.Errorf("ближайшая смена carol должна начинаться 18 марта, получено %s", next.Start)
+	}
+	if _, err := schedule.NextShiftOf("dave", time.Now()); err == nil {
+		t.Error("ожидалась ошибка для участника вне ротации")
+	}
+}
+
+func TestOnCallScheduleValidate(t *testing.T) {
+	valid := models.OnCallSchedule{Rotation: models.RotationDaily, HandoffTime: "09:00", Timezone: "UTC", Members: "alice"}
+	invalid := []models.OnCallSchedule{
+		{Rotation: "monthly", HandoffTime: "09:00", Timezone: "UTC", Members: "alice"},
+		{Rotation: models.RotationDaily, HandoffTime: "25:00", Timezone: "UTC", Members: "alice"},
+		{Rotation: models.RotationDaily, HandoffTime: "09:00", Timezone: "Mars/Olympus", Members: "alice"},
+		{Rotation: models.RotationDaily, HandoffTime: "09:00", Timezone: "UTC", Members: " , "},
+		{Rotation: models.RotationWeekly, HandoffTime: "09:00", HandoffWeekday: 7, Timezone: "UTC", Members: "alice"},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("ожидалось корректное расписание: %v", err)
+	}
+	for _, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("ожидалась ошибка для %+v", s)
+		}
+	}
+}
+
+func TestOnCallNextDutyWithOverrides(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2024, 1, d, h, 0, 0, 0, time.UTC) }
+	schedule := models.OnCallSchedule{
+		Rotation:    models.RotationDaily,
+		HandoffTime: "09:00",
+		Timezone:    "UTC",
+		Members:     "alice,bob,carol",
+		StartsAt:    day(1, 9),
+	}
+	now := day(1, 12)
+
+	shift, err := schedule.NextDutyOf("bob", now, nil)
+	if err != nil || !shift.Start.Equal(day(2, 9)) {
+		t.Errorf("NextDutyOf(bob) без подмен = %+v, %v", shift, err)
+	}
+
+	// alice и bob уже поменялись сменами
+	swapped := []models.OnCallOverride{
+		{User: models.User{Login: "bob"}, StartsAt: day(1, 9), EndsAt: day(2, 9)},
+		{User: models.User{Login: "alice"}, StartsAt: day(2, 9), EndsAt: day(3, 9)},
+	}
+	shift, err = schedule.NextDutyOf("carol", now, swapped)
+	if err != nil || !shift.Start.Equal(day(3, 9)) {
+		t.Errorf("NextDutyOf(carol) = %+v, %v", shift, err)
+	}
+	for _, login := range []string{"alice", "bob"} {
+		if shift, err := schedule.NextDutyOf(login, now, swapped); err == nil {
+			t.Errorf("NextDutyOf(%s): ожидалась ошибка, получена смена %+v", login, shift)
+		}
+	}
+
+	// смена, целиком отданная по подмене, пропускается; частично занятая - ошибка
+	vacation := []models.OnCallOverride{{User: models.User{Login: "dave"}, StartsAt: day(3, 9), EndsAt: day(4, 9)}}
+	shift, err = schedule.NextDutyOf("carol", now, vacation)
+	if err != nil || !shift.Start.Equal(day(6, 9)) {
+		t.Errorf("NextDutyOf(carol) с отпуском = %+v, %v", shift, err)
+	}
+	partial := []models.OnCallOverride{{User: models.User{Login: "dave"}, StartsAt: day(3, 12), EndsAt: day(3, 18)}}
+	if shift, err := schedule.NextDutyOf("carol", now, partial); err == nil {
+		t.Errorf("NextDutyOf(carol): ожидалась ошибка, получена смена %+v", shift)
+	}
+}
+
+func TestSaveOnCallScheduleKeepsStartsAt(t *testing.T) {
+	if err := migrations.AutoMigrate(); err != nil {
+		t.Skipf("база данных недоступна: %v", err)
+	}
+
+	label := fmt.Sprintf("team=oncall-test-%d", time.Now().UnixNano())
+	startsAt := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	schedule := &models.OnCallSchedule{
+		Label: label, Rotation: "daily", HandoffTime: "10:00", Timezone: "UTC",
+		Members: "alice,bob", StartsAt: startsAt,
+	}
+	if err := repository.SaveOnCallSchedule(schedule); err != nil {
+		t.Fatalf("SaveOnCallSchedule: %v", err)
+	}
+
+	// повторное сохранение с новым участником не сдвигает начало ротации
+	schedule = &models.OnCallSchedule{
+		Label: label, Rotation: "daily", HandoffTime: "10:00", Timezone: "UTC",
+		Members: "alice,bob,carol", StartsAt: time.Now(),
+	}
+	if err := repository.SaveOnCallSchedule(schedule); err != nil {
+		t.Fatalf("SaveOnCallSchedule: %v", err)
+	}
+
+	stored, err := repository.GetOnCallSchedule(label)
+	if err != nil {
+		t.Fatalf("GetOnCallSchedule: %v", err)
+	}
+	if stored.Members != "alice,bob,carol" {
+		t.Errorf("участники %q, ожидались alice,bob,carol", stored.Members)
+	}
+	if !stored.StartsAt.Equal(startsAt) {
+		t.Errorf("начало ротации %s, ожидалось %s", stored.StartsAt, startsAt)
+	}
+}
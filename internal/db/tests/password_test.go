@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"chatops/internal/db/repository"
+	"testing"
+)
+
+func TestPasswordHashing(t *testing.T) {
+	hash, err := repository.HashPassword("s3cret")
+	if err != nil {
+		t.Fatalf("Ошибка хеширования пароля: %v", err)
+	}
+	if hash == "s3cret" || !repository.IsPasswordHashed(hash) {
+		t.Fatalf("Ожидался bcrypt-хеш, получено: %s", hash)
+	}
+	if !repository.CheckPassword(hash, "s3cret") {
+		t.Error("Верный пароль не прошел проверку")
+	}
+	if repository.CheckPassword(hash, "wrong") {
+		t.Error("Неверный пароль прошел проверку")
+	}
+}
+
+func TestPasswordPlaintextFallback(t *testing.T) {
+	if repository.IsPasswordHashed("password123") {
+		t.Fatal("Открытый пароль определен как хеш")
+	}
+	if !repository.CheckPassword("password123", "password123") {
+		t.Error("Старый пароль в открытом виде должен проходить проверку до миграции")
+	}
+	if repository.CheckPassword("password123", "password1234") {
+		t.Error("Неверный пароль прошел проверку")
+	}
+}
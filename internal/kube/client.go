@@ -3,14 +3,16 @@ package kube
 import (
 	"context"
 	"fmt"
+	"io"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -34,17 +36,42 @@ type PodLogsOptions struct {
 	SinceSeconds int64
 	// Timestamps добавляет временные метки к логам
 	Timestamps bool
+	// Container выбирает контейнер пода, пустое значение - контейнер по умолчанию
+	// (аннотация kubectl.kubernetes.io/default-container или первый контейнер)
+	Container string
+	// Follow продолжает поток новыми строками (только для StreamPodLogs)
+	Follow bool
+}
+
+// ListPodsOptions сужает список подов
+type ListPodsOptions struct {
+	// LabelSelector - селектор меток, например app=api,tier!=cache
+	LabelSelector string
+	// FieldSelector - селектор полей, например status.phase=Running или spec.nodeName=node-1
+	FieldSelector string
+	// Deployment оставляет только поды, принадлежащие Deployment
+	Deployment string
 }
 
 type K8sClientInterface interface {
 	GetPodStatus(ctx context.Context, namespace, podName string) (string, error)
+	GetPodInfo(ctx context.Context, namespace, podName string) (*PodInfo, error)
 	ScaleDeploymentWithLogs(ctx context.Context, namespace, name string, replicas int32, logCh chan<- string) error
 	RollbackDeploymentWithLogs(ctx context.Context, namespace, name string, revision int64, logCh chan<- string) error
 	RestartDeploymentWithLogs(ctx context.Context, namespace, name string, logCh chan<- string) error
 	ListAvailableRevisions(ctx context.Context, namespace, deploymentName string) ([]RevisionInfo, error)
+	ScaleWorkloadWithLogs(ctx context.Context, w Workload, replicas int32, logCh chan<- string) error
+	RestartWorkloadWithLogs(ctx context.Context, w Workload, logCh chan<- string) error
+	RollbackWorkloadWithLogs(ctx context.Context, w Workload, revision int64, logCh chan<- string) error
+	ListWorkloadRevisions(ctx context.Context, w Workload) ([]RevisionInfo, error)
 	GetClientset() kubernetes.Interface
 	GetPodLogs(ctx context.Context, namespace, podName string, opts *PodLogsOptions) (string, error)
-	ListPods(ctx context.Context, namespace string) ([]string, error)
+	StreamPodLogs(ctx context.Context, namespace, podName string, opts *PodLogsOptions) (io.ReadCloser, error)
+	GetDeploymentLogs(ctx context.Context, namespace, name string, opts *PodLogsOptions) ([]LogLine, error)
+	ListPods(ctx context.Context, namespace string, opts *ListPodsOptions) ([]PodInfo, error)
+	GetDeploymentStatus(ctx context.Context, namespace, name string) (*DeploymentStatus, error)
+	ListEvents(ctx context.Context, namespace string, opts *ListEventsOptions) ([]EventInfo, error)
+	WatchWarningEvents(ctx context.Context, namespace string) (<-chan EventInfo, error)
 }
 
 type K8sClient struct {
@@ -420,15 +447,11 @@ func (c *K8sClient) GetPodLogs(ctx context.Context, namespace, podName string, o
 		return "", fmt.Errorf("client not initialized")
 	}
 
-	podLogOptions := &corev1.PodLogOptions{}
-	if opts != nil {
-		podLogOptions.Previous = opts.Previous
-		podLogOptions.TailLines = &opts.TailLines
-		podLogOptions.SinceSeconds = &opts.SinceSeconds
-		podLogOptions.Timestamps = opts.Timestamps
+	opts, err := c.resolveContainer(ctx, namespace, podName, opts)
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения логов пода %s: %v", podName, err)
 	}
-
-	logs, err := c.clientset.CoreV1().Pods(namespace).GetLogs(podName, podLogOptions).DoRaw(ctx)
+	logs, err := c.clientset.CoreV1().Pods(namespace).GetLogs(podName, podLogOptions(opts)).DoRaw(ctx)
 	if err != nil {
 		return "", fmt.Errorf("ошибка получения логов пода %s: %v", podName, err)
 	}
@@ -436,21 +459,69 @@ func (c *K8sClient) GetPodLogs(ctx context.Context, namespace, podName string, o
 	return string(logs), nil
 }
 
-// ListPods возвращает список имен подов в указанном namespace
-func (c *K8sClient) ListPods(ctx context.Context, namespace string) ([]string, error) {
+// ListPods возвращает состояния подов namespace, отсортированные по имени.
+// opts сужает список селекторами меток и полей и Deployment-владельцем.
+func (c *K8sClient) ListPods(ctx context.Context, namespace string, opts *ListPodsOptions) ([]PodInfo, error) {
 	if c.clientset == nil {
 		return nil, fmt.Errorf("client not initialized")
 	}
+	if opts == nil {
+		opts = &ListPodsOptions{}
+	}
 
-	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный селектор меток %q: %v", opts.LabelSelector, err)
+	}
+	if _, err := fields.ParseSelector(opts.FieldSelector); err != nil {
+		return nil, fmt.Errorf("некорректный селектор полей %q: %v", opts.FieldSelector, err)
+	}
+
+	// поды Deployment ищутся по его селектору и затем по ReplicaSet-владельцу,
+	// чтобы не захватить поды других Deployment с пересекающимися метками
+	var replicaSets map[string]bool
+	if opts.Deployment != "" {
+		dep, err := c.clientset.AppsV1().Deployments(namespace).Get(ctx, opts.Deployment, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		depSelector, err := metav1.LabelSelectorAsSelector(dep.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		requirements, _ := depSelector.Requirements()
+		selector = selector.Add(requirements...)
+
+		rsList, err := c.clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: depSelector.String()})
+		if err != nil {
+			return nil, fmt.Errorf("ошибка получения ReplicaSet: %v", err)
+		}
+		replicaSets = make(map[string]bool)
+		for _, rs := range rsList.Items {
+			if owner := metav1.GetControllerOf(&rs); owner != nil && owner.Kind == "Deployment" && owner.Name == dep.Name {
+				replicaSets[rs.Name] = true
+			}
+		}
+	}
+
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+		FieldSelector: opts.FieldSelector,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения списка подов: %v", err)
 	}
 
-	var podList []string
+	infos := make([]PodInfo, 0, len(pods.Items))
 	for _, pod := range pods.Items {
-		podList = append(podList, pod.Name)
+		if replicaSets != nil {
+			owner := metav1.GetControllerOf(&pod)
+			if owner == nil || owner.Kind != "ReplicaSet" || !replicaSets[owner.Name] {
+				continue
+			}
+		}
+		infos = append(infos, podInfo(pod))
 	}
-
-	return podList, nil
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
 }
@@ -0,0 +1,201 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Виды контейнеров пода
+const (
+	ContainerRegular   = "regular"
+	ContainerInit      = "init"
+	ContainerEphemeral = "ephemeral"
+)
+
+// Состояния контейнера
+const (
+	ContainerRunning    = "Running"
+	ContainerWaiting    = "Waiting"
+	ContainerTerminated = "Terminated"
+)
+
+// defaultContainerAnnotation выбирает контейнер по умолчанию для логов, как в kubectl
+const defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+// ContainerState - состояние контейнера пода
+type ContainerState struct {
+	Name     string
+	Kind     string // ContainerRegular, ContainerInit или ContainerEphemeral
+	Image    string
+	Ready    bool
+	State    string // ContainerRunning, ContainerWaiting, ContainerTerminated или пусто, если статуса еще нет
+	Reason   string // причина ожидания (CrashLoopBackOff, ImagePullBackOff) или завершения (OOMKilled, Completed)
+	Message  string
+	ExitCode int32 // код выхода, если контейнер завершен
+	Restarts int32
+	// LastTerminationReason и LastExitCode описывают предыдущий запуск контейнера
+	LastTerminationReason string
+	LastExitCode          int32
+	LastTerminatedAt      time.Time
+}
+
+// GetPodInfo возвращает состояние пода с причиной и состоянием каждого контейнера
+func (c *K8sClient) GetPodInfo(ctx context.Context, namespace, podName string) (*PodInfo, error) {
+	if c.clientset == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+	pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	info := podInfo(*pod)
+	return &info, nil
+}
+
+// containerStates собирает состояния init, обычных и ephemeral контейнеров пода в порядке спецификации
+func containerStates(pod corev1.Pod) []ContainerState {
+	var states []ContainerState
+	add := func(kind string, names, images []string, statuses []corev1.ContainerStatus) {
+		byName := make(map[string]corev1.ContainerStatus, len(statuses))
+		for _, s := range statuses {
+			byName[s.Name] = s
+		}
+		for i, name := range names {
+			state := ContainerState{Name: name, Kind: kind, Image: images[i]}
+			if s, ok := byName[name]; ok {
+				fillContainerState(&state, s)
+			}
+			states = append(states, state)
+		}
+	}
+
+	var names, images []string
+	for _, ct := range pod.Spec.InitContainers {
+		names, images = append(names, ct.Name), append(images, ct.Image)
+	}
+	add(ContainerInit, names, images, pod.Status.InitContainerStatuses)
+
+	names, images = nil, nil
+	for _, ct := range pod.Spec.Containers {
+		names, images = append(names, ct.Name), append(images, ct.Image)
+	}
+	add(ContainerRegular, names, images, pod.Status.ContainerStatuses)
+
+	names, images = nil, nil
+	for _, ct := range pod.Spec.EphemeralContainers {
+		names, images = append(names, ct.Name), append(images, ct.Image)
+	}
+	add(ContainerEphemeral, names, images, pod.Status.EphemeralContainerStatuses)
+	return states
+}
+
+func fillContainerState(state *ContainerState, s corev1.ContainerStatus) {
+	state.Ready = s.Ready
+	state.Restarts = s.RestartCount
+	switch {
+	case s.State.Running != nil:
+		state.State = ContainerRunning
+	case s.State.Waiting != nil:
+		state.State = ContainerWaiting
+		state.Reason = s.State.Waiting.Reason
+		state.Message = s.State.Waiting.Message
+	case s.State.Terminated != nil:
+		state.State = ContainerTerminated
+		state.Reason = s.State.Terminated.Reason
+		state.Message = s.State.Terminated.Message
+		state.ExitCode = s.State.Terminated.ExitCode
+	}
+	if last := s.LastTerminationState.Terminated; last != nil {
+		state.LastTerminationReason = last.Reason
+		state.LastExitCode = last.ExitCode
+		state.LastTerminatedAt = last.FinishedAt.Time
+	}
+}
+
+// podStatusReason возвращает состояние пода так же, как колонка STATUS в kubectl get pods:
+// Init:1/2, Init:CrashLoopBackOff, CrashLoopBackOff, OOMKilled, Terminating и т.д.
+func podStatusReason(pod corev1.Pod) string {
+	reason := string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		reason = pod.Status.Reason
+	}
+
+	initializing := false
+	for i, s := range pod.Status.InitContainerStatuses {
+		switch {
+		case s.State.Terminated != nil && s.State.Terminated.ExitCode == 0:
+			continue
+		case s.State.Terminated != nil:
+			if s.State.Terminated.Reason != "" {
+				reason = "Init:" + s.State.Terminated.Reason
+			} else {
+				reason = fmt.Sprintf("Init:ExitCode:%d", s.State.Terminated.ExitCode)
+			}
+		case s.State.Waiting != nil && s.State.Waiting.Reason != "" && s.State.Waiting.Reason != "PodInitializing":
+			reason = "Init:" + s.State.Waiting.Reason
+		default:
+			reason = fmt.Sprintf("Init:%d/%d", i, len(pod.Spec.InitContainers))
+		}
+		initializing = true
+		break
+	}
+
+	if !initializing {
+		hasRunning := false
+		for i := len(pod.Status.ContainerStatuses) - 1; i >= 0; i-- {
+			s := pod.Status.ContainerStatuses[i]
+			switch {
+			case s.State.Waiting != nil && s.State.Waiting.Reason != "":
+				reason = s.State.Waiting.Reason
+			case s.State.Terminated != nil && s.State.Terminated.Reason != "":
+				reason = s.State.Terminated.Reason
+			case s.State.Terminated != nil:
+				reason = fmt.Sprintf("ExitCode:%d", s.State.Terminated.ExitCode)
+			case s.Ready && s.State.Running != nil:
+				hasRunning = true
+			}
+		}
+		// часть контейнеров завершилась, но остальные еще работают
+		if reason == "Completed" && hasRunning {
+			reason = string(corev1.PodRunning)
+		}
+	}
+
+	if pod.DeletionTimestamp != nil {
+		reason = "Terminating"
+	}
+	return reason
+}
+
+// defaultContainer выбирает контейнер для логов, если он не указан: по аннотации
+// kubectl.kubernetes.io/default-container, иначе первый контейнер пода
+func defaultContainer(pod *corev1.Pod) string {
+	if name := pod.Annotations[defaultContainerAnnotation]; name != "" {
+		return name
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
+
+// resolveContainer подставляет контейнер по умолчанию, если под содержит несколько контейнеров
+func (c *K8sClient) resolveContainer(ctx context.Context, namespace, podName string, opts *PodLogsOptions) (*PodLogsOptions, error) {
+	if opts != nil && opts.Container != "" {
+		return opts, nil
+	}
+	pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	resolved := PodLogsOptions{}
+	if opts != nil {
+		resolved = *opts
+	}
+	resolved.Container = defaultContainer(pod)
+	return &resolved, nil
+}
@@ -0,0 +1,181 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// warningEventsSelector выбирает события типа Warning
+const warningEventsSelector = "type=" + corev1.EventTypeWarning
+
+// ListEventsOptions сужает список событий
+type ListEventsOptions struct {
+	// Name - объект, к которому относятся события. Для Deployment добавляются
+	// события его ReplicaSet и их подов, в том числе уже удаленных
+	Name string
+	// Kind - вид объекта Name (KindStatefulSet, KindDaemonSet); для них добавляются события их подов.
+	// Пустой вид или Deployment - Deployment, а если его нет - любой объект с именем Name
+	Kind string
+	// WarningsOnly оставляет только события типа Warning
+	WarningsOnly bool
+}
+
+// ListEvents возвращает события namespace или объекта, от новых к старым
+func (c *K8sClient) ListEvents(ctx context.Context, namespace string, opts *ListEventsOptions) ([]EventInfo, error) {
+	if c.clientset == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+	if opts == nil {
+		opts = &ListEventsOptions{}
+	}
+
+	match := func(corev1.ObjectReference) bool { return true }
+	switch {
+	case opts.Name == "":
+	case opts.Kind == KindStatefulSet || opts.Kind == KindDaemonSet:
+		match = workloadEventObjects(opts.Kind, opts.Name)
+	default:
+		objects, err := c.deploymentEventObjects(ctx, namespace, opts.Name)
+		switch {
+		case apierrors.IsNotFound(err):
+			// не Deployment: события любого объекта с таким именем (под, StatefulSet, Service)
+			match = func(ref corev1.ObjectReference) bool { return ref.Name == opts.Name }
+		case err != nil:
+			return nil, err
+		default:
+			match = objects
+		}
+	}
+
+	listOpts := metav1.ListOptions{}
+	if opts.WarningsOnly {
+		listOpts.FieldSelector = warningEventsSelector
+	}
+	events, err := c.clientset.CoreV1().Events(namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения событий: %v", err)
+	}
+
+	var infos []EventInfo
+	for _, e := range events.Items {
+		if opts.WarningsOnly && e.Type != corev1.EventTypeWarning {
+			continue
+		}
+		if match(e.InvolvedObject) {
+			infos = append(infos, eventInfo(e))
+		}
+	}
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].Time.After(infos[j].Time) })
+	return infos, nil
+}
+
+// deploymentEventObjects возвращает проверку, относится ли событие к Deployment, его ReplicaSet
+// или их подам. Поды определяются по префиксу имени ReplicaSet, чтобы учесть уже замененные поды.
+func (c *K8sClient) deploymentEventObjects(ctx context.Context, namespace, name string) (func(corev1.ObjectReference) bool, error) {
+	dep, err := c.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	replicaSets, err := c.clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения ReplicaSet: %v", err)
+	}
+	owned := make(map[string]bool)
+	for _, rs := range replicaSets.Items {
+		if owner := metav1.GetControllerOf(&rs); owner != nil && owner.Kind == "Deployment" && owner.Name == dep.Name {
+			owned[rs.Name] = true
+		}
+	}
+
+	return func(ref corev1.ObjectReference) bool {
+		switch ref.Kind {
+		case "Deployment":
+			return ref.Name == dep.Name
+		case "ReplicaSet":
+			return owned[ref.Name]
+		case "Pod":
+			if i := strings.LastIndex(ref.Name, "-"); i > 0 {
+				return owned[ref.Name[:i]]
+			}
+		}
+		return false
+	}, nil
+}
+
+// workloadEventObjects возвращает проверку, относится ли событие к StatefulSet или DaemonSet
+// или к их подам: name-<номер> у StatefulSet и name-<суффикс> у DaemonSet
+func workloadEventObjects(kind, name string) func(corev1.ObjectReference) bool {
+	return func(ref corev1.ObjectReference) bool {
+		switch ref.Kind {
+		case kind:
+			return ref.Name == name
+		case "Pod":
+			suffix, ok := strings.CutPrefix(ref.Name, name+"-")
+			if !ok || suffix == "" || strings.Contains(suffix, "-") {
+				return false
+			}
+			if kind == KindStatefulSet {
+				return strings.Trim(suffix, "0123456789") == ""
+			}
+			return true
+		}
+		return false
+	}
+}
+
+// WatchWarningEvents следит за новыми событиями Warning в namespace. Уже произошедшие события
+// не передаются. Канал закрывается при отмене ctx или разрыве watch, после чего watch нужно открыть заново.
+func (c *K8sClient) WatchWarningEvents(ctx context.Context, namespace string) (<-chan EventInfo, error) {
+	if c.clientset == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+	events := c.clientset.CoreV1().Events(namespace)
+	list, err := events.List(ctx, metav1.ListOptions{FieldSelector: warningEventsSelector})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения событий: %v", err)
+	}
+	w, err := events.Watch(ctx, metav1.ListOptions{
+		FieldSelector:   warningEventsSelector,
+		ResourceVersion: list.ResourceVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подписки на события: %v", err)
+	}
+
+	ch := make(chan EventInfo)
+	go func() {
+		defer close(ch)
+		defer w.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-w.ResultChan():
+				if !ok {
+					return
+				}
+				// Modified приходит, когда событие повторяется и растет его счетчик
+				if ev.Type != watch.Added && ev.Type != watch.Modified {
+					continue
+				}
+				e, ok := ev.Object.(*corev1.Event)
+				if !ok || e.Type != corev1.EventTypeWarning {
+					continue
+				}
+				select {
+				case ch <- eventInfo(*e):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
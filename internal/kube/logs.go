@@ -0,0 +1,140 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LogLine - строка лога пода с временной меткой Kubernetes
+type LogLine struct {
+	Time time.Time // нулевое, если метки нет
+	Pod  string
+	Text string
+}
+
+func podLogOptions(opts *PodLogsOptions) *corev1.PodLogOptions {
+	podLogOptions := &corev1.PodLogOptions{}
+	if opts == nil {
+		return podLogOptions
+	}
+	podLogOptions.Previous = opts.Previous
+	podLogOptions.Timestamps = opts.Timestamps
+	podLogOptions.Container = opts.Container
+	podLogOptions.Follow = opts.Follow
+	// нулевые значения означают "без ограничения": API отвергает sinceSeconds=0
+	if opts.TailLines > 0 {
+		podLogOptions.TailLines = &opts.TailLines
+	}
+	if opts.SinceSeconds > 0 {
+		podLogOptions.SinceSeconds = &opts.SinceSeconds
+	}
+	return podLogOptions
+}
+
+// StreamPodLogs открывает поток логов пода; с opts.Follow поток продолжается новыми строками,
+// пока не будет закрыт или не отменен ctx
+func (c *K8sClient) StreamPodLogs(ctx context.Context, namespace, podName string, opts *PodLogsOptions) (io.ReadCloser, error) {
+	if c.clientset == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+	opts, err := c.resolveContainer(ctx, namespace, podName, opts)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения логов пода %s: %v", podName, err)
+	}
+	stream, err := c.clientset.CoreV1().Pods(namespace).GetLogs(podName, podLogOptions(opts)).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения логов пода %s: %v", podName, err)
+	}
+	return stream, nil
+}
+
+// GetDeploymentLogs получает логи всех подов Deployment и объединяет их по временным меткам.
+// Ошибка возвращается, только если не удалось получить логи ни одного пода.
+func (c *K8sClient) GetDeploymentLogs(ctx context.Context, namespace, name string, opts *PodLogsOptions) ([]LogLine, error) {
+	if c.clientset == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+	dep, err := c.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(dep.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения подов: %v", err)
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("у deployment %s/%s нет подов", namespace, name)
+	}
+
+	podOpts := PodLogsOptions{}
+	if opts != nil {
+		podOpts = *opts
+	}
+	podOpts.Timestamps = true
+	podOpts.Follow = false
+
+	streams := make([][]LogLine, len(pods.Items))
+	errs := make([]error, len(pods.Items))
+	var wg sync.WaitGroup
+	for i, pod := range pods.Items {
+		wg.Add(1)
+		go func(i int, pod string) {
+			defer wg.Done()
+			var logs string
+			logs, errs[i] = c.GetPodLogs(ctx, namespace, pod, &podOpts)
+			streams[i] = ParseLogLines(pod, logs)
+		}(i, pod.Name)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err == nil {
+			return MergeLogLines(streams...), nil
+		}
+	}
+	return nil, errs[0]
+}
+
+// ParseLogLines разбирает логи пода, полученные с Timestamps. Строки без метки
+// (например, продолжение многострочного сообщения) получают метку предыдущей строки.
+func ParseLogLines(pod, logs string) []LogLine {
+	var lines []LogLine
+	var last time.Time
+	for _, text := range strings.Split(strings.TrimRight(logs, "\n"), "\n") {
+		if text == "" && len(lines) == 0 {
+			continue
+		}
+		line := LogLine{Time: last, Pod: pod, Text: text}
+		if stamp, rest, ok := strings.Cut(text, " "); ok {
+			if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+				line.Time, line.Text = t, rest
+				last = t
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// MergeLogLines объединяет логи нескольких подов в порядке временных меток,
+// сохраняя порядок строк внутри каждого пода
+func MergeLogLines(streams ...[]LogLine) []LogLine {
+	var merged []LogLine
+	for _, lines := range streams {
+		merged = append(merged, lines...)
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Time.Before(merged[j].Time) })
+	return merged
+}
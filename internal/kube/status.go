@@ -0,0 +1,204 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Состояния выкатки Deployment
+const (
+	RolloutComplete    = "complete"
+	RolloutProgressing = "progressing"
+	RolloutFailed      = "failed"
+)
+
+// maxDeploymentEvents ограничивает число последних событий в DeploymentStatus
+const maxDeploymentEvents = 5
+
+// DeploymentStatus - состояние Deployment, его подов и последние события
+type DeploymentStatus struct {
+	Name           string
+	Namespace      string
+	Desired        int32
+	Ready          int32
+	Updated        int32
+	Available      int32
+	Revision       int64
+	Images         []string
+	Rollout        string // RolloutComplete, RolloutProgressing или RolloutFailed
+	RolloutMessage string // сообщение условия Progressing
+	Pods           []PodInfo
+	Events         []EventInfo
+}
+
+// PodInfo - состояние пода по данным Kubernetes
+type PodInfo struct {
+	Name            string
+	Phase           string
+	Status          string // причина, как в колонке STATUS kubectl get pods (CrashLoopBackOff, Init:0/1)
+	Ready           bool
+	ReadyContainers int // готовые обычные контейнеры, как в колонке READY kubectl get pods
+	TotalContainers int
+	Restarts        int32
+	NodeName        string
+	IP              string
+	CreatedAt       time.Time
+	StartedAt       time.Time
+	Containers      []ContainerState
+}
+
+// EventInfo - событие Kubernetes
+type EventInfo struct {
+	Time    time.Time
+	Type    string // Normal или Warning
+	Reason  string
+	Object  string // kind/name объекта
+	Message string
+	Count   int32
+}
+
+// GetDeploymentStatus возвращает реплики, ревизию, образы, состояние выкатки,
+// поды и последние события Deployment
+func (c *K8sClient) GetDeploymentStatus(ctx context.Context, namespace, name string) (*DeploymentStatus, error) {
+	if c.clientset == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+	dep, err := c.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	status := &DeploymentStatus{
+		Name:      dep.Name,
+		Namespace: dep.Namespace,
+		Desired:   1,
+		Ready:     dep.Status.ReadyReplicas,
+		Updated:   dep.Status.UpdatedReplicas,
+		Available: dep.Status.AvailableReplicas,
+	}
+	if dep.Spec.Replicas != nil {
+		status.Desired = *dep.Spec.Replicas
+	}
+	status.Revision, _ = strconv.ParseInt(dep.Annotations["deployment.kubernetes.io/revision"], 10, 64)
+	for _, container := range dep.Spec.Template.Spec.Containers {
+		status.Images = append(status.Images, container.Image)
+	}
+	status.Rollout, status.RolloutMessage = rolloutState(dep)
+
+	selector, err := metav1.LabelSelectorAsSelector(dep.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения подов: %v", err)
+	}
+	objects := map[string]bool{"Deployment/" + dep.Name: true}
+	for _, pod := range pods.Items {
+		status.Pods = append(status.Pods, podInfo(pod))
+		objects["Pod/"+pod.Name] = true
+	}
+	sort.Slice(status.Pods, func(i, j int) bool { return status.Pods[i].Name < status.Pods[j].Name })
+
+	replicaSets, err := c.clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err == nil {
+		for _, rs := range replicaSets.Items {
+			objects["ReplicaSet/"+rs.Name] = true
+		}
+	}
+
+	// события не критичны для статуса, ошибку их получения не возвращаем
+	if events, err := c.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		for _, e := range events.Items {
+			object := e.InvolvedObject.Kind + "/" + e.InvolvedObject.Name
+			if objects[object] {
+				status.Events = append(status.Events, eventInfo(e))
+			}
+		}
+		sort.Slice(status.Events, func(i, j int) bool { return status.Events[i].Time.After(status.Events[j].Time) })
+		if len(status.Events) > maxDeploymentEvents {
+			status.Events = status.Events[:maxDeploymentEvents]
+		}
+	}
+
+	return status, nil
+}
+
+// rolloutState определяет состояние выкатки по условиям Deployment, как kubectl rollout status
+func rolloutState(dep *appsv1.Deployment) (string, string) {
+	var message string
+	for _, cond := range dep.Status.Conditions {
+		if cond.Type != appsv1.DeploymentProgressing {
+			continue
+		}
+		message = cond.Message
+		if cond.Reason == "ProgressDeadlineExceeded" {
+			return RolloutFailed, message
+		}
+	}
+
+	desired := int32(1)
+	if dep.Spec.Replicas != nil {
+		desired = *dep.Spec.Replicas
+	}
+	if dep.Generation > dep.Status.ObservedGeneration ||
+		dep.Status.UpdatedReplicas < desired ||
+		dep.Status.Replicas > dep.Status.UpdatedReplicas ||
+		dep.Status.AvailableReplicas < dep.Status.UpdatedReplicas {
+		return RolloutProgressing, message
+	}
+	return RolloutComplete, message
+}
+
+func podInfo(pod corev1.Pod) PodInfo {
+	info := PodInfo{
+		Name:            pod.Name,
+		Phase:           string(pod.Status.Phase),
+		Status:          podStatusReason(pod),
+		TotalContainers: len(pod.Spec.Containers),
+		NodeName:        pod.Spec.NodeName,
+		IP:              pod.Status.PodIP,
+		CreatedAt:       pod.CreationTimestamp.Time,
+		Containers:      containerStates(pod),
+	}
+	if pod.Status.StartTime != nil {
+		info.StartedAt = pod.Status.StartTime.Time
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			info.Ready = cond.Status == corev1.ConditionTrue
+		}
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		info.Restarts += cs.RestartCount
+		if cs.Ready {
+			info.ReadyContainers++
+		}
+	}
+	return info
+}
+
+func eventInfo(e corev1.Event) EventInfo {
+	t := e.LastTimestamp.Time
+	if t.IsZero() {
+		t = e.EventTime.Time
+	}
+	if t.IsZero() {
+		t = e.CreationTimestamp.Time
+	}
+	return EventInfo{
+		Time:    t,
+		Type:    e.Type,
+		Reason:  e.Reason,
+		Object:  e.InvolvedObject.Kind + "/" + e.InvolvedObject.Name,
+		Message: e.Message,
+		Count:   e.Count,
+	}
+}
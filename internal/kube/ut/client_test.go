@@ -846,18 +846,117 @@ func TestListPods(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			pods, err := testClient.ListPods(context.Background(), tt.namespace)
+			pods, err := testClient.ListPods(context.Background(), tt.namespace, nil)
 			if tt.expectError {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.ElementsMatch(t, tt.expected, pods)
+				names := []string{}
+				for _, pod := range pods {
+					names = append(names, pod.Name)
+				}
+				assert.ElementsMatch(t, tt.expected, names)
 			}
 		})
 	}
 }
 
+func TestListPods_Selectors(t *testing.T) {
+	created := time.Now().Add(-2 * time.Hour)
+	apiPod := func(name, rs string, labels map[string]string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "prod",
+				Labels:            labels,
+				CreationTimestamp: metav1.Time{Time: created},
+			},
+			Spec: corev1.PodSpec{
+				NodeName:   "node-1",
+				Containers: []corev1.Container{{Name: "app"}, {Name: "sidecar"}},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				PodIP: "10.0.0.1",
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "app", Ready: true, RestartCount: 1},
+					{Name: "sidecar", Ready: false, RestartCount: 2},
+				},
+			},
+		}
+		if rs != "" {
+			pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: rs, Controller: boolPtr(true)}}
+		}
+		return pod
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "prod"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+		},
+	}
+	ownedRS := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "api-5d4f",
+			Namespace:       "prod",
+			Labels:          map[string]string{"app": "api"},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+		},
+	}
+	client := kube.NewTestClient(fake.NewSimpleClientset(
+		deployment, ownedRS,
+		apiPod("api-5d4f-b", "api-5d4f", map[string]string{"app": "api", "tier": "web"}),
+		apiPod("api-5d4f-a", "api-5d4f", map[string]string{"app": "api", "tier": "worker"}),
+		// под с теми же метками, но принадлежащий чужому ReplicaSet
+		apiPod("api-canary-x", "api-canary", map[string]string{"app": "api", "tier": "web"}),
+		apiPod("db-0", "", map[string]string{"app": "db"}),
+	))
+
+	names := func(pods []kube.PodInfo) []string {
+		result := []string{}
+		for _, pod := range pods {
+			result = append(result, pod.Name)
+		}
+		return result
+	}
+
+	pods, err := client.ListPods(context.Background(), "prod", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"api-5d4f-a", "api-5d4f-b", "api-canary-x", "db-0"}, names(pods))
+
+	pods, err = client.ListPods(context.Background(), "prod", &kube.ListPodsOptions{LabelSelector: "app=api,tier!=worker"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"api-5d4f-b", "api-canary-x"}, names(pods))
+
+	pods, err = client.ListPods(context.Background(), "prod", &kube.ListPodsOptions{Deployment: "api"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"api-5d4f-a", "api-5d4f-b"}, names(pods))
+
+	pods, err = client.ListPods(context.Background(), "prod", &kube.ListPodsOptions{Deployment: "api", LabelSelector: "tier=web"})
+	assert.NoError(t, err)
+	if assert.Equal(t, []string{"api-5d4f-b"}, names(pods)) {
+		pod := pods[0]
+		assert.Equal(t, 1, pod.ReadyContainers)
+		assert.Equal(t, 2, pod.TotalContainers)
+		assert.Equal(t, int32(3), pod.Restarts)
+		assert.Equal(t, "node-1", pod.NodeName)
+		assert.Equal(t, "10.0.0.1", pod.IP)
+		assert.WithinDuration(t, created, pod.CreatedAt, time.Second)
+	}
+
+	_, err = client.ListPods(context.Background(), "prod", &kube.ListPodsOptions{Deployment: "missing"})
+	assert.Error(t, err)
+	_, err = client.ListPods(context.Background(), "prod", &kube.ListPodsOptions{LabelSelector: "app in (api"})
+	assert.Error(t, err)
+	_, err = client.ListPods(context.Background(), "prod", &kube.ListPodsOptions{FieldSelector: "status.phase"})
+	assert.Error(t, err)
+}
+
 // Вспомогательная функция для создания указателя на int32
 func int32Ptr(i int32) *int32 {
 	return &i
 }
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,168 @@
+package k8sclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"chatops/internal/kube"
+)
+
+func waiting(reason string) corev1.ContainerState {
+	return corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}
+}
+
+func terminated(reason string, code int32) corev1.ContainerState {
+	return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: reason, ExitCode: code}}
+}
+
+var running = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+
+func testPod(name string, init, containers []corev1.ContainerStatus) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod"},
+		Status: corev1.PodStatus{
+			Phase:                 corev1.PodRunning,
+			InitContainerStatuses: init,
+			ContainerStatuses:     containers,
+		},
+	}
+	for _, s := range init {
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{Name: s.Name, Image: s.Name + ":1"})
+	}
+	for _, s := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: s.Name, Image: s.Name + ":1"})
+	}
+	return pod
+}
+
+func TestGetPodInfo_StatusReason(t *testing.T) {
+	terminating := testPod("terminating", nil, []corev1.ContainerStatus{{Name: "app", State: running, Ready: true}})
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	terminating.Finalizers = []string{"test"}
+
+	tests := []struct {
+		pod    *corev1.Pod
+		status string
+	}{
+		{testPod("running", nil, []corev1.ContainerStatus{{Name: "app", State: running, Ready: true}}), "Running"},
+		{testPod("crashloop", nil, []corev1.ContainerStatus{
+			{Name: "app", State: running, Ready: true},
+			{Name: "sidecar", State: waiting("CrashLoopBackOff"), RestartCount: 4},
+		}), "CrashLoopBackOff"},
+		{testPod("image", nil, []corev1.ContainerStatus{{Name: "app", State: waiting("ImagePullBackOff")}}), "ImagePullBackOff"},
+		{testPod("oom", nil, []corev1.ContainerStatus{{Name: "app", State: terminated("OOMKilled", 137)}}), "OOMKilled"},
+		{testPod("exit", nil, []corev1.ContainerStatus{{Name: "app", State: terminated("", 2)}}), "ExitCode:2"},
+		{testPod("init-progress", []corev1.ContainerStatus{
+			{Name: "migrate", State: terminated("Completed", 0)},
+			{Name: "warmup", State: running},
+		}, []corev1.ContainerStatus{{Name: "app", State: waiting("PodInitializing")}}), "Init:1/2"},
+		{testPod("init-crash", []corev1.ContainerStatus{
+			{Name: "migrate", State: waiting("CrashLoopBackOff"), RestartCount: 3},
+		}, []corev1.ContainerStatus{{Name: "app", State: waiting("PodInitializing")}}), "Init:CrashLoopBackOff"},
+		{testPod("job-sidecar", nil, []corev1.ContainerStatus{
+			{Name: "job", State: terminated("Completed", 0)},
+			{Name: "proxy", State: running, Ready: true},
+		}), "Running"},
+		{terminating, "Terminating"},
+	}
+
+	var objects []runtime.Object
+	for _, tt := range tests {
+		objects = append(objects, tt.pod)
+	}
+	client := kube.NewTestClient(fake.NewSimpleClientset(objects...))
+
+	for _, tt := range tests {
+		t.Run(tt.pod.Name, func(t *testing.T) {
+			info, err := client.GetPodInfo(context.Background(), "prod", tt.pod.Name)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, info.Status)
+		})
+	}
+
+	pods, err := client.ListPods(context.Background(), "prod", nil)
+	require.NoError(t, err)
+	require.Len(t, pods, len(tests))
+	assert.Equal(t, "crashloop", pods[0].Name)
+}
+
+func TestGetPodInfo_Containers(t *testing.T) {
+	pod := testPod("api", []corev1.ContainerStatus{
+		{Name: "migrate", State: terminated("Completed", 0)},
+	}, []corev1.ContainerStatus{
+		{Name: "app", State: running, Ready: true, RestartCount: 2, LastTerminationState: terminated("OOMKilled", 137)},
+		{Name: "sidecar", State: waiting("CrashLoopBackOff"), RestartCount: 5},
+	})
+	pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox"}}}
+	client := kube.NewTestClient(fake.NewSimpleClientset(pod))
+
+	info, err := client.GetPodInfo(context.Background(), "prod", "api")
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), info.Restarts)
+	require.Len(t, info.Containers, 4)
+
+	migrate, app, sidecar, debugger := info.Containers[0], info.Containers[1], info.Containers[2], info.Containers[3]
+	assert.Equal(t, kube.ContainerInit, migrate.Kind)
+	assert.Equal(t, kube.ContainerTerminated, migrate.State)
+	assert.Equal(t, "Completed", migrate.Reason)
+
+	assert.Equal(t, kube.ContainerRegular, app.Kind)
+	assert.Equal(t, kube.ContainerRunning, app.State)
+	assert.True(t, app.Ready)
+	assert.Equal(t, "OOMKilled", app.LastTerminationReason)
+	assert.Equal(t, int32(137), app.LastExitCode)
+
+	assert.Equal(t, kube.ContainerWaiting, sidecar.State)
+	assert.Equal(t, "CrashLoopBackOff", sidecar.Reason)
+	assert.Equal(t, int32(5), sidecar.Restarts)
+
+	assert.Equal(t, kube.ContainerEphemeral, debugger.Kind)
+	assert.Equal(t, "busybox", debugger.Image)
+	assert.Empty(t, debugger.State, "ephemeral container without status")
+
+	_, err = client.GetPodInfo(context.Background(), "prod", "missing")
+	assert.Error(t, err)
+}
+
+func TestGetPodLogs_ContainerSelection(t *testing.T) {
+	pod := testPod("api", nil, []corev1.ContainerStatus{{Name: "app"}, {Name: "sidecar"}})
+	annotated := testPod("annotated", nil, []corev1.ContainerStatus{{Name: "proxy"}, {Name: "app"}})
+	annotated.Annotations = map[string]string{"kubectl.kubernetes.io/default-container": "app"}
+	fakeClient := fake.NewSimpleClientset(pod, annotated)
+	client := kube.NewTestClient(fakeClient)
+
+	logContainer := func() string {
+		actions := fakeClient.Actions()
+		for i := len(actions) - 1; i >= 0; i-- {
+			if action, ok := actions[i].(k8stesting.GenericActionImpl); ok && action.GetSubresource() == "log" {
+				return action.Value.(*corev1.PodLogOptions).Container
+			}
+		}
+		t.Fatal("no log request")
+		return ""
+	}
+
+	_, err := client.GetPodLogs(context.Background(), "prod", "api", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "app", logContainer(), "first container by default")
+
+	_, err = client.GetPodLogs(context.Background(), "prod", "api", &kube.PodLogsOptions{Container: "sidecar"})
+	require.NoError(t, err)
+	assert.Equal(t, "sidecar", logContainer())
+
+	_, err = client.GetPodLogs(context.Background(), "prod", "annotated", &kube.PodLogsOptions{TailLines: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "app", logContainer(), "default-container annotation")
+
+	_, err = client.GetPodLogs(context.Background(), "prod", "missing", nil)
+	assert.Error(t, err)
+}
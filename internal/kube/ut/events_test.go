@@ -0,0 +1,125 @@
+package k8sclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"chatops/internal/kube"
+)
+
+func testEvent(name, eventType, reason, kind, object string, at time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "prod"},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        reason + " " + object,
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Name: object, Namespace: "prod"},
+		LastTimestamp:  metav1.Time{Time: at},
+		Count:          1,
+	}
+}
+
+func TestListEvents(t *testing.T) {
+	now := time.Now()
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "prod"}}
+	replicaSet := func(name string) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "prod",
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+		}}
+	}
+	client := kube.NewTestClient(fake.NewSimpleClientset(
+		deployment,
+		replicaSet("api-5d4f"),
+		replicaSet("api-7c8b"),
+		testEvent("e1", corev1.EventTypeNormal, "ScalingReplicaSet", "Deployment", "api", now.Add(-5*time.Minute)),
+		testEvent("e2", corev1.EventTypeNormal, "SuccessfulCreate", "ReplicaSet", "api-7c8b", now.Add(-4*time.Minute)),
+		testEvent("e3", corev1.EventTypeWarning, "FailedScheduling", "Pod", "api-7c8b-x2x9q", now.Add(-3*time.Minute)),
+		// под старого ReplicaSet уже удален, но его события остаются
+		testEvent("e4", corev1.EventTypeWarning, "BackOff", "Pod", "api-5d4f-k8s7d", now.Add(-2*time.Minute)),
+		testEvent("e5", corev1.EventTypeWarning, "BackOff", "Pod", "api-gateway-6f9d-abcde", now.Add(-time.Minute)),
+		testEvent("e6", corev1.EventTypeWarning, "FailedMount", "Pod", "db-0", now),
+	))
+
+	reasons := func(events []kube.EventInfo) []string {
+		result := []string{}
+		for _, e := range events {
+			result = append(result, e.Reason+" "+e.Object)
+		}
+		return result
+	}
+
+	events, err := client.ListEvents(context.Background(), "prod", nil)
+	require.NoError(t, err)
+	assert.Len(t, events, 6)
+	assert.Equal(t, "FailedMount Pod/db-0", reasons(events)[0])
+
+	events, err = client.ListEvents(context.Background(), "prod", &kube.ListEventsOptions{Name: "api"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"BackOff Pod/api-5d4f-k8s7d",
+		"FailedScheduling Pod/api-7c8b-x2x9q",
+		"SuccessfulCreate ReplicaSet/api-7c8b",
+		"ScalingReplicaSet Deployment/api",
+	}, reasons(events))
+
+	events, err = client.ListEvents(context.Background(), "prod", &kube.ListEventsOptions{Name: "api", WarningsOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BackOff Pod/api-5d4f-k8s7d", "FailedScheduling Pod/api-7c8b-x2x9q"}, reasons(events))
+
+	// не Deployment: события объекта с таким именем
+	events, err = client.ListEvents(context.Background(), "prod", &kube.ListEventsOptions{Name: "db-0"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"FailedMount Pod/db-0"}, reasons(events))
+
+	// StatefulSet: события самого объекта и его подов по номерам
+	events, err = client.ListEvents(context.Background(), "prod", &kube.ListEventsOptions{Name: "db", Kind: kube.KindStatefulSet})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"FailedMount Pod/db-0"}, reasons(events))
+
+	events, err = client.ListEvents(context.Background(), "prod", &kube.ListEventsOptions{Name: "api", Kind: kube.KindStatefulSet})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	events, err = client.ListEvents(context.Background(), "staging", nil)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestWatchWarningEvents(t *testing.T) {
+	clientset := fake.NewSimpleClientset(testEvent("old", corev1.EventTypeWarning, "BackOff", "Pod", "api-0", time.Now()))
+	client := kube.NewTestClient(clientset)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := client.WatchWarningEvents(ctx, "prod")
+	require.NoError(t, err)
+
+	// watch фейкового клиента открывается асинхронно
+	time.Sleep(100 * time.Millisecond)
+	eventsAPI := clientset.CoreV1().Events("prod")
+	_, err = eventsAPI.Create(context.Background(), testEvent("normal", corev1.EventTypeNormal, "Pulled", "Pod", "api-1", time.Now()), metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = eventsAPI.Create(context.Background(), testEvent("unhealthy", corev1.EventTypeWarning, "Unhealthy", "Pod", "api-1", time.Now()), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	select {
+	case e := <-events:
+		assert.Equal(t, "Unhealthy", e.Reason)
+		assert.Equal(t, "Pod/api-1", e.Object)
+	case <-time.After(2 * time.Second):
+		t.Fatal("warning event was not delivered")
+	}
+
+	cancel()
+	for range events {
+	}
+}
@@ -0,0 +1,87 @@
+package k8sclient
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"chatops/internal/kube"
+)
+
+func TestParseLogLines(t *testing.T) {
+	logs := "2024-05-01T10:00:00.000000001Z started\n" +
+		"2024-05-01T10:00:02Z panic: boom\n" +
+		"goroutine 1 [running]:\n" +
+		"2024-05-01T10:00:03Z restarting\n"
+
+	lines := kube.ParseLogLines("api-1", logs)
+	require.Len(t, lines, 4)
+	assert.Equal(t, "started", lines[0].Text)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 1, time.UTC), lines[0].Time)
+	assert.Equal(t, "api-1", lines[1].Pod)
+	assert.Equal(t, "goroutine 1 [running]:", lines[2].Text)
+	assert.Equal(t, lines[1].Time, lines[2].Time, "continuation line inherits previous timestamp")
+
+	assert.Empty(t, kube.ParseLogLines("api-1", ""))
+}
+
+func TestMergeLogLines(t *testing.T) {
+	a := kube.ParseLogLines("api-a", "2024-05-01T10:00:00Z a1\n2024-05-01T10:00:02Z a2\ncontinued\n2024-05-01T10:00:04Z a3")
+	b := kube.ParseLogLines("api-b", "2024-05-01T10:00:01Z b1\n2024-05-01T10:00:02Z b2\n2024-05-01T10:00:05Z b3")
+
+	var got []string
+	for _, l := range kube.MergeLogLines(a, b) {
+		got = append(got, l.Pod+":"+l.Text)
+	}
+	assert.Equal(t, []string{
+		"api-a:a1", "api-b:b1", "api-a:a2", "api-a:continued", "api-b:b2", "api-a:a3", "api-b:b3",
+	}, got)
+}
+
+func TestGetDeploymentLogs(t *testing.T) {
+	labels := map[string]string{"app": "api"}
+	pod := func(name string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod", Labels: labels}}
+	}
+	client := kube.NewTestClient(fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "prod"},
+			Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+		},
+		pod("api-1", labels),
+		pod("api-2", labels),
+		pod("worker-1", map[string]string{"app": "worker"}),
+	))
+
+	lines, err := client.GetDeploymentLogs(context.Background(), "prod", "api", &kube.PodLogsOptions{TailLines: 10})
+	require.NoError(t, err)
+	var pods []string
+	for _, l := range lines {
+		pods = append(pods, l.Pod)
+	}
+	assert.ElementsMatch(t, []string{"api-1", "api-2"}, pods)
+
+	_, err = client.GetDeploymentLogs(context.Background(), "prod", "missing", nil)
+	assert.Error(t, err)
+}
+
+func TestStreamPodLogs(t *testing.T) {
+	client := kube.NewTestClient(fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "prod"},
+	}))
+
+	stream, err := client.StreamPodLogs(context.Background(), "prod", "api-1", &kube.PodLogsOptions{Follow: true, TailLines: 10})
+	require.NoError(t, err)
+	defer stream.Close()
+	data, err := io.ReadAll(stream)
+	require.NoError(t, err)
+	assert.NotEmpty(t, data)
+}
@@ -0,0 +1,137 @@
+package k8sclient
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"chatops/internal/kube"
+)
+
+func TestGetDeploymentStatus(t *testing.T) {
+	labels := map[string]string{"app": "api"}
+	now := time.Now()
+	objects := []runtime.Object{
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "api",
+				Namespace:   "prod",
+				Generation:  3,
+				Annotations: map[string]string{"deployment.kubernetes.io/revision": "7"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: int32Ptr(3),
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "api", Image: "registry/api:1.7"}}},
+				},
+			},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: 3,
+				Replicas:           3,
+				ReadyReplicas:      2,
+				UpdatedReplicas:    3,
+				AvailableReplicas:  2,
+				Conditions: []appsv1.DeploymentCondition{{
+					Type:    appsv1.DeploymentProgressing,
+					Reason:  "ReplicaSetUpdated",
+					Message: `ReplicaSet "api-7" is progressing.`,
+				}},
+			},
+		},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "api-7", Namespace: "prod", Labels: labels}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-7-b", Namespace: "prod", Labels: labels},
+			Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				ContainerStatuses: []corev1.ContainerStatus{{RestartCount: 2}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-7-a", Namespace: "prod", Labels: labels},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Namespace: "prod", Labels: map[string]string{"app": "worker"}},
+		},
+	}
+	// События пода, ReplicaSet и Deployment попадают в статус, событие чужого пода - нет
+	for i, object := range []corev1.ObjectReference{
+		{Kind: "Pod", Name: "api-7-a"},
+		{Kind: "ReplicaSet", Name: "api-7"},
+		{Kind: "Deployment", Name: "api"},
+		{Kind: "Pod", Name: "worker-1"},
+		{Kind: "Pod", Name: "api-7-b"},
+		{Kind: "Pod", Name: "api-7-b"},
+		{Kind: "Pod", Name: "api-7-b"},
+	} {
+		objects = append(objects, &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: fmt.Sprintf("event-%d", i), Namespace: "prod"},
+			InvolvedObject: object,
+			Type:           corev1.EventTypeNormal,
+			Reason:         fmt.Sprintf("Reason%d", i),
+			LastTimestamp:  metav1.NewTime(now.Add(-time.Duration(i) * time.Minute)),
+		})
+	}
+	client := kube.NewTestClient(fake.NewSimpleClientset(objects...))
+
+	status, err := client.GetDeploymentStatus(context.Background(), "prod", "api")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), status.Desired)
+	assert.Equal(t, int32(2), status.Ready)
+	assert.Equal(t, int32(3), status.Updated)
+	assert.Equal(t, int64(7), status.Revision)
+	assert.Equal(t, []string{"registry/api:1.7"}, status.Images)
+	assert.Equal(t, kube.RolloutProgressing, status.Rollout)
+	assert.Equal(t, `ReplicaSet "api-7" is progressing.`, status.RolloutMessage)
+
+	require.Len(t, status.Pods, 2)
+	assert.Equal(t, "api-7-a", status.Pods[0].Name)
+	assert.False(t, status.Pods[0].Ready)
+	assert.Equal(t, "api-7-b", status.Pods[1].Name)
+	assert.True(t, status.Pods[1].Ready)
+	assert.Equal(t, int32(2), status.Pods[1].Restarts)
+
+	require.Len(t, status.Events, 5)
+	assert.Equal(t, "Pod/api-7-a", status.Events[0].Object)
+	assert.Equal(t, "ReplicaSet/api-7", status.Events[1].Object)
+	assert.Equal(t, "Deployment/api", status.Events[2].Object)
+	for _, e := range status.Events {
+		assert.NotEqual(t, "Pod/worker-1", e.Object)
+	}
+
+	_, err = client.GetDeploymentStatus(context.Background(), "prod", "missing")
+	assert.Error(t, err)
+}
+
+func TestGetDeploymentStatus_RolloutFailed(t *testing.T) {
+	client := kube.NewTestClient(fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "prod"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(1),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+		},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{
+				Type:    appsv1.DeploymentProgressing,
+				Reason:  "ProgressDeadlineExceeded",
+				Message: "progress deadline exceeded",
+			}},
+		},
+	}))
+
+	status, err := client.GetDeploymentStatus(context.Background(), "prod", "api")
+	require.NoError(t, err)
+	assert.Equal(t, kube.RolloutFailed, status.Rollout)
+	assert.Empty(t, status.Pods)
+}
@@ -0,0 +1,251 @@
+package k8sclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"chatops/internal/kube"
+)
+
+func TestParseWorkload(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected kube.Workload
+		wantErr  bool
+	}{
+		{"prod/api", kube.Workload{Kind: kube.KindDeployment, Namespace: "prod", Name: "api"}, false},
+		{"deploy/prod/api", kube.Workload{Kind: kube.KindDeployment, Namespace: "prod", Name: "api"}, false},
+		{"sts/prod/db", kube.Workload{Kind: kube.KindStatefulSet, Namespace: "prod", Name: "db"}, false},
+		{"StatefulSet/prod/db", kube.Workload{Kind: kube.KindStatefulSet, Namespace: "prod", Name: "db"}, false},
+		{"daemonset/monitoring/node-exporter", kube.Workload{Kind: kube.KindDaemonSet, Namespace: "monitoring", Name: "node-exporter"}, false},
+		{"cronjob/prod/backup", kube.Workload{}, true},
+		{"prod", kube.Workload{}, true},
+		{"prod/", kube.Workload{}, true},
+		{"sts/prod/db/extra", kube.Workload{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			w, err := kube.ParseWorkload(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, w)
+		})
+	}
+}
+
+// drainLogs читает сообщения операции, пока канал не будет закрыт
+func drainLogs(logCh chan string) <-chan []string {
+	done := make(chan []string, 1)
+	go func() {
+		var logs []string
+		for msg := range logCh {
+			logs = append(logs, msg)
+		}
+		done <- logs
+	}()
+	return done
+}
+
+func testStatefulSet(replicas int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "db", Image: "postgres:16"}}}},
+		},
+		Status: appsv1.StatefulSetStatus{
+			Replicas:        replicas,
+			ReadyReplicas:   replicas,
+			UpdatedReplicas: replicas,
+			CurrentRevision: "db-2",
+			UpdateRevision:  "db-2",
+		},
+	}
+}
+
+func testDaemonSet() *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "monitoring"},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "agent"}},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "agent", Image: "agent:3"}}}},
+		},
+		Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberAvailable: 2},
+	}
+}
+
+// controllerRevision сохраняет шаблон пода так же, как контроллеры StatefulSet и DaemonSet
+func controllerRevision(t *testing.T, owner metav1.Object, kind, name string, revision int64, image string, labels map[string]string) *appsv1.ControllerRevision {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec":   map[string]interface{}{"containers": []map[string]interface{}{{"name": "main", "image": image}}},
+				"$patch": "replace",
+			},
+		},
+	})
+	require.NoError(t, err)
+	return &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       owner.GetNamespace(),
+			Labels:          labels,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(owner, appsv1.SchemeGroupVersion.WithKind(kind))},
+		},
+		Data:     runtime.RawExtension{Raw: data},
+		Revision: revision,
+	}
+}
+
+func TestScaleWorkloadWithLogs(t *testing.T) {
+	client := kube.NewTestClient(fake.NewSimpleClientset(testStatefulSet(3), testDaemonSet()))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// фейковый клиент не меняет статус, поэтому масштабируем до уже готового числа реплик
+	logCh := make(chan string)
+	logs := drainLogs(logCh)
+	err := client.ScaleWorkloadWithLogs(ctx, kube.Workload{Kind: kube.KindStatefulSet, Namespace: "prod", Name: "db"}, 3, logCh)
+	require.NoError(t, err)
+	assert.Contains(t, <-logs, "✅ Масштабирование завершено успешно.")
+
+	logCh = make(chan string)
+	logs = drainLogs(logCh)
+	err = client.ScaleWorkloadWithLogs(ctx, kube.Workload{Kind: kube.KindDaemonSet, Namespace: "monitoring", Name: "agent"}, 3, logCh)
+	assert.Error(t, err)
+	<-logs
+}
+
+func TestScaleWorkloadWithLogs_RetriesOnConflict(t *testing.T) {
+	clientset := fake.NewSimpleClientset(testStatefulSet(3))
+	conflicts := 0
+	clientset.PrependReactor("update", "statefulsets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		return true, nil, apierrors.NewConflict(appsv1.Resource("statefulsets"), "db", errors.New("object has been modified"))
+	})
+	client := kube.NewTestClient(clientset)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	logCh := make(chan string)
+	logs := drainLogs(logCh)
+	err := client.ScaleWorkloadWithLogs(ctx, kube.Workload{Kind: kube.KindStatefulSet, Namespace: "prod", Name: "db"}, 3, logCh)
+	require.NoError(t, err)
+	assert.Equal(t, 1, conflicts)
+	assert.Contains(t, <-logs, "✅ Масштабирование завершено успешно.")
+}
+
+func TestRestartWorkloadWithLogs(t *testing.T) {
+	clientset := fake.NewSimpleClientset(testStatefulSet(2), testDaemonSet())
+	client := kube.NewTestClient(clientset)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, w := range []kube.Workload{
+		{Kind: kube.KindStatefulSet, Namespace: "prod", Name: "db"},
+		{Kind: kube.KindDaemonSet, Namespace: "monitoring", Name: "agent"},
+	} {
+		logCh := make(chan string)
+		logs := drainLogs(logCh)
+		require.NoError(t, client.RestartWorkloadWithLogs(ctx, w, logCh), w.String())
+		assert.Contains(t, <-logs, "✅ Rollout завершён успешно.")
+	}
+
+	sts, err := clientset.AppsV1().StatefulSets("prod").Get(ctx, "db", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, sts.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"])
+	ds, err := clientset.AppsV1().DaemonSets("monitoring").Get(ctx, "agent", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, ds.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"])
+}
+
+func TestStatefulSetRevisionsAndRollback(t *testing.T) {
+	sts := testStatefulSet(2)
+	other := testStatefulSet(1)
+	other.Name = "cache"
+	labels := map[string]string{"app": "db"}
+	clientset := fake.NewSimpleClientset(sts,
+		controllerRevision(t, sts, "StatefulSet", "db-1", 1, "postgres:14", labels),
+		controllerRevision(t, sts, "StatefulSet", "db-3", 3, "postgres:16", labels),
+		controllerRevision(t, sts, "StatefulSet", "db-2", 2, "postgres:15", labels),
+		// ревизия другого StatefulSet с теми же метками
+		controllerRevision(t, other, "StatefulSet", "cache-1", 1, "redis:7", labels),
+	)
+	client := kube.NewTestClient(clientset)
+	w := kube.Workload{Kind: kube.KindStatefulSet, Namespace: "prod", Name: "db"}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	revisions, err := client.ListWorkloadRevisions(ctx, w)
+	require.NoError(t, err)
+	assert.Equal(t, []kube.RevisionInfo{
+		{Revision: 1, RSName: "db-1", Image: "postgres:14"},
+		{Revision: 2, RSName: "db-2", Image: "postgres:15"},
+		{Revision: 3, RSName: "db-3", Image: "postgres:16"},
+	}, revisions)
+
+	// без номера - откат к предыдущей ревизии
+	logCh := make(chan string)
+	logs := drainLogs(logCh)
+	require.NoError(t, client.RollbackWorkloadWithLogs(ctx, w, 0, logCh))
+	assert.Contains(t, <-logs, "[rollback] Откат StatefulSet prod/db успешно завершен")
+	updated, err := clientset.AppsV1().StatefulSets("prod").Get(ctx, "db", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "postgres:15", updated.Spec.Template.Spec.Containers[0].Image)
+
+	logCh = make(chan string)
+	logs = drainLogs(logCh)
+	require.NoError(t, client.RollbackWorkloadWithLogs(ctx, w, 1, logCh))
+	<-logs
+	updated, err = clientset.AppsV1().StatefulSets("prod").Get(ctx, "db", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "postgres:14", updated.Spec.Template.Spec.Containers[0].Image)
+
+	for _, revision := range []int64{3, 7} {
+		logCh = make(chan string)
+		logs = drainLogs(logCh)
+		assert.Error(t, client.RollbackWorkloadWithLogs(ctx, w, revision, logCh), "revision %d", revision)
+		<-logs
+	}
+}
+
+func TestDaemonSetRollback(t *testing.T) {
+	ds := testDaemonSet()
+	labels := map[string]string{"app": "agent"}
+	clientset := fake.NewSimpleClientset(ds,
+		controllerRevision(t, ds, "DaemonSet", "agent-1", 1, "agent:2", labels),
+		controllerRevision(t, ds, "DaemonSet", "agent-2", 2, "agent:3", labels),
+	)
+	client := kube.NewTestClient(clientset)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	logCh := make(chan string)
+	logs := drainLogs(logCh)
+	require.NoError(t, client.RollbackWorkloadWithLogs(ctx, kube.Workload{Kind: kube.KindDaemonSet, Namespace: "monitoring", Name: "agent"}, 0, logCh))
+	<-logs
+	updated, err := clientset.AppsV1().DaemonSets("monitoring").Get(ctx, "agent", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "agent:2", updated.Spec.Template.Spec.Containers[0].Image)
+}
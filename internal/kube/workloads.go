@@ -0,0 +1,399 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+// Виды рабочих нагрузок
+const (
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
+	KindDaemonSet   = "DaemonSet"
+)
+
+// workloadKinds - сокращения видов нагрузок, как в kubectl
+var workloadKinds = map[string]string{
+	"deploy":       KindDeployment,
+	"deployment":   KindDeployment,
+	"deployments":  KindDeployment,
+	"sts":          KindStatefulSet,
+	"statefulset":  KindStatefulSet,
+	"statefulsets": KindStatefulSet,
+	"ds":           KindDaemonSet,
+	"daemonset":    KindDaemonSet,
+	"daemonsets":   KindDaemonSet,
+}
+
+// Workload - рабочая нагрузка: Deployment, StatefulSet или DaemonSet
+type Workload struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// ParseWorkload разбирает [вид/]namespace/имя; без вида - Deployment.
+// Вид задается как в kubectl: deploy, deployment, sts, statefulset, ds, daemonset.
+func ParseWorkload(s string) (Workload, error) {
+	parts := strings.Split(s, "/")
+	w := Workload{Kind: KindDeployment}
+	switch len(parts) {
+	case 2:
+		w.Namespace, w.Name = parts[0], parts[1]
+	case 3:
+		kind, ok := workloadKinds[strings.ToLower(parts[0])]
+		if !ok {
+			return Workload{}, fmt.Errorf("неизвестный вид нагрузки %q, поддерживаются deployment, statefulset и daemonset", parts[0])
+		}
+		w.Kind, w.Namespace, w.Name = kind, parts[1], parts[2]
+	default:
+		return Workload{}, fmt.Errorf("ожидается [вид/]namespace/имя, получено %q", s)
+	}
+	if w.Namespace == "" || w.Name == "" {
+		return Workload{}, fmt.Errorf("ожидается [вид/]namespace/имя, получено %q", s)
+	}
+	return w, nil
+}
+
+func (w Workload) String() string {
+	return fmt.Sprintf("%s %s/%s", w.Kind, w.Namespace, w.Name)
+}
+
+// ScaleWorkloadWithLogs масштабирует Deployment или StatefulSet и ждет готовности реплик.
+// DaemonSet не масштабируется: число его подов определяется узлами.
+func (c *K8sClient) ScaleWorkloadWithLogs(ctx context.Context, w Workload, replicas int32, logCh chan<- string) error {
+	switch w.Kind {
+	case KindDeployment:
+		return c.ScaleDeploymentWithLogs(ctx, w.Namespace, w.Name, replicas, logCh)
+	case KindDaemonSet:
+		close(logCh)
+		return fmt.Errorf("DaemonSet %s/%s нельзя масштабировать: поды запускаются на подходящих узлах", w.Namespace, w.Name)
+	}
+
+	defer close(logCh)
+	if c.clientset == nil {
+		return fmt.Errorf("client not initialized")
+	}
+	log := logFunc(logCh)
+
+	log(fmt.Sprintf("🔧 Масштабируем %s до %d реплик...", w, replicas))
+	// StatefulSet перечитывается на каждой попытке: контроллер постоянно обновляет его статус
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		sts, err := c.clientset.AppsV1().StatefulSets(w.Namespace).Get(ctx, w.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		sts.Spec.Replicas = &replicas
+		_, err = c.clientset.AppsV1().StatefulSets(w.Namespace).Update(ctx, sts, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		log(fmt.Sprintf("Ошибка обновления %s: %v", w, err))
+		return err
+	}
+
+	log("🚀 Масштабирование запущено...")
+
+	// поды StatefulSet создаются и удаляются по одному, по порядку номеров
+	return wait.PollUntilContextCancel(ctx, 2*time.Second, true, func(ctx context.Context) (bool, error) {
+		updated, err := c.clientset.AppsV1().StatefulSets(w.Namespace).Get(ctx, w.Name, metav1.GetOptions{})
+		if err != nil {
+			log(fmt.Sprintf("Ошибка чтения %s: %v", w, err))
+			return false, err
+		}
+
+		log(fmt.Sprintf("🌀 Статус: готово %d/%d реплик, всего %d", updated.Status.ReadyReplicas, replicas, updated.Status.Replicas))
+
+		if updated.Status.ReadyReplicas == replicas && updated.Status.Replicas == replicas {
+			log("✅ Масштабирование завершено успешно.")
+			return true, nil
+		}
+		return false, nil
+	})
+}
+
+// RestartWorkloadWithLogs перезапускает поды нагрузки, как kubectl rollout restart, и ждет завершения выкатки
+func (c *K8sClient) RestartWorkloadWithLogs(ctx context.Context, w Workload, logCh chan<- string) error {
+	if w.Kind == KindDeployment {
+		return c.RestartDeploymentWithLogs(ctx, w.Namespace, w.Name, logCh)
+	}
+
+	defer close(logCh)
+	if c.clientset == nil {
+		return fmt.Errorf("client not initialized")
+	}
+	log := logFunc(logCh)
+
+	restartedAt := time.Now().Format(time.RFC3339Nano)
+	err := c.updatePodTemplate(ctx, w, func(template *corev1.PodTemplateSpec) {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations["kubectl.kubernetes.io/restartedAt"] = restartedAt
+	})
+	if err != nil {
+		log(fmt.Sprintf("Ошибка обновления %s: %v", w, err))
+		return err
+	}
+
+	log(fmt.Sprintf("🚀 Rollout restart %s запущен...", w))
+
+	return c.waitWorkloadRollout(ctx, w, log, "✅ Rollout завершён успешно.")
+}
+
+// RollbackWorkloadWithLogs откатывает нагрузку к ревизии; revision <= 0 - к предыдущей.
+// Ревизии StatefulSet и DaemonSet хранятся в ControllerRevision, откат восстанавливает из нее шаблон пода.
+func (c *K8sClient) RollbackWorkloadWithLogs(ctx context.Context, w Workload, revision int64, logCh chan<- string) error {
+	if w.Kind == KindDeployment {
+		return c.RollbackDeploymentWithLogs(ctx, w.Namespace, w.Name, revision, logCh)
+	}
+
+	defer close(logCh)
+	if c.clientset == nil {
+		return fmt.Errorf("client not initialized")
+	}
+	log := logFunc(logCh)
+
+	log(fmt.Sprintf("[rollback] Получаем ревизии %s...", w))
+	revisions, err := c.controllerRevisions(ctx, w)
+	if err != nil {
+		log(fmt.Sprintf("[rollback] Ошибка получения ревизий: %v", err))
+		return err
+	}
+	log(fmt.Sprintf("[rollback] Найдено ревизий: %d", len(revisions)))
+	if len(revisions) < 2 {
+		log("[rollback] Недостаточно ревизий для отката (нужно минимум 2)")
+		return fmt.Errorf("недостаточно ревизий для отката (нужно минимум 2)")
+	}
+
+	// текущая ревизия - последняя: при откате контроллер переносит выбранную ревизию в конец истории
+	current := revisions[len(revisions)-1]
+	target := revisions[len(revisions)-2]
+	if revision > 0 {
+		found := false
+		for _, cr := range revisions {
+			if cr.Revision == revision {
+				target, found = cr, true
+				break
+			}
+		}
+		if !found {
+			log(fmt.Sprintf("[rollback] Ревизия %d не найдена", revision))
+			return fmt.Errorf("ревизия %d не найдена", revision)
+		}
+		if target.Revision == current.Revision {
+			log(fmt.Sprintf("[rollback] Ревизия %d уже текущая", revision))
+			return fmt.Errorf("ревизия %d уже текущая", revision)
+		}
+	}
+	log(fmt.Sprintf("[rollback] Целевая ревизия: %d (%s)", target.Revision, target.Name))
+
+	template, err := revisionTemplate(target)
+	if err != nil {
+		log(fmt.Sprintf("[rollback] Ошибка чтения ревизии %s: %v", target.Name, err))
+		return err
+	}
+
+	log(fmt.Sprintf("[rollback] Обновляем %s шаблоном пода из ревизии %d...", w, target.Revision))
+	if err := c.updatePodTemplate(ctx, w, func(t *corev1.PodTemplateSpec) { *t = *template }); err != nil {
+		log(fmt.Sprintf("[rollback] Ошибка обновления %s: %v", w, err))
+		return fmt.Errorf("ошибка обновления %s: %v", w, err)
+	}
+
+	log("[rollback] Ожидание завершения отката...")
+	return c.waitWorkloadRollout(ctx, w, log, fmt.Sprintf("[rollback] Откат %s успешно завершен", w))
+}
+
+// ListWorkloadRevisions возвращает ревизии нагрузки по возрастанию: ReplicaSet для Deployment,
+// ControllerRevision для StatefulSet и DaemonSet
+func (c *K8sClient) ListWorkloadRevisions(ctx context.Context, w Workload) ([]RevisionInfo, error) {
+	if w.Kind == KindDeployment {
+		return c.ListAvailableRevisions(ctx, w.Namespace, w.Name)
+	}
+	if c.clientset == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	revisions, err := c.controllerRevisions(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]RevisionInfo, 0, len(revisions))
+	for _, cr := range revisions {
+		info := RevisionInfo{Revision: cr.Revision, RSName: cr.Name}
+		if template, err := revisionTemplate(cr); err == nil && len(template.Spec.Containers) > 0 {
+			info.Image = template.Spec.Containers[0].Image
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func logFunc(logCh chan<- string) func(string) {
+	return func(msg string) {
+		if logCh != nil {
+			logCh <- msg
+		}
+	}
+}
+
+// updatePodTemplate изменяет шаблон пода StatefulSet или DaemonSet, повторяя обновление при конфликте версий
+func (c *K8sClient) updatePodTemplate(ctx context.Context, w Workload, mutate func(*corev1.PodTemplateSpec)) error {
+	var err error
+	for attempt := 0; attempt < 5; attempt++ {
+		switch w.Kind {
+		case KindStatefulSet:
+			var sts *appsv1.StatefulSet
+			if sts, err = c.clientset.AppsV1().StatefulSets(w.Namespace).Get(ctx, w.Name, metav1.GetOptions{}); err != nil {
+				return err
+			}
+			mutate(&sts.Spec.Template)
+			_, err = c.clientset.AppsV1().StatefulSets(w.Namespace).Update(ctx, sts, metav1.UpdateOptions{})
+		case KindDaemonSet:
+			var ds *appsv1.DaemonSet
+			if ds, err = c.clientset.AppsV1().DaemonSets(w.Namespace).Get(ctx, w.Name, metav1.GetOptions{}); err != nil {
+				return err
+			}
+			mutate(&ds.Spec.Template)
+			_, err = c.clientset.AppsV1().DaemonSets(w.Namespace).Update(ctx, ds, metav1.UpdateOptions{})
+		default:
+			return fmt.Errorf("неподдерживаемый вид нагрузки %s", w.Kind)
+		}
+		if !apierrors.IsConflict(err) {
+			return err
+		}
+		time.Sleep(time.Second)
+	}
+	return err
+}
+
+// waitWorkloadRollout ждет завершения выкатки StatefulSet или DaemonSet по тем же условиям, что kubectl rollout status
+func (c *K8sClient) waitWorkloadRollout(ctx context.Context, w Workload, log func(string), done string) error {
+	return wait.PollUntilContextCancel(ctx, 2*time.Second, true, func(ctx context.Context) (bool, error) {
+		var ready bool
+		var progress string
+		switch w.Kind {
+		case KindStatefulSet:
+			sts, err := c.clientset.AppsV1().StatefulSets(w.Namespace).Get(ctx, w.Name, metav1.GetOptions{})
+			if err != nil {
+				log(fmt.Sprintf("Ошибка чтения %s: %v", w, err))
+				return false, err
+			}
+			if sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+				log("ℹ️ Стратегия OnDelete: поды обновятся только после их удаления")
+				return true, nil
+			}
+			ready, progress = statefulSetRolloutDone(sts)
+		case KindDaemonSet:
+			ds, err := c.clientset.AppsV1().DaemonSets(w.Namespace).Get(ctx, w.Name, metav1.GetOptions{})
+			if err != nil {
+				log(fmt.Sprintf("Ошибка чтения %s: %v", w, err))
+				return false, err
+			}
+			if ds.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+				log("ℹ️ Стратегия OnDelete: поды обновятся только после их удаления")
+				return true, nil
+			}
+			ready, progress = daemonSetRolloutDone(ds)
+		default:
+			return false, fmt.Errorf("неподдерживаемый вид нагрузки %s", w.Kind)
+		}
+
+		log("🌀 Прогресс: " + progress)
+		if ready {
+			log(done)
+		}
+		return ready, nil
+	})
+}
+
+func statefulSetRolloutDone(sts *appsv1.StatefulSet) (bool, string) {
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	progress := fmt.Sprintf("обновлено %d/%d, готово %d", sts.Status.UpdatedReplicas, replicas, sts.Status.ReadyReplicas)
+	if sts.Status.ObservedGeneration < sts.Generation || sts.Status.ReadyReplicas < replicas {
+		return false, progress
+	}
+	// при partition обновляются только поды с номером не меньше partition
+	if ru := sts.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil && *ru.Partition > 0 {
+		return sts.Status.UpdatedReplicas >= replicas-*ru.Partition, progress
+	}
+	return sts.Status.UpdateRevision == sts.Status.CurrentRevision, progress
+}
+
+func daemonSetRolloutDone(ds *appsv1.DaemonSet) (bool, string) {
+	desired := ds.Status.DesiredNumberScheduled
+	progress := fmt.Sprintf("обновлено %d/%d узлов, доступно %d", ds.Status.UpdatedNumberScheduled, desired, ds.Status.NumberAvailable)
+	return ds.Status.ObservedGeneration >= ds.Generation &&
+		ds.Status.UpdatedNumberScheduled >= desired &&
+		ds.Status.NumberAvailable >= desired, progress
+}
+
+// controllerRevisions возвращает ControllerRevision, принадлежащие StatefulSet или DaemonSet, по возрастанию ревизии
+func (c *K8sClient) controllerRevisions(ctx context.Context, w Workload) ([]appsv1.ControllerRevision, error) {
+	var selector *metav1.LabelSelector
+	switch w.Kind {
+	case KindStatefulSet:
+		sts, err := c.clientset.AppsV1().StatefulSets(w.Namespace).Get(ctx, w.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = sts.Spec.Selector
+	case KindDaemonSet:
+		ds, err := c.clientset.AppsV1().DaemonSets(w.Namespace).Get(ctx, w.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = ds.Spec.Selector
+	default:
+		return nil, fmt.Errorf("неподдерживаемый вид нагрузки %s", w.Kind)
+	}
+
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := c.clientset.AppsV1().ControllerRevisions(w.Namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения ControllerRevision: %v", err)
+	}
+
+	var revisions []appsv1.ControllerRevision
+	for _, cr := range list.Items {
+		if owner := metav1.GetControllerOf(&cr); owner != nil && owner.Kind == w.Kind && owner.Name == w.Name {
+			revisions = append(revisions, cr)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions, nil
+}
+
+// revisionTemplate извлекает шаблон пода из ControllerRevision: контроллеры сохраняют
+// в ней патч вида {"spec":{"template":{...}}}
+func revisionTemplate(cr appsv1.ControllerRevision) (*corev1.PodTemplateSpec, error) {
+	var data struct {
+		Spec struct {
+			Template *corev1.PodTemplateSpec `json:"template"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(cr.Data.Raw, &data); err != nil {
+		return nil, fmt.Errorf("ошибка разбора ревизии %s: %v", cr.Name, err)
+	}
+	if data.Spec.Template == nil {
+		return nil, fmt.Errorf("ревизия %s не содержит шаблон пода", cr.Name)
+	}
+	return data.Spec.Template, nil
+}
@@ -0,0 +1,106 @@
+package monitoring
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+var catalogNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// QueryCatalog - каталог именованных запросов и дашбордов в YAML:
+//
+//	queries:
+//	  - name: error_rate
+//	    description: Доля ответов 5xx
+//	    expr: sum(rate(http_requests_total{job=~"{{service|regex}}.*", namespace="{{namespace}}", code=~"5.."}[5m]))
+//	dashboards:
+//	  - name: payments
+//	    namespace: prod
+//	    panels: [error_rate]
+type QueryCatalog struct {
+	Queries    []CatalogQuery     `yaml:"queries"`
+	Dashboards []CatalogDashboard `yaml:"dashboards"`
+}
+
+// CatalogQuery - именованный шаблон запроса PromQL (см. RenderQuery)
+type CatalogQuery struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Expr        string `yaml:"expr"`
+	Unit        string `yaml:"unit"`
+}
+
+// CatalogDashboard - набор запросов каталога, выполняемых вместе.
+// Service и Namespace - значения параметров по умолчанию.
+type CatalogDashboard struct {
+	Name      string   `yaml:"name"`
+	Service   string   `yaml:"service"`
+	Namespace string   `yaml:"namespace"`
+	Panels    []string `yaml:"panels"`
+}
+
+// LoadQueryCatalog читает каталог запросов из YAML-файла
+func LoadQueryCatalog(path string) (*QueryCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query catalog: %w", err)
+	}
+	return ParseQueryCatalog(data)
+}
+
+// ParseQueryCatalog разбирает и проверяет каталог запросов
+func ParseQueryCatalog(data []byte) (*QueryCatalog, error) {
+	var catalog QueryCatalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse query catalog: %w", err)
+	}
+	if err := catalog.Validate(); err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+// Validate проверяет имена, шаблоны запросов и ссылки дашбордов на запросы каталога
+func (c *QueryCatalog) Validate() error {
+	queries := make(map[string]bool)
+	for _, q := range c.Queries {
+		if !ValidCatalogName(q.Name) {
+			return fmt.Errorf("invalid query name %q", q.Name)
+		}
+		if queries[q.Name] {
+			return fmt.Errorf("duplicate query %q", q.Name)
+		}
+		if err := ValidateQueryTemplate(q.Expr); err != nil {
+			return fmt.Errorf("query %s: %w", q.Name, err)
+		}
+		queries[q.Name] = true
+	}
+
+	dashboards := make(map[string]bool)
+	for _, d := range c.Dashboards {
+		if !ValidCatalogName(d.Name) {
+			return fmt.Errorf("invalid dashboard name %q", d.Name)
+		}
+		if dashboards[d.Name] {
+			return fmt.Errorf("duplicate dashboard %q", d.Name)
+		}
+		if len(d.Panels) == 0 {
+			return fmt.Errorf("dashboard %s has no panels", d.Name)
+		}
+		for _, panel := range d.Panels {
+			if !queries[panel] {
+				return fmt.Errorf("dashboard %s: unknown query %q", d.Name, panel)
+			}
+		}
+		dashboards[d.Name] = true
+	}
+	return nil
+}
+
+// ValidCatalogName проверяет имя запроса или дашборда: латиница, цифры, _ и -
+func ValidCatalogName(name string) bool {
+	return catalogNameRe.MatchString(name)
+}
@@ -7,31 +7,123 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
 	"time"
 )
 
-type PrometheusQueryResponse struct {
-	Status string `json:"status"`
-	Data   struct {
-		ResultType string `json:"resultType"`
-		Result     []struct {
-			Metric map[string]string `json:"metric"`
-			Value  []interface{}     `json:"value"`
-		} `json:"result"`
-	} `json:"data"`
-}
-
 type PrometheusLabelResponse struct {
 	Status string   `json:"status"`
 	Data   []string `json:"data"`
 }
 
+// Состояния алерта в Alertmanager
+const (
+	AlertStateActive      = "active"
+	AlertStateSuppressed  = "suppressed"
+	AlertStateUnprocessed = "unprocessed"
+)
+
+// Alert - алерт в формате Alertmanager API v2 (GettableAlert)
 type Alert struct {
-	Labels      map[string]string `json:"labels"`
-	Annotations map[string]string `json:"annotations"`
-	State       string            `json:"state"`
-	ActiveAt    time.Time         `json:"activeAt"`
-	Value       string            `json:"value"`
+	Fingerprint  string            `json:"fingerprint"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Receivers    []AlertReceiver   `json:"receivers"`
+	Status       AlertStatus       `json:"status"`
+}
+
+// AlertStatus - состояние алерта и причины его подавления
+type AlertStatus struct {
+	State       string   `json:"state"`
+	SilencedBy  []string `json:"silencedBy"`
+	InhibitedBy []string `json:"inhibitedBy"`
+}
+
+// AlertReceiver - ресивер Alertmanager, в который маршрутизирован алерт
+type AlertReceiver struct {
+	Name string `json:"name"`
+}
+
+// Silenced сообщает, подавлен ли алерт тишиной
+func (a Alert) Silenced() bool {
+	return len(a.Status.SilencedBy) > 0
+}
+
+// Inhibited сообщает, подавлен ли алерт правилом inhibit
+func (a Alert) Inhibited() bool {
+	return len(a.Status.InhibitedBy) > 0
+}
+
+// Suppressed сообщает, что уведомления по алерту подавлены Alertmanager
+func (a Alert) Suppressed() bool {
+	return a.Status.State == AlertStateSuppressed || a.Silenced() || a.Inhibited()
+}
+
+// AlertFilter - параметры выборки алертов /api/v2/alerts.
+// Пустые поля не передаются, и Alertmanager применяет значения по умолчанию (все алерты).
+type AlertFilter struct {
+	Matchers  []Matcher
+	Active    *bool
+	Silenced  *bool
+	Inhibited *bool
+	Receiver  string // регулярное выражение по имени ресивера
+}
+
+// Query возвращает параметры запроса для фильтра
+func (f AlertFilter) Query() url.Values {
+	q := url.Values{}
+	for _, m := range f.Matchers {
+		q.Add("filter", m.String())
+	}
+	for name, value := range map[string]*bool{"active": f.Active, "silenced": f.Silenced, "inhibited": f.Inhibited} {
+		if value != nil {
+			q.Set(name, strconv.FormatBool(*value))
+		}
+	}
+	if f.Receiver != "" {
+		q.Set("receiver", f.Receiver)
+	}
+	return q
+}
+
+// Matches проверяет алерт на соответствие фильтру так же, как это делает Alertmanager
+func (f AlertFilter) Matches(alert Alert) bool {
+	for _, m := range f.Matchers {
+		if !m.Matches(alert.Labels[m.Name]) {
+			return false
+		}
+	}
+	if f.Silenced != nil && *f.Silenced != alert.Silenced() {
+		return false
+	}
+	if f.Inhibited != nil && *f.Inhibited != alert.Inhibited() {
+		return false
+	}
+	if f.Active != nil && *f.Active != (!alert.Silenced() && !alert.Inhibited()) {
+		return false
+	}
+	if f.Receiver != "" {
+		re, err := regexp.Compile("^(?:" + f.Receiver + ")$")
+		if err != nil {
+			return false
+		}
+		matched := false
+		for _, r := range alert.Receivers {
+			if re.MatchString(r.Name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
 }
 
 type Client struct {
@@ -78,12 +170,14 @@ func NewClient(prometheusURL, alertmanagerURL string) (*Client, error) {
 	}, nil
 }
 
-func (c *Client) GetActiveAlerts(ctx context.Context) ([]Alert, error) {
+// GetActiveAlerts получает алерты из Alertmanager с учетом фильтра
+func (c *Client) GetActiveAlerts(ctx context.Context, filter AlertFilter) ([]Alert, error) {
 	endpoint := fmt.Sprintf("%s/api/v2/alerts", c.alertmanagerURL)
 	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	req.URL.RawQuery = filter.Query().Encode()
 
 	if c.user != "" && c.pass != "" {
 		req.SetBasicAuth(c.user, c.pass)
@@ -119,7 +213,7 @@ func (c *Client) ListMetrics(ctx context.Context, jobName string) ([]string, err
 	}
 
 	q := req.URL.Query()
-	q.Add("match[]", fmt.Sprintf(`{job=~"^%s.*"}`, jobName))
+	q.Add("match[]", NewSelector("").HasPrefix("job", jobName).String())
 	req.URL.RawQuery = q.Encode()
 
 	resp, err := c.httpClient.Do(req)
@@ -144,9 +238,63 @@ func (c *Client) ListMetrics(ctx context.Context, jobName string) ([]string, err
 	return promResp.Data, nil
 }
 
+// Query выполняет instant-запрос PromQL
 func (c *Client) Query(ctx context.Context, query string) (*PrometheusQueryResponse, error) {
-	endpoint := fmt.Sprintf("%s/api/v1/query", c.prometheusURL)
-	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	return c.QueryWithOptions(ctx, query, QueryOptions{})
+}
+
+// QueryOptions - необязательные параметры instant-запроса
+type QueryOptions struct {
+	Time    time.Time     // момент вычисления, по умолчанию - текущее время сервера
+	Timeout time.Duration // таймаут вычисления на стороне Prometheus
+	Limit   int           // максимум возвращаемых рядов (Prometheus 2.54+)
+	Stats   bool          // запросить статистику выполнения (QueryResult.Stats)
+}
+
+// QueryWithOptions выполняет instant-запрос PromQL с дополнительными параметрами
+func (c *Client) QueryWithOptions(ctx context.Context, query string, opts QueryOptions) (*PrometheusQueryResponse, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	if !opts.Time.IsZero() {
+		params.Set("time", strconv.FormatFloat(float64(opts.Time.UnixMilli())/1000, 'f', 3, 64))
+	}
+	if opts.Timeout > 0 {
+		params.Set("timeout", opts.Timeout.String())
+	}
+	if opts.Limit > 0 {
+		params.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Stats {
+		params.Set("stats", "all")
+	}
+	return c.prometheusQuery(ctx, "/api/v1/query", params)
+}
+
+// QueryRange выполняет range-запрос PromQL на интервале [start, end] с шагом step
+func (c *Client) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) ([]Series, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatFloat(float64(start.UnixMilli())/1000, 'f', 3, 64))
+	params.Set("end", strconv.FormatFloat(float64(end.UnixMilli())/1000, 'f', 3, 64))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+
+	promResp, err := c.prometheusQuery(ctx, "/api/v1/query_range", params)
+	if err != nil {
+		return nil, err
+	}
+	if promResp.Data.ResultType != ResultTypeMatrix {
+		return nil, fmt.Errorf("unexpected result type %q, want matrix", promResp.Data.ResultType)
+	}
+	return promResp.Data.Matrix, nil
+}
+
+// prometheusQuery выполняет запрос к API запросов Prometheus. Тело ответа разбирается
+// и при неуспешном HTTP-статусе, чтобы вернуть errorType и error из ответа.
+func (c *Client) prometheusQuery(ctx context.Context, path string, params url.Values) (*PrometheusQueryResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.prometheusURL+path, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -154,10 +302,7 @@ func (c *Client) Query(ctx context.Context, query string) (*PrometheusQueryRespo
 	if c.user != "" && c.pass != "" {
 		req.SetBasicAuth(c.user, c.pass)
 	}
-
-	q := req.URL.Query()
-	q.Add("query", query)
-	req.URL.RawQuery = q.Encode()
+	req.URL.RawQuery = params.Encode()
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -165,16 +310,18 @@ func (c *Client) Query(ctx context.Context, query string) (*PrometheusQueryRespo
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("prometheus returned non-OK status: %s", resp.Status)
-	}
-
 	var promResp PrometheusQueryResponse
 	if err := json.NewDecoder(resp.Body).Decode(&promResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("prometheus returned non-OK status: %s", resp.Status)
+		}
 		return nil, fmt.Errorf("failed to decode prometheus response: %w", err)
 	}
 
 	if promResp.Status != "success" {
+		if promResp.ErrorType != "" || promResp.Error != "" {
+			return nil, &PrometheusError{Type: promResp.ErrorType, Message: promResp.Error}
+		}
 		return nil, fmt.Errorf("prometheus returned non-success status: %s", promResp.Status)
 	}
 
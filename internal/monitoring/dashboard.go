@@ -3,11 +3,23 @@ package monitoring
 import (
 	"context"
 	"fmt"
-	"strconv"
-	"strings"
+	"log"
+	"sort"
 	"sync"
 )
 
+// LimitWarningRatio - доля лимита CPU или памяти, выше которой под считается близким к лимиту
+const LimitWarningRatio = 0.9
+
+// WorkloadPod - под сервиса по данным Kubernetes. Такие поды попадают на дашборд
+// и без scrape-таргетов, а их фаза и готовность заменяют метрики kube-state-metrics.
+type WorkloadPod struct {
+	Name     string
+	Phase    string
+	Ready    bool
+	Restarts int64
+}
+
 type ServiceStatusDashboard struct {
 	ServiceName string
 	Alerts      []Alert
@@ -26,23 +38,66 @@ type PodStatus struct {
 	OOMKilled        bool    // Был ли под убит по OOM
 }
 
-func (c *Client) GetStatusDashboard(ctx context.Context, namespace, jobName string) (*ServiceStatusDashboard, error) {
+// CPURatio возвращает долю использованного лимита CPU, 0 - если лимит не задан
+func (p PodStatus) CPURatio() float64 {
+	if p.CPULimitCores <= 0 {
+		return 0
+	}
+	return p.CPUUsageCores / p.CPULimitCores
+}
+
+// MemoryRatio возвращает долю использованного лимита памяти, 0 - если лимит не задан
+func (p PodStatus) MemoryRatio() float64 {
+	if p.MemoryLimitBytes <= 0 {
+		return 0
+	}
+	return p.MemoryUsageBytes / p.MemoryLimitBytes
+}
+
+// NearLimit сообщает, превышает ли потребление CPU или памяти LimitWarningRatio от лимита
+func (p PodStatus) NearLimit() bool {
+	return p.CPURatio() > LimitWarningRatio || p.MemoryRatio() > LimitWarningRatio
+}
+
+// GetStatusDashboard собирает алерты и метрики подов сервиса. workloadPods - поды из Kubernetes,
+// nil - данных Kubernetes нет; тогда без списка подов из Prometheus дашборд не строится.
+func (c *Client) GetStatusDashboard(ctx context.Context, namespace, jobName string, workloadPods []WorkloadPod) (*ServiceStatusDashboard, error) {
 	dashboard := &ServiceStatusDashboard{
 		ServiceName: jobName,
 		Pods:        []PodStatus{},
 	}
 
+	// Поды из Kubernetes видны и без scrape-таргетов, поды из up - и без доступа к Kubernetes
 	podNames, err := c.getPodNamesForJob(ctx, namespace, jobName)
 	if err != nil {
-		return nil, fmt.Errorf("could not get pod names for job %s: %w", jobName, err)
+		if workloadPods == nil {
+			return nil, fmt.Errorf("could not get pod names for job %s: %w", jobName, err)
+		}
+		log.Printf("Warning: could not get pod names for job %s: %v", jobName, err)
+	}
+	k8sPods := make(map[string]WorkloadPod, len(workloadPods))
+	scraped := make(map[string]bool, len(podNames))
+	for _, name := range podNames {
+		scraped[name] = true
+	}
+	for _, pod := range workloadPods {
+		k8sPods[pod.Name] = pod
+		if !scraped[pod.Name] {
+			podNames = append(podNames, pod.Name)
+		}
 	}
 	if len(podNames) == 0 {
 		return dashboard, nil
 	}
+	sort.Strings(podNames)
 
-	allAlerts, err := c.GetActiveAlerts(ctx)
+	filter := AlertFilter{Matchers: []Matcher{{Name: "job", Value: jobName, IsEqual: true}}}
+	if namespace != "" {
+		filter.Matchers = append(filter.Matchers, Matcher{Name: "namespace", Value: namespace, IsEqual: true})
+	}
+	allAlerts, err := c.GetActiveAlerts(ctx, filter)
 	if err != nil {
-		fmt.Printf("Warning: could not get active alerts: %v\n", err)
+		log.Printf("Warning: could not get active alerts: %v", err)
 	} else {
 		for _, alert := range allAlerts {
 			if alert.Labels["job"] == jobName && (namespace == "" || alert.Labels["namespace"] == namespace) {
@@ -61,77 +116,70 @@ func (c *Client) GetStatusDashboard(ctx context.Context, namespace, jobName stri
 
 	queryAndParse := func(query string, parser func(*PodStatus, float64)) {
 		defer wg.Done()
-		resp, err := c.Query(ctx, query)
+		samples, err := c.queryVector(ctx, query)
 		if err != nil {
-			fmt.Printf("Error querying prometheus for job %s: %v\n", jobName, err)
-			return
-		}
-		if resp.Data.ResultType != "vector" {
+			log.Printf("Error querying prometheus for job %s: %v", jobName, err)
 			return
 		}
 
 		mu.Lock()
 		defer mu.Unlock()
-		for _, result := range resp.Data.Result {
-			podName, ok := result.Metric["pod"]
-			if !ok {
+		for _, sample := range samples {
+			podName, ok := sample.Metric["pod"]
+			if !ok || !IsFinite(sample.Value) {
 				continue
 			}
 			if podStatus, exists := podMetrics[podName]; exists {
-				value, err := strconv.ParseFloat(result.Value[1].(string), 64)
-				if err != nil {
-					continue
-				}
-				parser(podStatus, value)
+				parser(podStatus, sample.Value)
 			}
 		}
 	}
 
 	queryAndParseLabel := func(query, labelName string, parser func(*PodStatus, string)) {
 		defer wg.Done()
-		resp, err := c.Query(ctx, query)
+		samples, err := c.queryVector(ctx, query)
 		if err != nil {
-			fmt.Printf("Error querying prometheus for job %s: %v\n", jobName, err)
-			return
-		}
-		if resp.Data.ResultType != "vector" {
+			log.Printf("Error querying prometheus for job %s: %v", jobName, err)
 			return
 		}
 
 		mu.Lock()
 		defer mu.Unlock()
-		for _, result := range resp.Data.Result {
-			podName, ok := result.Metric["pod"]
+		for _, sample := range samples {
+			podName, ok := sample.Metric["pod"]
 			if !ok {
 				continue
 			}
 			if podStatus, exists := podMetrics[podName]; exists {
-				if labelValue, ok := result.Metric[labelName]; ok {
+				if labelValue, ok := sample.Metric[labelName]; ok {
 					parser(podStatus, labelValue)
 				}
 			}
 		}
 	}
 
-	podsRegex := strings.Join(podNames, "|")
+	// Имена подов попадают в регулярное выражение буквально, метасимволы в них экранируются
+	pods := func(metric string) *Selector {
+		return NewSelector(metric).OneOf("pod", podNames)
+	}
 
 	queries := map[string]func(*PodStatus, float64){
-		fmt.Sprintf(`sum(rate(container_cpu_usage_seconds_total{pod=~"%s", container!="", image!~".*pause.*"}[5m])) by (pod)`, podsRegex): func(ps *PodStatus, v float64) {
+		fmt.Sprintf(`sum(rate(%s[5m])) by (pod)`, pods("container_cpu_usage_seconds_total").Neq("container", "").NotRe("image", ".*pause.*")): func(ps *PodStatus, v float64) {
 			ps.CPUUsageCores = v
 		},
-		fmt.Sprintf(`sum(kube_pod_container_resource_limits{pod=~"%s", resource="cpu"}) by (pod)`, podsRegex): func(ps *PodStatus, v float64) {
+		fmt.Sprintf(`sum(%s) by (pod)`, pods("kube_pod_container_resource_limits").Eq("resource", "cpu")): func(ps *PodStatus, v float64) {
 			ps.CPULimitCores = v
 		},
-		fmt.Sprintf(`sum(container_memory_working_set_bytes{pod=~"%s", container!="", image!~".*pause.*"}) by (pod)`, podsRegex): func(ps *PodStatus, v float64) {
+		fmt.Sprintf(`sum(%s) by (pod)`, pods("container_memory_working_set_bytes").Neq("container", "").NotRe("image", ".*pause.*")): func(ps *PodStatus, v float64) {
 			ps.MemoryUsageBytes = v
 		},
-		fmt.Sprintf(`sum(kube_pod_container_resource_limits{pod=~"%s", resource="memory"}) by (pod)`, podsRegex): func(ps *PodStatus, v float64) {
+		fmt.Sprintf(`sum(%s) by (pod)`, pods("kube_pod_container_resource_limits").Eq("resource", "memory")): func(ps *PodStatus, v float64) {
 			ps.MemoryLimitBytes = v
 		},
-		fmt.Sprintf(`sum(kube_pod_container_status_restarts_total{pod=~"%s"}) by (pod)`, podsRegex): func(ps *PodStatus, v float64) {
+		fmt.Sprintf(`sum(%s) by (pod)`, pods("kube_pod_container_status_restarts_total")): func(ps *PodStatus, v float64) {
 			ps.Restarts = int64(v)
 		},
-		fmt.Sprintf(`kube_pod_status_ready{condition="true", pod=~"%s"}`, podsRegex): func(ps *PodStatus, v float64) {
+		pods("kube_pod_status_ready").Eq("condition", "true").String(): func(ps *PodStatus, v float64) {
 			if v == 1 {
 				ps.Ready = true
 			}
@@ -145,7 +193,7 @@ func (c *Client) GetStatusDashboard(ctx context.Context, namespace, jobName stri
 
 	wg.Add(1)
 	go queryAndParseLabel(
-		fmt.Sprintf(`kube_pod_status_phase{pod=~"%s"} > 0`, podsRegex),
+		fmt.Sprintf(`%s > 0`, pods("kube_pod_status_phase")),
 		"phase",
 		func(ps *PodStatus, phase string) {
 			ps.Phase = phase
@@ -155,19 +203,16 @@ func (c *Client) GetStatusDashboard(ctx context.Context, namespace, jobName stri
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		oomQuery := fmt.Sprintf(`kube_pod_container_status_last_terminated_reason{pod=~"%s", reason="OOMKilled"}`, podsRegex)
-		resp, err := c.Query(ctx, oomQuery)
+		oomQuery := pods("kube_pod_container_status_last_terminated_reason").Eq("reason", "OOMKilled").String()
+		samples, err := c.queryVector(ctx, oomQuery)
 		if err != nil {
-			fmt.Printf("Error querying OOMKilled for job %s: %v\n", jobName, err)
-			return
-		}
-		if resp.Data.ResultType != "vector" {
+			log.Printf("Error querying OOMKilled for job %s: %v", jobName, err)
 			return
 		}
 		mu.Lock()
 		defer mu.Unlock()
-		for _, result := range resp.Data.Result {
-			podName, ok := result.Metric["pod"]
+		for _, sample := range samples {
+			podName, ok := sample.Metric["pod"]
 			if !ok {
 				continue
 			}
@@ -179,7 +224,16 @@ func (c *Client) GetStatusDashboard(ctx context.Context, namespace, jobName stri
 
 	wg.Wait()
 
-	for _, podStatus := range podMetrics {
+	for _, name := range podNames {
+		podStatus := podMetrics[name]
+		// Фаза и готовность из Kubernetes актуальнее метрик kube-state-metrics
+		if pod, ok := k8sPods[name]; ok {
+			podStatus.Phase = pod.Phase
+			podStatus.Ready = pod.Ready
+			if pod.Restarts > podStatus.Restarts {
+				podStatus.Restarts = pod.Restarts
+			}
+		}
 		dashboard.Pods = append(dashboard.Pods, *podStatus)
 	}
 
@@ -187,19 +241,15 @@ func (c *Client) GetStatusDashboard(ctx context.Context, namespace, jobName stri
 }
 
 func (c *Client) getPodNamesForJob(ctx context.Context, namespace, jobName string) ([]string, error) {
-	query := fmt.Sprintf(`up{job=~"^%s.*", namespace="%s"}`, jobName, namespace)
-	resp, err := c.Query(ctx, query)
+	query := NewSelector("up").HasPrefix("job", jobName).Eq("namespace", namespace).String()
+	samples, err := c.queryVector(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.Data.ResultType != "vector" {
-		return nil, fmt.Errorf("unexpected result type for pod name query: %s", resp.Data.ResultType)
-	}
-
 	podNames := make(map[string]struct{})
-	for _, result := range resp.Data.Result {
-		if podName, ok := result.Metric["pod"]; ok {
+	for _, sample := range samples {
+		if podName, ok := sample.Metric["pod"]; ok {
 			podNames[podName] = struct{}{}
 		}
 	}
@@ -211,3 +261,15 @@ func (c *Client) getPodNamesForJob(ctx context.Context, namespace, jobName strin
 
 	return namesList, nil
 }
+
+// queryVector выполняет instant-запрос и возвращает его значения, выводя предупреждения Prometheus
+func (c *Client) queryVector(ctx context.Context, query string) ([]Sample, error) {
+	resp, err := c.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range resp.Warnings {
+		log.Printf("Warning: prometheus query %s: %s", query, warning)
+	}
+	return resp.Data.Samples()
+}
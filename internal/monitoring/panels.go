@@ -0,0 +1,45 @@
+package monitoring
+
+import (
+	"context"
+	"sync"
+)
+
+// Panel - запрос в составе дашборда
+type Panel struct {
+	Name  string
+	Title string
+	Query string
+	Unit  string
+}
+
+// PanelResult - результат выполнения панели; при ошибке заполнено только Err
+type PanelResult struct {
+	Panel    Panel
+	Samples  []Sample
+	Warnings []string
+	Err      error
+}
+
+// RunPanels выполняет запросы панелей параллельно и возвращает результаты в порядке панелей.
+// Ошибка одной панели не прерывает остальные.
+func (c *Client) RunPanels(ctx context.Context, panels []Panel) []PanelResult {
+	results := make([]PanelResult, len(panels))
+	var wg sync.WaitGroup
+	for i, panel := range panels {
+		wg.Add(1)
+		go func(i int, panel Panel) {
+			defer wg.Done()
+			results[i].Panel = panel
+			resp, err := c.Query(ctx, panel.Query)
+			if err != nil {
+				results[i].Err = err
+				return
+			}
+			results[i].Warnings = resp.Warnings
+			results[i].Samples, results[i].Err = resp.Data.Samples()
+		}(i, panel)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,177 @@
+package monitoring
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	metricNameRe = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRe  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Selector - селектор рядов PromQL вида metric{label="value", ...}.
+// Значения меток экранируются при выводе, поэтому в методы Eq, Neq, HasPrefix и OneOf
+// можно передавать пользовательский ввод как есть; Re и NotRe принимают готовое регулярное выражение.
+type Selector struct {
+	Metric   string
+	Matchers []Matcher
+}
+
+// NewSelector создает селектор по имени метрики; пустое имя - селектор только по меткам
+func NewSelector(metric string) *Selector {
+	return &Selector{Metric: metric}
+}
+
+// Eq добавляет условие name="value"
+func (s *Selector) Eq(name, value string) *Selector {
+	s.Matchers = append(s.Matchers, Matcher{Name: name, Value: value, IsEqual: true})
+	return s
+}
+
+// Neq добавляет условие name!="value"
+func (s *Selector) Neq(name, value string) *Selector {
+	s.Matchers = append(s.Matchers, Matcher{Name: name, Value: value})
+	return s
+}
+
+// Re добавляет условие name=~"pattern"
+func (s *Selector) Re(name, pattern string) *Selector {
+	s.Matchers = append(s.Matchers, Matcher{Name: name, Value: pattern, IsRegex: true, IsEqual: true})
+	return s
+}
+
+// NotRe добавляет условие name!~"pattern"
+func (s *Selector) NotRe(name, pattern string) *Selector {
+	s.Matchers = append(s.Matchers, Matcher{Name: name, Value: pattern, IsRegex: true})
+	return s
+}
+
+// HasPrefix добавляет условие на значение метки, начинающееся с prefix
+func (s *Selector) HasPrefix(name, prefix string) *Selector {
+	return s.Re(name, QuoteRegex(prefix)+".*")
+}
+
+// OneOf добавляет условие на значение метки, равное одному из values
+func (s *Selector) OneOf(name string, values []string) *Selector {
+	return s.Re(name, RegexAlternation(values))
+}
+
+// Validate проверяет имя метрики, имена меток и регулярные выражения
+func (s *Selector) Validate() error {
+	if s.Metric != "" && !ValidMetricName(s.Metric) {
+		return fmt.Errorf("invalid metric name %q", s.Metric)
+	}
+	if s.Metric == "" && len(s.Matchers) == 0 {
+		return fmt.Errorf("empty selector")
+	}
+	for _, m := range s.Matchers {
+		if !labelNameRe.MatchString(m.Name) {
+			return fmt.Errorf("invalid label name %q", m.Name)
+		}
+		if m.IsRegex {
+			if _, err := regexp.Compile("^(?:" + m.Value + ")$"); err != nil {
+				return fmt.Errorf("invalid regex for label %s: %w", m.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// String возвращает селектор в синтаксисе PromQL
+func (s *Selector) String() string {
+	if len(s.Matchers) == 0 {
+		if s.Metric == "" {
+			return "{}"
+		}
+		return s.Metric
+	}
+	parts := make([]string, 0, len(s.Matchers))
+	for _, m := range s.Matchers {
+		parts = append(parts, m.String())
+	}
+	return s.Metric + "{" + strings.Join(parts, ", ") + "}"
+}
+
+// ValidMetricName проверяет, что name - допустимое имя метрики Prometheus
+func ValidMetricName(name string) bool {
+	return metricNameRe.MatchString(name)
+}
+
+// QuoteLabelValue возвращает строковый литерал PromQL для значения метки
+func QuoteLabelValue(value string) string {
+	return fmt.Sprintf("%q", value)
+}
+
+// QuoteRegex экранирует метасимволы, чтобы строка совпадала в регулярном выражении буквально
+func QuoteRegex(literal string) string {
+	return regexp.QuoteMeta(literal)
+}
+
+// RegexAlternation возвращает регулярное выражение, совпадающее ровно с одним из values
+func RegexAlternation(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, QuoteRegex(v))
+	}
+	return strings.Join(quoted, "|")
+}
+
+var placeholderRe = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(?:\|\s*([a-z]+)\s*)?\}\}`)
+
+// RenderQuery подставляет параметры в шаблон запроса. Плейсхолдер {{name}} должен стоять
+// внутри строкового литерала PromQL и заменяется экранированным значением;
+// {{name|regex}} дополнительно экранирует метасимволы для использования в =~ и !~.
+func RenderQuery(expr string, params map[string]string) (string, error) {
+	if err := ValidateQueryTemplate(expr); err != nil {
+		return "", err
+	}
+	var missing []string
+	rendered := placeholderRe.ReplaceAllStringFunc(expr, func(ph string) string {
+		m := placeholderRe.FindStringSubmatch(ph)
+		value, ok := params[m[1]]
+		if !ok {
+			missing = append(missing, m[1])
+			return ph
+		}
+		if m[2] == "regex" {
+			value = QuoteRegex(value)
+		}
+		quoted := QuoteLabelValue(value)
+		return quoted[1 : len(quoted)-1]
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing query parameters: %s", strings.Join(missing, ", "))
+	}
+	return rendered, nil
+}
+
+// QueryParams возвращает имена параметров шаблона запроса в порядке появления
+func QueryParams(expr string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range placeholderRe.FindAllStringSubmatch(expr, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// ValidateQueryTemplate проверяет синтаксис плейсхолдеров в шаблоне запроса
+func ValidateQueryTemplate(expr string) error {
+	if strings.TrimSpace(expr) == "" {
+		return fmt.Errorf("empty query")
+	}
+	for _, m := range placeholderRe.FindAllStringSubmatch(expr, -1) {
+		if m[2] != "" && m[2] != "regex" {
+			return fmt.Errorf("unknown placeholder filter %q in %s", m[2], m[0])
+		}
+	}
+	if rest := placeholderRe.ReplaceAllString(expr, ""); strings.Contains(rest, "{{") || strings.Contains(rest, "}}") {
+		return fmt.Errorf("malformed placeholder in query %q", expr)
+	}
+	return nil
+}
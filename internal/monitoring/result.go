@@ -0,0 +1,278 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Типы результата запроса Prometheus
+const (
+	ResultTypeVector = "vector"
+	ResultTypeMatrix = "matrix"
+	ResultTypeScalar = "scalar"
+	ResultTypeString = "string"
+)
+
+// PrometheusQueryResponse - ответ /api/v1/query и /api/v1/query_range.
+// При ошибке Status равен "error", а причина - в ErrorType и Error.
+type PrometheusQueryResponse struct {
+	Status    string      `json:"status"`
+	Data      QueryResult `json:"data"`
+	ErrorType string      `json:"errorType,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	Warnings  []string    `json:"warnings,omitempty"`
+}
+
+// PrometheusError - ошибка выполнения запроса, которую вернул Prometheus
+type PrometheusError struct {
+	Type    string
+	Message string
+}
+
+func (e *PrometheusError) Error() string {
+	return fmt.Sprintf("prometheus %s error: %s", e.Type, e.Message)
+}
+
+// QueryResult - результат запроса; заполнено только поле, соответствующее ResultType
+type QueryResult struct {
+	ResultType string
+	Vector     []Sample
+	Matrix     []Series
+	Scalar     *SamplePoint
+	String     *StringPoint
+	Stats      *QueryStats // только при запросе со stats
+}
+
+// QueryStats - статистика выполнения запроса (параметр stats=all)
+type QueryStats struct {
+	Timings struct {
+		EvalTotalTime        float64 `json:"evalTotalTime"`
+		ExecTotalTime        float64 `json:"execTotalTime"`
+		ExecQueueTime        float64 `json:"execQueueTime"`
+		QueryPreparationTime float64 `json:"queryPreparationTime"`
+	} `json:"timings"`
+	Samples struct {
+		TotalQueryableSamples int64 `json:"totalQueryableSamples"`
+		PeakSamples           int64 `json:"peakSamples"`
+	} `json:"samples"`
+}
+
+// Sample - элемент результата типа vector
+type Sample struct {
+	Metric map[string]string
+	Time   time.Time
+	Value  float64
+}
+
+// SamplePoint - значение ряда в момент времени
+type SamplePoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series - временной ряд результата типа matrix
+type Series struct {
+	Metric map[string]string `json:"metric"`
+	Points []SamplePoint     `json:"values"`
+}
+
+// StringPoint - результат типа string
+type StringPoint struct {
+	Time  time.Time
+	Value string
+}
+
+type rawQueryResult struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+	Stats      *QueryStats     `json:"stats"`
+}
+
+type rawSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  SamplePoint       `json:"value"`
+}
+
+func (r *QueryResult) UnmarshalJSON(data []byte) error {
+	var raw rawQueryResult
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = QueryResult{ResultType: raw.ResultType, Stats: raw.Stats}
+	if len(raw.Result) == 0 || string(raw.Result) == "null" {
+		return nil
+	}
+
+	switch raw.ResultType {
+	case ResultTypeVector:
+		var samples []rawSample
+		if err := json.Unmarshal(raw.Result, &samples); err != nil {
+			return fmt.Errorf("invalid vector result: %w", err)
+		}
+		r.Vector = make([]Sample, 0, len(samples))
+		for _, s := range samples {
+			r.Vector = append(r.Vector, Sample{Metric: s.Metric, Time: s.Value.Time, Value: s.Value.Value})
+		}
+	case ResultTypeMatrix:
+		if err := json.Unmarshal(raw.Result, &r.Matrix); err != nil {
+			return fmt.Errorf("invalid matrix result: %w", err)
+		}
+	case ResultTypeScalar:
+		r.Scalar = &SamplePoint{}
+		if err := json.Unmarshal(raw.Result, r.Scalar); err != nil {
+			return fmt.Errorf("invalid scalar result: %w", err)
+		}
+	case ResultTypeString:
+		r.String = &StringPoint{}
+		if err := json.Unmarshal(raw.Result, r.String); err != nil {
+			return fmt.Errorf("invalid string result: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown result type %q", raw.ResultType)
+	}
+	return nil
+}
+
+func (r QueryResult) MarshalJSON() ([]byte, error) {
+	var result interface{}
+	switch r.ResultType {
+	case ResultTypeVector:
+		samples := make([]rawSample, 0, len(r.Vector))
+		for _, s := range r.Vector {
+			samples = append(samples, rawSample{Metric: s.Metric, Value: SamplePoint{Time: s.Time, Value: s.Value}})
+		}
+		result = samples
+	case ResultTypeMatrix:
+		result = r.Matrix
+	case ResultTypeScalar:
+		result = r.Scalar
+	case ResultTypeString:
+		result = r.String
+	}
+	return json.Marshal(struct {
+		ResultType string      `json:"resultType"`
+		Result     interface{} `json:"result"`
+		Stats      *QueryStats `json:"stats,omitempty"`
+	}{r.ResultType, result, r.Stats})
+}
+
+// Samples возвращает значения результата типа vector; scalar возвращается
+// как один sample без меток. Для matrix и string возвращается ошибка.
+func (r QueryResult) Samples() ([]Sample, error) {
+	switch r.ResultType {
+	case ResultTypeVector:
+		return r.Vector, nil
+	case ResultTypeScalar:
+		if r.Scalar == nil {
+			return nil, nil
+		}
+		return []Sample{{Metric: map[string]string{}, Time: r.Scalar.Time, Value: r.Scalar.Value}}, nil
+	default:
+		return nil, fmt.Errorf("unexpected result type %q, want vector or scalar", r.ResultType)
+	}
+}
+
+// Len возвращает число рядов результата; scalar и string считаются одним рядом
+func (r QueryResult) Len() int {
+	switch r.ResultType {
+	case ResultTypeVector:
+		return len(r.Vector)
+	case ResultTypeMatrix:
+		return len(r.Matrix)
+	case ResultTypeScalar:
+		if r.Scalar != nil {
+			return 1
+		}
+	case ResultTypeString:
+		if r.String != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Float возвращает единственное значение результата: scalar или vector из одного элемента
+func (r QueryResult) Float() (float64, bool) {
+	samples, err := r.Samples()
+	if err != nil || len(samples) != 1 {
+		return 0, false
+	}
+	return samples[0].Value, true
+}
+
+// ValuesByLabel группирует значения vector-результата по метке label;
+// элементы без метки и с нечисловыми значениями (NaN, Inf) пропускаются
+func (r QueryResult) ValuesByLabel(label string) (map[string]float64, error) {
+	samples, err := r.Samples()
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]float64, len(samples))
+	for _, s := range samples {
+		key, ok := s.Metric[label]
+		if !ok || !IsFinite(s.Value) {
+			continue
+		}
+		values[key] = s.Value
+	}
+	return values, nil
+}
+
+// IsFinite сообщает, что значение не NaN и не бесконечность
+func IsFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+func (p *SamplePoint) UnmarshalJSON(data []byte) error {
+	ts, raw, err := parsePair(data)
+	if err != nil {
+		return err
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return fmt.Errorf("invalid sample value %q: %w", raw, err)
+	}
+	*p = SamplePoint{Time: ts, Value: value}
+	return nil
+}
+
+func (p SamplePoint) MarshalJSON() ([]byte, error) {
+	return marshalPair(p.Time, strconv.FormatFloat(p.Value, 'f', -1, 64))
+}
+
+func (p *StringPoint) UnmarshalJSON(data []byte) error {
+	ts, value, err := parsePair(data)
+	if err != nil {
+		return err
+	}
+	*p = StringPoint{Time: ts, Value: value}
+	return nil
+}
+
+func (p StringPoint) MarshalJSON() ([]byte, error) {
+	return marshalPair(p.Time, p.Value)
+}
+
+// parsePair разбирает пару [<unix time>, "<value>"] из ответа Prometheus
+func parsePair(data []byte) (time.Time, string, error) {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(data, &pair); err != nil || len(pair) != 2 {
+		return time.Time{}, "", fmt.Errorf("invalid sample %s", data)
+	}
+	var ts float64
+	if err := json.Unmarshal(pair[0], &ts); err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid sample timestamp %s", pair[0])
+	}
+	var value string
+	if err := json.Unmarshal(pair[1], &value); err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid sample value %s", pair[1])
+	}
+	return time.UnixMilli(int64(math.Round(ts * 1000))).UTC(), value, nil
+}
+
+func marshalPair(t time.Time, value string) ([]byte, error) {
+	return json.Marshal([]interface{}{float64(t.UnixMilli()) / 1000, value})
+}
@@ -0,0 +1,293 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Matcher - условие на метку алерта в тишине Alertmanager
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// String возвращает матчер в синтаксисе Alertmanager: name="value", name!~"value" и т.д.
+func (m Matcher) String() string {
+	op := "="
+	switch {
+	case m.IsRegex && m.IsEqual:
+		op = "=~"
+	case m.IsRegex:
+		op = "!~"
+	case !m.IsEqual:
+		op = "!="
+	}
+	return m.Name + op + QuoteLabelValue(m.Value)
+}
+
+// Matches проверяет значение метки на соответствие матчеру
+func (m Matcher) Matches(value string) bool {
+	matched := value == m.Value
+	if m.IsRegex {
+		re, err := regexp.Compile("^(?:" + m.Value + ")$")
+		if err != nil {
+			return false
+		}
+		matched = re.MatchString(value)
+	}
+	return matched == m.IsEqual
+}
+
+// Состояния тишины
+const (
+	SilenceActive  = "active"
+	SilencePending = "pending"
+	SilenceExpired = "expired"
+)
+
+// Silence - тишина Alertmanager (/api/v2/silences)
+type Silence struct {
+	ID        string    `json:"id,omitempty"`
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+	Status    *struct {
+		State string `json:"state"`
+	} `json:"status,omitempty"`
+}
+
+// State возвращает состояние тишины (active, pending, expired)
+func (s Silence) State() string {
+	if s.Status == nil {
+		return ""
+	}
+	return s.Status.State
+}
+
+// ParseMatchers разбирает список матчеров вида alertname=X,pod=~"api-.*"
+// (фигурные скобки и кавычки необязательны)
+func ParseMatchers(expr string) ([]Matcher, error) {
+	expr = strings.TrimSpace(expr)
+	expr = strings.TrimPrefix(expr, "{")
+	expr = strings.TrimSuffix(expr, "}")
+
+	var matchers []Matcher
+	for _, part := range splitMatchers(expr) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		m, err := parseMatcher(part)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	if len(matchers) == 0 {
+		return nil, fmt.Errorf("не задано ни одного матчера")
+	}
+	return matchers, nil
+}
+
+// MatchersForLabels возвращает матчеры на точное совпадение всех меток алерта
+func MatchersForLabels(labels map[string]string) []Matcher {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	matchers := make([]Matcher, 0, len(names))
+	for _, name := range names {
+		matchers = append(matchers, Matcher{Name: name, Value: labels[name], IsEqual: true})
+	}
+	return matchers
+}
+
+// CutMatchers отделяет выражение матчеров в начале строки от остального текста:
+// выражение заканчивается на первом пробеле вне кавычек и фигурных скобок,
+// поэтому значения вида alertname="Disk full" не разрываются
+func CutMatchers(text string) (expr, rest string) {
+	text = strings.TrimLeftFunc(text, unicode.IsSpace)
+	inQuotes, escaped, depth := false, false, 0
+	for i, r := range text {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && inQuotes:
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case r == '{':
+			depth++
+		case r == '}':
+			depth--
+		case unicode.IsSpace(r) && depth <= 0:
+			return text[:i], strings.TrimSpace(text[i:])
+		}
+	}
+	return text, ""
+}
+
+// splitMatchers делит выражение по запятым вне кавычек
+func splitMatchers(expr string) []string {
+	var parts []string
+	var current strings.Builder
+	inQuotes, escaped := false, false
+	for _, r := range expr {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && inQuotes:
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == ',' && !inQuotes:
+			parts = append(parts, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	return append(parts, current.String())
+}
+
+func parseMatcher(part string) (Matcher, error) {
+	idx := strings.IndexAny(part, "=!")
+	if idx <= 0 {
+		return Matcher{}, fmt.Errorf("некорректный матчер %q", part)
+	}
+	name := strings.TrimSpace(part[:idx])
+	rest := part[idx:]
+
+	m := Matcher{Name: name}
+	switch {
+	case strings.HasPrefix(rest, "=~"):
+		m.IsRegex, m.IsEqual, rest = true, true, rest[2:]
+	case strings.HasPrefix(rest, "!~"):
+		m.IsRegex, m.IsEqual, rest = true, false, rest[2:]
+	case strings.HasPrefix(rest, "!="):
+		m.IsEqual, rest = false, rest[2:]
+	case strings.HasPrefix(rest, "="):
+		m.IsEqual, rest = true, rest[1:]
+	default:
+		return Matcher{}, fmt.Errorf("некорректный оператор в матчере %q", part)
+	}
+
+	value := strings.TrimSpace(rest)
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := unquoteMatcherValue(value)
+		if err != nil {
+			return Matcher{}, fmt.Errorf("некорректное значение в матчере %q", part)
+		}
+		value = unquoted
+	}
+	m.Value = value
+	if m.Value == "" && m.IsEqual && !m.IsRegex {
+		return Matcher{}, fmt.Errorf("пустое значение в матчере %q", part)
+	}
+	return m, nil
+}
+
+func unquoteMatcherValue(value string) (string, error) {
+	if len(value) < 2 || !strings.HasSuffix(value, `"`) {
+		return "", fmt.Errorf("unterminated quote")
+	}
+	var sb strings.Builder
+	escaped := false
+	for _, r := range value[1 : len(value)-1] {
+		if escaped {
+			sb.WriteRune(r)
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	if escaped {
+		return "", fmt.Errorf("dangling escape")
+	}
+	return sb.String(), nil
+}
+
+// CreateSilence создает тишину и возвращает ее ID
+func (c *Client) CreateSilence(ctx context.Context, silence Silence) (string, error) {
+	var resp struct {
+		SilenceID string `json:"silenceID"`
+	}
+	if err := c.alertmanagerRequest(ctx, http.MethodPost, "/api/v2/silences", silence, &resp); err != nil {
+		return "", err
+	}
+	return resp.SilenceID, nil
+}
+
+// ListSilences возвращает все тишины, известные Alertmanager, включая истекшие
+func (c *Client) ListSilences(ctx context.Context) ([]Silence, error) {
+	var silences []Silence
+	if err := c.alertmanagerRequest(ctx, http.MethodGet, "/api/v2/silences", nil, &silences); err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+// ExpireSilence досрочно завершает тишину
+func (c *Client) ExpireSilence(ctx context.Context, id string) error {
+	return c.alertmanagerRequest(ctx, http.MethodDelete, "/api/v2/silence/"+url.PathEscape(id), nil, nil)
+}
+
+func (c *Client) alertmanagerRequest(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.alertmanagerURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.user != "" && c.pass != "" {
+		req.SetBasicAuth(c.user, c.pass)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request to alertmanager: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("alertmanager returned non-OK status: %s %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode alertmanager response: %w", err)
+	}
+	return nil
+}
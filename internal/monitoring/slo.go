@@ -0,0 +1,181 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Виды SLO
+const (
+	SLOAvailability = "availability"
+	SLOLatency      = "latency"
+)
+
+// SLO - цель уровня обслуживания сервиса.
+// Query - шаблон запроса доли хороших событий (от 0 до 1) за окно {{window}}, например
+// sum(rate(http_requests_total{job="{{service}}", code!~"5.."}[{{window}}])) / sum(rate(http_requests_total{job="{{service}}"}[{{window}}])).
+// Кроме {{window}} доступны {{service}} и {{namespace}} (см. RenderQuery).
+type SLO struct {
+	Name      string
+	Service   string
+	Namespace string
+	Kind      string // SLOAvailability или SLOLatency
+	Query     string
+	Target    float64       // целевая доля хороших событий, например 0.999
+	Window    time.Duration // окно SLO, например 30 дней
+}
+
+// Validate проверяет цель и шаблон запроса SLO
+func (s SLO) Validate() error {
+	if s.Kind != SLOAvailability && s.Kind != SLOLatency {
+		return fmt.Errorf("unknown SLO kind %q, expected %s or %s", s.Kind, SLOAvailability, SLOLatency)
+	}
+	if !(s.Target > 0 && s.Target < 1) {
+		return fmt.Errorf("SLO target must be between 0 and 1, got %v", s.Target)
+	}
+	if s.Window < time.Hour {
+		return fmt.Errorf("SLO window must be at least 1h, got %s", s.Window)
+	}
+	if err := ValidateQueryTemplate(s.Query); err != nil {
+		return err
+	}
+	for _, name := range QueryParams(s.Query) {
+		if name == "window" {
+			return nil
+		}
+	}
+	return fmt.Errorf("SLO query must use the {{window}} range placeholder")
+}
+
+// ErrorBudget возвращает допустимую долю плохих событий
+func (s SLO) ErrorBudget() float64 {
+	return 1 - s.Target
+}
+
+// Важность правил расхода бюджета ошибок
+const (
+	BurnRateCritical = "critical"
+	BurnRateWarning  = "warning"
+)
+
+// BurnRateRule - правило multi-window multi-burn-rate из SRE Workbook: срабатывает,
+// если за окна Long и Short бюджет расходуется так быстро, что за Long будет потрачена
+// доля BudgetFraction всего бюджета ошибок. Короткое окно гасит правило вскоре после восстановления.
+type BurnRateRule struct {
+	Severity       string
+	Long           time.Duration
+	Short          time.Duration
+	BudgetFraction float64
+}
+
+// Threshold возвращает пороговую скорость расхода бюджета для окна SLO
+// (14.4 для 2% бюджета за час при окне 30 дней)
+func (r BurnRateRule) Threshold(window time.Duration) float64 {
+	return r.BudgetFraction * float64(window) / float64(r.Long)
+}
+
+// DefaultBurnRateRules - правила SRE Workbook для окна 30 дней: срочные (critical)
+// при расходе 2% бюджета за час или 5% за 6 часов, предупреждения (warning) - 10% за сутки или за 3 дня
+var DefaultBurnRateRules = []BurnRateRule{
+	{Severity: BurnRateCritical, Long: time.Hour, Short: 5 * time.Minute, BudgetFraction: 0.02},
+	{Severity: BurnRateCritical, Long: 6 * time.Hour, Short: 30 * time.Minute, BudgetFraction: 0.05},
+	{Severity: BurnRateWarning, Long: 24 * time.Hour, Short: 2 * time.Hour, BudgetFraction: 0.1},
+	{Severity: BurnRateWarning, Long: 72 * time.Hour, Short: 6 * time.Hour, BudgetFraction: 0.1},
+}
+
+// SLOStatus - состояние SLO. Значения, для которых нет данных (нет трафика за окно), равны NaN.
+type SLOStatus struct {
+	SLO             SLO
+	Attainment      float64                   // доля хороших событий за окно SLO
+	BudgetRemaining float64                   // доля оставшегося бюджета ошибок, отрицательная - бюджет исчерпан
+	BurnRates       map[time.Duration]float64 // скорость расхода бюджета по окнам правил
+	Firing          []BurnRateRule            // сработавшие правила
+}
+
+// EvaluateSLO вычисляет выполнение SLO за его окно, остаток бюджета ошибок
+// и скорость его расхода за окна правил; запросы по окнам выполняются параллельно
+func (c *Client) EvaluateSLO(ctx context.Context, slo SLO, rules []BurnRateRule) (*SLOStatus, error) {
+	if err := slo.Validate(); err != nil {
+		return nil, err
+	}
+
+	windows := []time.Duration{slo.Window}
+	seen := map[time.Duration]bool{slo.Window: true}
+	for _, rule := range rules {
+		for _, w := range []time.Duration{rule.Long, rule.Short} {
+			if !seen[w] {
+				seen[w] = true
+				windows = append(windows, w)
+			}
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+
+	panels := make([]Panel, 0, len(windows))
+	for _, w := range windows {
+		query, err := RenderQuery(slo.Query, map[string]string{
+			"window":    PromDuration(w),
+			"service":   slo.Service,
+			"namespace": slo.Namespace,
+		})
+		if err != nil {
+			return nil, err
+		}
+		panels = append(panels, Panel{Name: PromDuration(w), Query: query})
+	}
+
+	ratios := make(map[time.Duration]float64, len(windows))
+	for i, result := range c.RunPanels(ctx, panels) {
+		if result.Err != nil {
+			return nil, fmt.Errorf("SLO %s/%s query over %s: %w", slo.Service, slo.Name, result.Panel.Name, result.Err)
+		}
+		ratios[windows[i]] = sloRatio(result.Samples)
+	}
+
+	status := &SLOStatus{
+		SLO:        slo,
+		Attainment: ratios[slo.Window],
+		BurnRates:  make(map[time.Duration]float64, len(windows)),
+	}
+	status.BudgetRemaining = 1 - (1-status.Attainment)/slo.ErrorBudget()
+	for w, ratio := range ratios {
+		status.BurnRates[w] = (1 - ratio) / slo.ErrorBudget()
+	}
+	for _, rule := range rules {
+		threshold := rule.Threshold(slo.Window)
+		// сравнение с NaN ложно: без трафика правило не срабатывает
+		if status.BurnRates[rule.Long] > threshold && status.BurnRates[rule.Short] > threshold {
+			status.Firing = append(status.Firing, rule)
+		}
+	}
+	return status, nil
+}
+
+// sloRatio возвращает долю хороших событий из результата запроса SLO, NaN - если данных нет
+func sloRatio(samples []Sample) float64 {
+	if len(samples) != 1 || !IsFinite(samples[0].Value) {
+		return math.NaN()
+	}
+	return math.Max(0, math.Min(1, samples[0].Value))
+}
+
+// PromDuration форматирует длительность в синтаксисе PromQL: 30d, 6h, 5m, 30s
+func PromDuration(d time.Duration) string {
+	for _, unit := range []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	} {
+		if d >= unit.size && d%unit.size == 0 {
+			return fmt.Sprintf("%d%s", d/unit.size, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
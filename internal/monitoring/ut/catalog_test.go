@@ -0,0 +1,122 @@
+package monitoring_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"chatops/internal/monitoring"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const catalogYAML = `
+queries:
+  - name: error_rate
+    description: Доля ответов 5xx
+    unit: "%"
+    expr: 100 * sum(rate(http_requests_total{job=~"{{service|regex}}.*", namespace="{{namespace}}", code=~"5.."}[5m]))
+  - name: p99_latency
+    expr: histogram_quantile(0.99, sum(rate(http_request_duration_seconds_bucket{job=~"{{ service | regex }}.*"}[5m])) by (le))
+dashboards:
+  - name: payments
+    namespace: prod
+    panels: [p99_latency, error_rate]
+`
+
+func TestLoadQueryCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(catalogYAML), 0o600))
+
+	catalog, err := monitoring.LoadQueryCatalog(path)
+	require.NoError(t, err)
+	require.Len(t, catalog.Queries, 2)
+	assert.Equal(t, "%", catalog.Queries[0].Unit)
+	assert.Equal(t, []string{"service", "namespace"}, monitoring.QueryParams(catalog.Queries[0].Expr))
+	require.Len(t, catalog.Dashboards, 1)
+	assert.Equal(t, []string{"p99_latency", "error_rate"}, catalog.Dashboards[0].Panels)
+
+	_, err = monitoring.LoadQueryCatalog(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestParseQueryCatalog_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"syntax":          "queries: [",
+		"bad name":        "queries:\n  - name: error rate\n    expr: up",
+		"duplicate":       "queries:\n  - name: up\n    expr: up\n  - name: up\n    expr: up",
+		"empty expr":      "queries:\n  - name: up\n    expr: ''",
+		"bad filter":      "queries:\n  - name: up\n    expr: up{job=\"{{service|upper}}\"}",
+		"unclosed":        "queries:\n  - name: up\n    expr: up{job=\"{{service\"}",
+		"unknown panel":   "queries:\n  - name: up\n    expr: up\ndashboards:\n  - name: d\n    panels: [down]",
+		"no panels":       "queries:\n  - name: up\n    expr: up\ndashboards:\n  - name: d",
+		"duplicate board": "queries:\n  - name: up\n    expr: up\ndashboards:\n  - name: d\n    panels: [up]\n  - name: d\n    panels: [up]",
+	} {
+		_, err := monitoring.ParseQueryCatalog([]byte(data))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestRenderQuery(t *testing.T) {
+	expr := `sum(rate(http_requests_total{job=~"{{service|regex}}.*", namespace="{{namespace}}"}[5m]))`
+
+	rendered, err := monitoring.RenderQuery(expr, map[string]string{"service": "payments", "namespace": "prod"})
+	require.NoError(t, err)
+	assert.Equal(t, `sum(rate(http_requests_total{job=~"payments.*", namespace="prod"}[5m]))`, rendered)
+
+	rendered, err = monitoring.RenderQuery(expr, map[string]string{"service": `a.b"}) or vector(1) #`, "namespace": `x"\`})
+	require.NoError(t, err)
+	assert.Equal(t, `sum(rate(http_requests_total{job=~"a\\.b\"\\}\\) or vector\\(1\\) #.*", namespace="x\"\\"}[5m]))`, rendered)
+	assert.Equal(t, monitoring.QuoteRegex(`a.b"}) or vector(1) #`)+".*", labelValue(t, rendered, "job", "=~"))
+	assert.Equal(t, `x"\`, labelValue(t, rendered, "namespace", "="))
+
+	_, err = monitoring.RenderQuery(expr, map[string]string{"service": "payments"})
+	assert.ErrorContains(t, err, "namespace")
+}
+
+func TestClient_RunPanels(t *testing.T) {
+	var inFlight, maxInFlight int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("query") {
+		case "bad":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+		case "scalar(1)":
+			w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1700000000,"1"]}}`))
+		default:
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"pod":"api-1"},"value":[1700000000,"2"]}]}}`))
+		}
+	})
+	client, server := setupTestClient(t, handler)
+	defer server.Close()
+
+	results := client.RunPanels(context.Background(), []monitoring.Panel{
+		{Name: "a", Query: "up"},
+		{Name: "b", Query: "bad"},
+		{Name: "c", Query: "scalar(1)"},
+	})
+	require.Len(t, results, 3)
+	assert.Equal(t, "a", results[0].Panel.Name)
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, 2.0, results[0].Samples[0].Value)
+	assert.Error(t, results[1].Err)
+	require.NoError(t, results[2].Err)
+	assert.Equal(t, 1.0, results[2].Samples[0].Value)
+	assert.Greater(t, atomic.LoadInt32(&maxInFlight), int32(1), "panels must run concurrently")
+}
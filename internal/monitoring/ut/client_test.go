@@ -5,10 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func setupTestClient(t *testing.T, handler http.Handler) (*monitoring.Client, *httptest.Server) {
@@ -105,11 +108,187 @@ func TestClient_Query(t *testing.T) {
 		t.Errorf("Query() response status got = %s, want success", resp.Status)
 	}
 
-	if len(resp.Data.Result) != 1 {
-		t.Fatalf("Query() expected 1 result, got %d", len(resp.Data.Result))
+	if len(resp.Data.Vector) != 1 {
+		t.Fatalf("Query() expected 1 result, got %d", len(resp.Data.Vector))
 	}
 
-	if resp.Data.Result[0].Value[1] != "9" {
-		t.Errorf("Query() result value got = %v, want 9", resp.Data.Result[0].Value[1])
+	if resp.Data.Vector[0].Value != 9 {
+		t.Errorf("Query() result value got = %v, want 9", resp.Data.Vector[0].Value)
+	}
+}
+
+func TestClient_GetActiveAlerts_V2Model(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/alerts" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		if got := q["filter"]; !reflect.DeepEqual(got, []string{`alertname="PodCrash"`, `pod=~"api-.*"`}) {
+			http.Error(w, fmt.Sprintf("unexpected filter %v", got), http.StatusBadRequest)
+			return
+		}
+		if q.Get("silenced") != "false" || q.Get("receiver") != "chatops" || q.Has("active") {
+			http.Error(w, fmt.Sprintf("unexpected query %s", r.URL.RawQuery), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{
+			"fingerprint": "abc",
+			"labels": {"alertname": "PodCrash", "pod": "api-1"},
+			"annotations": {"summary": "crash"},
+			"startsAt": "2024-01-01T12:00:00Z",
+			"endsAt": "2024-01-01T12:05:00Z",
+			"updatedAt": "2024-01-01T12:01:00Z",
+			"generatorURL": "http://prometheus/graph",
+			"receivers": [{"name": "chatops"}],
+			"status": {"state": "suppressed", "silencedBy": [], "inhibitedBy": ["def"]}
+		}]`))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	client, err := monitoring.NewClient("", server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create test client: %v", err)
+	}
+
+	no := false
+	alerts, err := client.GetActiveAlerts(context.Background(), monitoring.AlertFilter{
+		Matchers: []monitoring.Matcher{
+			{Name: "alertname", Value: "PodCrash", IsEqual: true},
+			{Name: "pod", Value: "api-.*", IsRegex: true, IsEqual: true},
+		},
+		Silenced: &no,
+		Receiver: "chatops",
+	})
+	if err != nil {
+		t.Fatalf("GetActiveAlerts() returned an error: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("GetActiveAlerts() got %d alerts, want 1", len(alerts))
+	}
+	alert := alerts[0]
+	if alert.Fingerprint != "abc" || alert.GeneratorURL != "http://prometheus/graph" || alert.Receivers[0].Name != "chatops" {
+		t.Errorf("GetActiveAlerts() decoded %+v", alert)
+	}
+	if alert.Status.State != monitoring.AlertStateSuppressed || alert.Silenced() || !alert.Inhibited() || !alert.Suppressed() {
+		t.Errorf("GetActiveAlerts() decoded status %+v", alert.Status)
+	}
+	if alert.EndsAt.Sub(alert.StartsAt) != 5*time.Minute {
+		t.Errorf("GetActiveAlerts() decoded startsAt %s, endsAt %s", alert.StartsAt, alert.EndsAt)
+	}
+}
+
+func TestAlertFilter_Matches(t *testing.T) {
+	yes, no := true, false
+	silenced := monitoring.Alert{
+		Labels:    map[string]string{"alertname": "PodCrash", "namespace": "payments"},
+		Receivers: []monitoring.AlertReceiver{{Name: "team-payments"}},
+		Status:    monitoring.AlertStatus{State: monitoring.AlertStateSuppressed, SilencedBy: []string{"s1"}},
+	}
+	active := monitoring.Alert{
+		Labels: map[string]string{"alertname": "HighLatency", "namespace": "billing"},
+		Status: monitoring.AlertStatus{State: monitoring.AlertStateActive},
+	}
+
+	tests := []struct {
+		name     string
+		filter   monitoring.AlertFilter
+		expected []bool
+	}{
+		{"empty", monitoring.AlertFilter{}, []bool{true, true}},
+		{"active only", monitoring.AlertFilter{Active: &yes, Silenced: &no}, []bool{false, true}},
+		{"silenced only", monitoring.AlertFilter{Silenced: &yes}, []bool{true, false}},
+		{"negative matcher", monitoring.AlertFilter{Matchers: []monitoring.Matcher{{Name: "namespace", Value: "billing"}}}, []bool{true, false}},
+		{"regex matcher", monitoring.AlertFilter{Matchers: []monitoring.Matcher{{Name: "alertname", Value: "High.*", IsRegex: true, IsEqual: true}}}, []bool{false, true}},
+		{"receiver", monitoring.AlertFilter{Receiver: "team-.*"}, []bool{true, false}},
+	}
+	for _, tt := range tests {
+		for i, alert := range []monitoring.Alert{silenced, active} {
+			if got := tt.filter.Matches(alert); got != tt.expected[i] {
+				t.Errorf("%s: Matches(%s) = %v, want %v", tt.name, alert.Labels["alertname"], got, tt.expected[i])
+			}
+		}
+	}
+}
+
+func TestClient_QueryRange(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_range" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("query") != "up" || q.Get("start") != "1700000000.000" || q.Get("end") != "1700000060.000" || q.Get("step") != "30" {
+			http.Error(w, fmt.Sprintf("unexpected query %s", r.URL.RawQuery), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"pod":"api-1"},"values":[[1700000000,"1"],[1700000030.5,"NaN"],[1700000060,"0.25"]]}
+		]}}`))
+	})
+	client, server := setupTestClient(t, handler)
+	defer server.Close()
+
+	series, err := client.QueryRange(context.Background(), "up", start, start.Add(time.Minute), 30*time.Second)
+	if err != nil {
+		t.Fatalf("QueryRange() returned an error: %v", err)
+	}
+	if len(series) != 1 || len(series[0].Points) != 3 || series[0].Metric["pod"] != "api-1" {
+		t.Fatalf("QueryRange() got = %+v", series)
+	}
+	points := series[0].Points
+	if points[0].Value != 1 || points[2].Value != 0.25 || !math.IsNaN(points[1].Value) {
+		t.Errorf("QueryRange() values = %+v", points)
+	}
+	if !points[1].Time.Equal(start.Add(30500 * time.Millisecond)) {
+		t.Errorf("QueryRange() time = %s, want %s", points[1].Time, start.Add(30500*time.Millisecond))
+	}
+}
+
+func TestClient_QueryRange_Error(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+	})
+	client, server := setupTestClient(t, handler)
+	defer server.Close()
+
+	_, err := client.QueryRange(context.Background(), "up{", time.Now().Add(-time.Hour), time.Now(), time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "parse error") {
+		t.Errorf("QueryRange() expected prometheus error, got %v", err)
+	}
+}
+
+func TestClient_QueryWithOptions(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("timeout") != "15s" || q.Get("limit") != "201" || q.Get("stats") != "all" || q.Get("time") != "1700000000.000" {
+			http.Error(w, fmt.Sprintf("unexpected query %s", r.URL.RawQuery), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[],
+			"stats":{"timings":{"evalTotalTime":0.01},"samples":{"totalQueryableSamples":1234,"peakSamples":56}}}}`))
+	})
+	client, server := setupTestClient(t, handler)
+	defer server.Close()
+
+	resp, err := client.QueryWithOptions(context.Background(), "up", monitoring.QueryOptions{
+		Time:    time.Unix(1700000000, 0),
+		Timeout: 15 * time.Second,
+		Limit:   201,
+		Stats:   true,
+	})
+	if err != nil {
+		t.Fatalf("QueryWithOptions() returned an error: %v", err)
+	}
+	if resp.Data.Stats == nil || resp.Data.Stats.Samples.TotalQueryableSamples != 1234 || resp.Data.Stats.Samples.PeakSamples != 56 {
+		t.Errorf("QueryWithOptions() stats = %+v", resp.Data.Stats)
+	}
+	if resp.Data.Len() != 0 {
+		t.Errorf("QueryWithOptions() Len() = %d, want 0", resp.Data.Len())
 	}
 }
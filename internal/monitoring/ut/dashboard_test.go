@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -26,58 +27,37 @@ func TestGetStatusDashboard_Integration(t *testing.T) {
 		query := r.URL.Query().Get("query")
 		var resp monitoring.PrometheusQueryResponse
 		resp.Status = "success"
-		resp.Data.ResultType = "vector"
+		resp.Data.ResultType = monitoring.ResultTypeVector
 
 		switch query {
 		case `sum(rate(container_cpu_usage_seconds_total{job="test-job", namespace="test-ns", container!=""}[5m])) by (pod)`:
-			resp.Data.Result = []struct {
-				Metric map[string]string `json:"metric"`
-				Value  []interface{}     `json:"value"`
-			}{
-				{Metric: map[string]string{"pod": "pod-1"}, Value: []interface{}{1672531200.0, "0.5"}},
-				{Metric: map[string]string{"pod": "pod-2"}, Value: []interface{}{1672531200.0, "1.2"}},
+			resp.Data.Vector = []monitoring.Sample{
+				{Metric: map[string]string{"pod": "pod-1"}, Time: time.Unix(1672531200, 0), Value: 0.5},
+				{Metric: map[string]string{"pod": "pod-2"}, Time: time.Unix(1672531200, 0), Value: 1.2},
 			}
 		case `sum(kube_pod_container_resource_limits{job="test-job", namespace="test-ns", resource="cpu"}) by (pod)`:
-			resp.Data.Result = []struct {
-				Metric map[string]string `json:"metric"`
-				Value  []interface{}     `json:"value"`
-			}{
-				{Metric: map[string]string{"pod": "pod-1"}, Value: []interface{}{1672531200.0, "1"}},
-				{Metric: map[string]string{"pod": "pod-2"}, Value: []interface{}{1672531200.0, "2"}},
+			resp.Data.Vector = []monitoring.Sample{
+				{Metric: map[string]string{"pod": "pod-1"}, Time: time.Unix(1672531200, 0), Value: 1},
+				{Metric: map[string]string{"pod": "pod-2"}, Time: time.Unix(1672531200, 0), Value: 2},
 			}
 		case `sum(container_memory_working_set_bytes{job="test-job", namespace="test-ns", container!=""}) by (pod)`:
-			resp.Data.Result = []struct {
-				Metric map[string]string `json:"metric"`
-				Value  []interface{}     `json:"value"`
-			}{
-				{Metric: map[string]string{"pod": "pod-1"}, Value: []interface{}{1672531200.0, "1073741824"}}, // 1 GiB
+			resp.Data.Vector = []monitoring.Sample{
+				{Metric: map[string]string{"pod": "pod-1"}, Time: time.Unix(1672531200, 0), Value: 1073741824}, // 1 GiB
 			}
 		case `sum(kube_pod_container_resource_limits{job="test-job", namespace="test-ns", resource="memory"}) by (pod)`:
-			resp.Data.Result = []struct {
-				Metric map[string]string `json:"metric"`
-				Value  []interface{}     `json:"value"`
-			}{
-				{Metric: map[string]string{"pod": "pod-1"}, Value: []interface{}{1672531200.0, "2147483648"}}, // 2 GiB
+			resp.Data.Vector = []monitoring.Sample{
+				{Metric: map[string]string{"pod": "pod-1"}, Time: time.Unix(1672531200, 0), Value: 2147483648}, // 2 GiB
 			}
 		case `sum(kube_pod_container_status_restarts_total{job="test-job", namespace="test-ns"}) by (pod)`:
-			resp.Data.Result = []struct {
-				Metric map[string]string `json:"metric"`
-				Value  []interface{}     `json:"value"`
-			}{
-				{Metric: map[string]string{"pod": "pod-2"}, Value: []interface{}{1672531200.0, "5"}},
+			resp.Data.Vector = []monitoring.Sample{
+				{Metric: map[string]string{"pod": "pod-2"}, Time: time.Unix(1672531200, 0), Value: 5},
 			}
 		case `kube_pod_container_status_last_terminated_reason{job="test-job", namespace="test-ns", reason="OOMKilled"}`:
-			resp.Data.Result = []struct {
-				Metric map[string]string `json:"metric"`
-				Value  []interface{}     `json:"value"`
-			}{
-				{Metric: map[string]string{"pod": "pod-2", "namespace": "test-ns", "reason": "OOMKilled"}, Value: []interface{}{1672531200.0, "1"}},
+			resp.Data.Vector = []monitoring.Sample{
+				{Metric: map[string]string{"pod": "pod-2", "namespace": "test-ns", "reason": "OOMKilled"}, Time: time.Unix(1672531200, 0), Value: 1},
 			}
 		default:
-			resp.Data.Result = []struct {
-				Metric map[string]string `json:"metric"`
-				Value  []interface{}     `json:"value"`
-			}{}
+			resp.Data.Vector = []monitoring.Sample{}
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -97,14 +77,13 @@ func TestGetStatusDashboard_Integration(t *testing.T) {
 			{
 				Labels:      map[string]string{"alertname": "HighCPU", "job": "test-job", "namespace": "test-ns"},
 				Annotations: map[string]string{"summary": "CPU usage is high"},
-				State:       "firing",
-				ActiveAt:    time.Now(),
-				Value:       "95",
+				Status:      monitoring.AlertStatus{State: monitoring.AlertStateActive},
+				StartsAt:    time.Now(),
 			},
 			{
 				Labels:      map[string]string{"alertname": "OtherAlert", "job": "other-job"}, // Should be filtered out
 				Annotations: map[string]string{"summary": "Another alert"},
-				State:       "firing",
+				Status:      monitoring.AlertStatus{State: monitoring.AlertStateActive},
 			},
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -118,7 +97,7 @@ func TestGetStatusDashboard_Integration(t *testing.T) {
 	client, err := monitoring.NewClient(promServer.URL, alertmanagerServer.URL)
 	require.NoError(t, err)
 
-	dashboard, err := client.GetStatusDashboard(context.Background(), "test-ns", "test-job")
+	dashboard, err := client.GetStatusDashboard(context.Background(), "test-ns", "test-job", nil)
 	require.NoError(t, err)
 	require.NotNil(t, dashboard)
 
@@ -165,7 +144,7 @@ func TestGetStatusDashboard_ErrorHandling(t *testing.T) {
 		client, err := monitoring.NewClient(invalidPromURL, alertmanagerServer.URL)
 		require.NoError(t, err)
 
-		dashboard, err := client.GetStatusDashboard(context.Background(), "test-ns", "test-job")
+		dashboard, err := client.GetStatusDashboard(context.Background(), "test-ns", "test-job", nil)
 		require.NoError(t, err)
 		require.NotNil(t, dashboard)
 
@@ -185,7 +164,7 @@ func TestGetStatusDashboard_ErrorHandling(t *testing.T) {
 		client, err := monitoring.NewClient(promServer.URL, invalidAlertmanagerURL)
 		require.NoError(t, err)
 
-		dashboard, err := client.GetStatusDashboard(context.Background(), "test-ns", "test-job")
+		dashboard, err := client.GetStatusDashboard(context.Background(), "test-ns", "test-job", nil)
 		require.NoError(t, err)
 		require.NotNil(t, dashboard)
 
@@ -193,3 +172,59 @@ func TestGetStatusDashboard_ErrorHandling(t *testing.T) {
 		assert.Empty(t, dashboard.Pods)
 	})
 }
+
+func TestGetStatusDashboard_WithWorkloads(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		var result string
+		switch {
+		case strings.HasPrefix(query, "up{"):
+			result = `{"metric":{"pod":"api-1"},"value":[1700000000,"1"]}`
+		case strings.Contains(query, "container_cpu_usage_seconds_total"):
+			result = `{"metric":{"pod":"api-1"},"value":[1700000000,"0.95"]},{"metric":{"pod":"api-2"},"value":[1700000000,"0.1"]}`
+		case strings.Contains(query, `resource="cpu"`):
+			result = `{"metric":{"pod":"api-1"},"value":[1700000000,"1"]},{"metric":{"pod":"api-2"},"value":[1700000000,"1"]}`
+		case strings.Contains(query, "kube_pod_status_phase"):
+			result = `{"metric":{"pod":"api-1","phase":"Pending"},"value":[1700000000,"1"]}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[%s]}}`, result)
+	})
+	client, server := setupTestClient(t, handler)
+	defer server.Close()
+
+	workloadPods := []monitoring.WorkloadPod{
+		{Name: "api-2", Phase: "Running", Ready: true, Restarts: 3},
+		{Name: "api-1", Phase: "Running", Ready: true},
+	}
+
+	dashboard, err := client.GetStatusDashboard(context.Background(), "prod", "api", workloadPods)
+	require.NoError(t, err)
+
+	// api-2 не отдает метрики up, но виден через Kubernetes
+	require.Len(t, dashboard.Pods, 2)
+	api1, api2 := dashboard.Pods[0], dashboard.Pods[1]
+	assert.Equal(t, "api-1", api1.PodName)
+	assert.Equal(t, "Running", api1.Phase)
+	assert.True(t, api1.Ready)
+	assert.InDelta(t, 0.95, api1.CPURatio(), 1e-9)
+	assert.True(t, api1.NearLimit())
+
+	assert.Equal(t, "api-2", api2.PodName)
+	assert.Equal(t, int64(3), api2.Restarts)
+	assert.False(t, api2.NearLimit())
+}
+
+func TestGetStatusDashboard_WorkloadsWithoutPrometheus(t *testing.T) {
+	client, err := monitoring.NewClient("http://localhost:9999", "")
+	require.NoError(t, err)
+	dashboard, err := client.GetStatusDashboard(context.Background(), "prod", "api", []monitoring.WorkloadPod{{Name: "api-1", Phase: "Pending"}})
+	require.NoError(t, err)
+	require.Len(t, dashboard.Pods, 1)
+	assert.Equal(t, "Pending", dashboard.Pods[0].Phase)
+	assert.Equal(t, 0.0, dashboard.Pods[0].CPURatio())
+
+	// без данных Kubernetes недоступный Prometheus - ошибка
+	_, err = client.GetStatusDashboard(context.Background(), "prod", "api", nil)
+	assert.Error(t, err)
+}
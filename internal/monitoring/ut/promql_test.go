@@ -0,0 +1,112 @@
+package monitoring_test
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+
+	"chatops/internal/monitoring"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var hostileValues = []string{
+	`api`,
+	`a"b`,
+	`x"} or vector(1) or up{job="`,
+	`back\slash\`,
+	`.*`,
+	`api|admin`,
+	`(a+)+$`,
+	"line\nbreak",
+	"таб\tи юникод ✓",
+	`{}[]^$`,
+}
+
+// labelValue извлекает значение матчера из вывода селектора и снимает кавычки,
+// как это сделает лексер PromQL
+func labelValue(t *testing.T, selector, name, op string) string {
+	t.Helper()
+	re := regexp.MustCompile(regexp.QuoteMeta(name+op) + `("(?:[^"\\]|\\.)*")`)
+	m := re.FindStringSubmatch(selector)
+	require.NotNil(t, m, "matcher %s%s not found in %s", name, op, selector)
+	value, err := strconv.Unquote(m[1])
+	require.NoError(t, err)
+	return value
+}
+
+func TestSelector_EscapesLabelValues(t *testing.T) {
+	for _, v := range hostileValues {
+		sel := monitoring.NewSelector("up").Eq("namespace", v).Neq("pod", v).String()
+		assert.Equal(t, v, labelValue(t, sel, "namespace", "="), sel)
+		assert.Equal(t, v, labelValue(t, sel, "pod", "!="), sel)
+		assert.Regexp(t, `^up\{namespace="(?:[^"\\]|\\.)*", pod!="(?:[^"\\]|\\.)*"\}$`, sel)
+	}
+}
+
+func TestSelector_HasPrefixQuotesRegex(t *testing.T) {
+	for _, v := range hostileValues {
+		sel := monitoring.NewSelector("up").HasPrefix("job", v)
+		require.NoError(t, sel.Validate(), v)
+
+		// регулярное выражение Prometheus якорится с обеих сторон
+		re := regexp.MustCompile("^(?:" + labelValue(t, sel.String(), "job", "=~") + ")$")
+		assert.True(t, re.MatchString(v), "prefix %q must match itself", v)
+		assert.True(t, re.MatchString(v+"-canary"), "prefix %q must match longer values", v)
+		assert.False(t, re.MatchString("other"+v), "prefix %q must not match arbitrary values", v)
+	}
+	assert.False(t, regexp.MustCompile("^(?:"+monitoring.QuoteRegex("api|admin")+".*)$").MatchString("admin"))
+}
+
+func TestSelector_OneOf(t *testing.T) {
+	sel := monitoring.NewSelector("kube_pod_status_ready").OneOf("pod", hostileValues)
+	require.NoError(t, sel.Validate())
+
+	re := regexp.MustCompile("^(?:" + labelValue(t, sel.String(), "pod", "=~") + ")$")
+	for _, v := range hostileValues {
+		assert.True(t, re.MatchString(v), "%q must match", v)
+	}
+	for _, v := range []string{"apix", "admin", "", "a", "xapi", "anything"} {
+		assert.False(t, re.MatchString(v), "%q must not match", v)
+	}
+}
+
+func TestSelector_Validate(t *testing.T) {
+	assert.NoError(t, monitoring.NewSelector("http_requests_total").Validate())
+	assert.NoError(t, monitoring.NewSelector("").Eq("job", "api").Validate())
+	assert.Error(t, monitoring.NewSelector(`up{job="x"} or vector(1)`).Validate())
+	assert.Error(t, monitoring.NewSelector("").Validate())
+	assert.Error(t, monitoring.NewSelector("up").Eq(`job"`, "x").Validate())
+	assert.Error(t, monitoring.NewSelector("up").Re("job", "(unclosed").Validate())
+
+	assert.Equal(t, "up", monitoring.NewSelector("up").String())
+	assert.Equal(t, `{job=~"api.*"}`, monitoring.NewSelector("").HasPrefix("job", "api").String())
+	assert.False(t, monitoring.ValidMetricName("9up"))
+	assert.False(t, monitoring.ValidMetricName("up or vector(1)"))
+	assert.True(t, monitoring.ValidMetricName("node:cpu_usage:rate5m"))
+}
+
+func TestGetStatusDashboard_EscapesJobName(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.Query().Get("query"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	})
+	client, server := setupTestClient(t, handler)
+	defer server.Close()
+
+	job := `api"} or vector(1) or up{job="`
+	_, err := client.GetStatusDashboard(context.Background(), `ns"x`, job, nil)
+	require.NoError(t, err)
+
+	require.Len(t, queries, 1)
+	assert.Equal(t, `up{job=~"api\"\\} or vector\\(1\\) or up\\{job=\".*", namespace="ns\"x"}`, queries[0])
+}
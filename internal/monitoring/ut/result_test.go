@@ -0,0 +1,131 @@
+package monitoring_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"chatops/internal/monitoring"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryResult_Unmarshal(t *testing.T) {
+	ts := time.Unix(1700000000, 0).UTC()
+
+	tests := []struct {
+		name  string
+		body  string
+		check func(t *testing.T, r monitoring.QueryResult)
+	}{
+		{
+			name: "vector",
+			body: `{"resultType":"vector","result":[{"metric":{"pod":"api-1"},"value":[1700000000,"1.5"]},{"metric":{"pod":"api-2"},"value":[1700000000,"NaN"]}]}`,
+			check: func(t *testing.T, r monitoring.QueryResult) {
+				require.Len(t, r.Vector, 2)
+				assert.Equal(t, monitoring.Sample{Metric: map[string]string{"pod": "api-1"}, Time: ts, Value: 1.5}, r.Vector[0])
+				assert.True(t, math.IsNaN(r.Vector[1].Value))
+
+				values, err := r.ValuesByLabel("pod")
+				require.NoError(t, err)
+				assert.Equal(t, map[string]float64{"api-1": 1.5}, values)
+				_, ok := r.Float()
+				assert.False(t, ok)
+			},
+		},
+		{
+			name: "matrix",
+			body: `{"resultType":"matrix","result":[{"metric":{"pod":"api-1"},"values":[[1700000000,"1"],[1700000015,"+Inf"]]}]}`,
+			check: func(t *testing.T, r monitoring.QueryResult) {
+				require.Len(t, r.Matrix, 1)
+				require.Len(t, r.Matrix[0].Points, 2)
+				assert.Equal(t, ts.Add(15*time.Second), r.Matrix[0].Points[1].Time)
+				assert.True(t, math.IsInf(r.Matrix[0].Points[1].Value, 1))
+
+				_, err := r.Samples()
+				assert.Error(t, err)
+				_, err = r.ValuesByLabel("pod")
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "scalar",
+			body: `{"resultType":"scalar","result":[1700000000,"42"]}`,
+			check: func(t *testing.T, r monitoring.QueryResult) {
+				require.NotNil(t, r.Scalar)
+				value, ok := r.Float()
+				assert.True(t, ok)
+				assert.Equal(t, 42.0, value)
+			},
+		},
+		{
+			name: "string",
+			body: `{"resultType":"string","result":[1700000000,"hello"]}`,
+			check: func(t *testing.T, r monitoring.QueryResult) {
+				require.NotNil(t, r.String)
+				assert.Equal(t, monitoring.StringPoint{Time: ts, Value: "hello"}, *r.String)
+				_, ok := r.Float()
+				assert.False(t, ok)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r monitoring.QueryResult
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &r))
+			tt.check(t, r)
+
+			// результат переживает повторную сериализацию
+			encoded, err := json.Marshal(r)
+			require.NoError(t, err)
+			var again monitoring.QueryResult
+			require.NoError(t, json.Unmarshal(encoded, &again))
+			assert.Equal(t, r.ResultType, again.ResultType)
+		})
+	}
+}
+
+func TestQueryResult_UnmarshalInvalid(t *testing.T) {
+	for _, body := range []string{
+		`{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"abc"]}]}`,
+		`{"resultType":"vector","result":[{"metric":{},"value":[1700000000]}]}`,
+		`{"resultType":"scalar","result":["now","1"]}`,
+		`{"resultType":"histogram","result":[]}`,
+	} {
+		var r monitoring.QueryResult
+		assert.Error(t, json.Unmarshal([]byte(body), &r), body)
+	}
+}
+
+func TestClient_Query_ErrorAndWarnings(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("query") == "bad{" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"1:5: parse error: unexpected end of input"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","warnings":["query result may be incomplete"],"data":{"resultType":"scalar","result":[1700000000,"3"]}}`))
+	})
+	client, server := setupTestClient(t, handler)
+	defer server.Close()
+
+	_, err := client.Query(context.Background(), "bad{")
+	var promErr *monitoring.PrometheusError
+	require.True(t, errors.As(err, &promErr), "expected PrometheusError, got %v", err)
+	assert.Equal(t, "bad_data", promErr.Type)
+	assert.Contains(t, promErr.Message, "parse error")
+
+	resp, err := client.Query(context.Background(), "scalar(up)")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"query result may be incomplete"}, resp.Warnings)
+	value, ok := resp.Data.Float()
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, value)
+}
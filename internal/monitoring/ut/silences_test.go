@@ -0,0 +1,148 @@
+package monitoring_test
+
+import (
+	"chatops/internal/monitoring"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseMatchers(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected []monitoring.Matcher
+		wantErr  bool
+	}{
+		{
+			expr: `alertname=PodCrash,namespace!=dev`,
+			expected: []monitoring.Matcher{
+				{Name: "alertname", Value: "PodCrash", IsEqual: true},
+				{Name: "namespace", Value: "dev"},
+			},
+		},
+		{
+			expr: `{pod=~"api-.*",job!~"a,b"}`,
+			expected: []monitoring.Matcher{
+				{Name: "pod", Value: "api-.*", IsRegex: true, IsEqual: true},
+				{Name: "job", Value: "a,b", IsRegex: true},
+			},
+		},
+		{
+			expr:     `summary="say \"hi\""`,
+			expected: []monitoring.Matcher{{Name: "summary", Value: `say "hi"`, IsEqual: true}},
+		},
+		{expr: ``, wantErr: true},
+		{expr: `=value`, wantErr: true},
+		{expr: `alertname`, wantErr: true},
+		{expr: `alertname=`, wantErr: true},
+		{expr: `alertname="unterminated`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		matchers, err := monitoring.ParseMatchers(tt.expr)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseMatchers(%q) expected error, got %+v", tt.expr, matchers)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseMatchers(%q) returned an error: %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(matchers, tt.expected) {
+			t.Errorf("ParseMatchers(%q) got = %+v, want %+v", tt.expr, matchers, tt.expected)
+		}
+	}
+}
+
+func TestCutMatchers(t *testing.T) {
+	tests := []struct {
+		text string
+		expr string
+		rest string
+	}{
+		{text: `alertname=PodCrash 2h плановые работы`, expr: `alertname=PodCrash`, rest: `2h плановые работы`},
+		{text: `alertname="Disk full",ns=prod 2h работы`, expr: `alertname="Disk full",ns=prod`, rest: `2h работы`},
+		{text: `  {a="x y", b=c} 1h комментарий`, expr: `{a="x y", b=c}`, rest: `1h комментарий`},
+		{text: `summary="say \"hi there\"" 30m`, expr: `summary="say \"hi there\""`, rest: `30m`},
+		{text: `alertname=PodCrash`, expr: `alertname=PodCrash`, rest: ``},
+		{text: ``, expr: ``, rest: ``},
+	}
+
+	for _, tt := range tests {
+		expr, rest := monitoring.CutMatchers(tt.text)
+		if expr != tt.expr || rest != tt.rest {
+			t.Errorf("CutMatchers(%q) = %q, %q, want %q, %q", tt.text, expr, rest, tt.expr, tt.rest)
+		}
+	}
+}
+
+func TestClient_Silences(t *testing.T) {
+	var created monitoring.Silence
+	var expired string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/silences", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodPost:
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"silenceID": "abc-123"})
+		case http.MethodGet:
+			w.Write([]byte(`[{"id":"abc-123","status":{"state":"active"},"matchers":[{"name":"alertname","value":"PodCrash","isRegex":false,"isEqual":true}],"createdBy":"alice","comment":"maintenance","startsAt":"2024-01-01T12:00:00Z","endsAt":"2024-01-01T14:00:00Z"}]`))
+		}
+	})
+	mux.HandleFunc("/api/v2/silence/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		expired = r.URL.Path[len("/api/v2/silence/"):]
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := monitoring.NewClient("", server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create test client: %v", err)
+	}
+	ctx := context.Background()
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	id, err := client.CreateSilence(ctx, monitoring.Silence{
+		Matchers:  []monitoring.Matcher{{Name: "alertname", Value: "PodCrash", IsEqual: true}},
+		StartsAt:  start,
+		EndsAt:    start.Add(2 * time.Hour),
+		CreatedBy: "alice",
+		Comment:   "maintenance",
+	})
+	if err != nil {
+		t.Fatalf("CreateSilence() returned an error: %v", err)
+	}
+	if id != "abc-123" || created.CreatedBy != "alice" || len(created.Matchers) != 1 {
+		t.Errorf("CreateSilence() got id %q, request %+v", id, created)
+	}
+
+	silences, err := client.ListSilences(ctx)
+	if err != nil {
+		t.Fatalf("ListSilences() returned an error: %v", err)
+	}
+	if len(silences) != 1 || silences[0].State() != monitoring.SilenceActive || silences[0].Matchers[0].String() != `alertname="PodCrash"` {
+		t.Errorf("ListSilences() got = %+v", silences)
+	}
+
+	if err := client.ExpireSilence(ctx, "abc-123"); err != nil {
+		t.Fatalf("ExpireSilence() returned an error: %v", err)
+	}
+	if expired != "abc-123" {
+		t.Errorf("ExpireSilence() expired %q, want abc-123", expired)
+	}
+}
@@ -0,0 +1,124 @@
+package monitoring_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"chatops/internal/monitoring"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSLO() monitoring.SLO {
+	return monitoring.SLO{
+		Name:      "availability",
+		Service:   "api",
+		Namespace: "prod",
+		Kind:      monitoring.SLOAvailability,
+		Query:     `sum(rate(http_requests_total{job="{{service}}", namespace="{{namespace}}", code!~"5.."}[{{window}}])) / sum(rate(http_requests_total{job="{{service}}", namespace="{{namespace}}"}[{{window}}]))`,
+		Target:    0.999,
+		Window:    30 * 24 * time.Hour,
+	}
+}
+
+func TestClient_EvaluateSLO(t *testing.T) {
+	ratios := map[string]string{
+		"30d": "0.9995",
+		"1h":  "0.98",
+		"5m":  "0.97",
+		"6h":  "0.999",
+		"30m": "0.99",
+		"1d":  "0.9999",
+		"2h":  "0.999",
+	}
+	windowRe := regexp.MustCompile(`\[(\w+)\]`)
+	var mu sync.Mutex
+	var queries []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		mu.Lock()
+		queries = append(queries, query)
+		mu.Unlock()
+		var result string
+		if ratio, ok := ratios[windowRe.FindStringSubmatch(query)[1]]; ok {
+			result = fmt.Sprintf(`{"metric":{},"value":[1700000000,"%s"]}`, ratio)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[%s]}}`, result)
+	})
+	client, server := setupTestClient(t, handler)
+	defer server.Close()
+
+	status, err := client.EvaluateSLO(context.Background(), testSLO(), monitoring.DefaultBurnRateRules)
+	require.NoError(t, err)
+	assert.Len(t, queries, 8)
+	assert.Contains(t, queries, `sum(rate(http_requests_total{job="api", namespace="prod", code!~"5.."}[1h])) / sum(rate(http_requests_total{job="api", namespace="prod"}[1h]))`)
+
+	assert.InDelta(t, 0.9995, status.Attainment, 1e-9)
+	assert.InDelta(t, 0.5, status.BudgetRemaining, 1e-6)
+	assert.InDelta(t, 20, status.BurnRates[time.Hour], 1e-6)
+	assert.InDelta(t, 30, status.BurnRates[5*time.Minute], 1e-6)
+	assert.True(t, math.IsNaN(status.BurnRates[72*time.Hour]), "3d window has no traffic")
+
+	require.Len(t, status.Firing, 1)
+	assert.Equal(t, monitoring.BurnRateCritical, status.Firing[0].Severity)
+	assert.Equal(t, time.Hour, status.Firing[0].Long)
+}
+
+func TestClient_EvaluateSLO_QueryError(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+	})
+	client, server := setupTestClient(t, handler)
+	defer server.Close()
+
+	_, err := client.EvaluateSLO(context.Background(), testSLO(), monitoring.DefaultBurnRateRules)
+	assert.ErrorContains(t, err, "parse error")
+}
+
+func TestSLO_Validate(t *testing.T) {
+	assert.NoError(t, testSLO().Validate())
+
+	invalid := map[string]func(*monitoring.SLO){
+		"kind":        func(s *monitoring.SLO) { s.Kind = "throughput" },
+		"target":      func(s *monitoring.SLO) { s.Target = 99.9 },
+		"window":      func(s *monitoring.SLO) { s.Window = time.Minute },
+		"no window":   func(s *monitoring.SLO) { s.Query = `avg_over_time(up{job="{{service}}"}[1h])` },
+		"placeholder": func(s *monitoring.SLO) { s.Query = `avg_over_time(up[{{window|upper}}])` },
+	}
+	for name, mutate := range invalid {
+		slo := testSLO()
+		mutate(&slo)
+		assert.Error(t, slo.Validate(), name)
+	}
+}
+
+func TestBurnRateRule_Threshold(t *testing.T) {
+	window := 30 * 24 * time.Hour
+	expected := []float64{14.4, 6, 3, 1}
+	for i, rule := range monitoring.DefaultBurnRateRules {
+		assert.InDelta(t, expected[i], rule.Threshold(window), 1e-9)
+	}
+}
+
+func TestPromDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		30 * 24 * time.Hour: "30d",
+		72 * time.Hour:      "3d",
+		6 * time.Hour:       "6h",
+		90 * time.Minute:    "90m",
+		5 * time.Minute:     "5m",
+		30 * time.Second:    "30s",
+	} {
+		assert.Equal(t, want, monitoring.PromDuration(d))
+	}
+}
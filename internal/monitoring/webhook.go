@@ -0,0 +1,47 @@
+package monitoring
+
+import "time"
+
+// WebhookMessage - тело уведомления webhook-ресивера Alertmanager (версия 4)
+type WebhookMessage struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	TruncatedAlerts   int               `json:"truncatedAlerts"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []WebhookAlert    `json:"alerts"`
+}
+
+// WebhookAlert - алерт в составе WebhookMessage
+type WebhookAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// Статусы алертов в webhook-уведомлении
+const (
+	WebhookStatusFiring   = "firing"
+	WebhookStatusResolved = "resolved"
+)
+
+// ToAlert приводит алерт из webhook-уведомления к модели Alert
+func (a WebhookAlert) ToAlert() Alert {
+	return Alert{
+		Fingerprint:  a.Fingerprint,
+		Labels:       a.Labels,
+		Annotations:  a.Annotations,
+		StartsAt:     a.StartsAt,
+		EndsAt:       a.EndsAt,
+		GeneratorURL: a.GeneratorURL,
+		Status:       AlertStatus{State: AlertStateActive},
+	}
+}
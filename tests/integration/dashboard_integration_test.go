@@ -48,7 +48,7 @@ func TestGetStatusDashboard_Integration(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("GetDashboardForKnownService", func(t *testing.T) {
-		dashboard, err := client.GetStatusDashboard(ctx, namespace, jobName)
+		dashboard, err := client.GetStatusDashboard(ctx, namespace, jobName, nil)
 		require.NoError(t, err, "GetStatusDashboard returned an error")
 		require.NotNil(t, dashboard, "Dashboard should not be nil")
 
@@ -78,7 +78,7 @@ func TestGetStatusDashboard_Integration(t *testing.T) {
 
 	t.Run("GetDashboardForNonExistentService", func(t *testing.T) {
 		nonExistentJob := "i-do-not-exist-for-real"
-		dashboard, err := client.GetStatusDashboard(ctx, namespace, nonExistentJob)
+		dashboard, err := client.GetStatusDashboard(ctx, namespace, nonExistentJob, nil)
 		require.NoError(t, err, "GetStatusDashboard should not return an error for a non-existent service")
 		require.NotNil(t, dashboard)
 
@@ -779,12 +779,14 @@ func TestIntegrationListPods(t *testing.T) {
 
 	// Получаем список подов
 	fmt.Println("📋 Получаем список подов...")
-	podList, err := client.ListPods(ctx, "test-integration")
+	podList, err := client.ListPods(ctx, "test-integration", nil)
 	assert.NoError(t, err)
 	assert.Len(t, podList, 2)
 	fmt.Printf("📊 Найдено подов: %d\n", len(podList))
+	var podNames []string
 	for _, pod := range podList {
-		fmt.Printf("  - %s\n", pod)
+		fmt.Printf("  - %s (%s)\n", pod.Name, pod.Status)
+		podNames = append(podNames, pod.Name)
 	}
 
 	// Проверяем, что все поды присутствуют в списке
@@ -792,7 +794,7 @@ func TestIntegrationListPods(t *testing.T) {
 		"test-pod-1",
 		"test-pod-2",
 	}
-	assert.ElementsMatch(t, expectedPods, podList)
+	assert.ElementsMatch(t, expectedPods, podNames)
 
 	fmt.Println("🎉 Интеграционный тест получения списка подов успешно завершен!")
 }
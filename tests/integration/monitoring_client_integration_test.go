@@ -67,15 +67,15 @@ func TestMonitoringClient_Integration(t *testing.T) {
 			t.Fatalf("Failed to query metric: %v", err)
 		}
 
-		if len(queryResp.Data.Result) == 0 {
+		if len(queryResp.Data.Vector) == 0 {
 			t.Errorf("No data found for metric %s", metricToQuery)
 			return
 		}
 
-		fmt.Printf("Successfully queried metric '%s'. Found %d time series.\n", metricToQuery, len(queryResp.Data.Result))
-		for _, res := range queryResp.Data.Result {
+		fmt.Printf("Successfully queried metric '%s'. Found %d time series.\n", metricToQuery, len(queryResp.Data.Vector))
+		for _, res := range queryResp.Data.Vector {
 			podName := res.Metric["pod"]
-			value := res.Value[1]
+			value := res.Value
 			fmt.Printf("  - Pod: %s, Value: %v\n", podName, value)
 		}
 	})